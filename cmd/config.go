@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe/pkg/cmdconfig"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/contexthelpers"
+	"github.com/turbot/steampipe/pkg/error_helpers"
+	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/plugin"
+	"github.com/turbot/steampipe/pkg/statushooks"
+	"github.com/turbot/steampipe/pkg/steampipeconfig"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+// Config management commands
+func configCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "config [command]",
+		Args:  cobra.NoArgs,
+		Short: "Steampipe config management",
+		Long: `Steampipe config management.
+
+Examples:
+
+  # Validate the connection config
+  steampipe config validate`,
+	}
+
+	cmd.AddCommand(configValidateCmd())
+	cmd.Flags().BoolP(constants.ArgHelp, "h", false, "Help for config")
+
+	return cmd
+}
+
+func configValidateCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "validate",
+		Args:  cobra.NoArgs,
+		Run:   runConfigValidateCmd,
+		Short: "Validate the connection config",
+		Long: `Validate the connection config.
+
+Loads all .spc files in the config directory and reports every problem found,
+with its file and line. This checks:
+  - the config files parse and all blocks are valid
+  - connection names are valid
+  - the plugin of each connection resolves to an installed plugin
+  - aggregator child connections are not duplicated and each matches a connection
+  - each plugin accepts the config of its connections
+
+To validate the connection config, each plugin is started (the Steampipe
+service does not need to be running). Use --plugins=false to skip this.
+
+Examples:
+
+  # Validate the connection config
+  steampipe config validate
+
+  # Validate the connection config without starting plugins
+  steampipe config validate --plugins=false
+
+  # Validate the connection config, output in json
+  steampipe config validate --output json`,
+	}
+
+	cmdconfig.
+		OnCmd(cmd).
+		AddBoolFlag(constants.ArgPlugins, true, "Start each plugin to validate its connection config").
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatTable, "Output format: table or json").
+		// config validate loads and reports on the config itself, so must be able to run when the config is invalid
+		AddBoolFlag(constants.ArgIgnoreConfigErrors, true, "Do not fail if the config cannot be loaded", cmdconfig.FlagOptions.Hidden()).
+		AddBoolFlag(constants.ArgHelp, false, "Help for config validate", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func runConfigValidateCmd(cmd *cobra.Command, _ []string) {
+	// setup a cancel context and start cancel handler
+	ctx, cancel := context.WithCancel(cmd.Context())
+	contexthelpers.StartCancelHandler(cancel)
+
+	utils.LogTime("runConfigValidateCmd start")
+	defer func() {
+		utils.LogTime("runConfigValidateCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	if err := cmdconfig.ValidateOutputFormat(constants.OutputFormatTable, constants.OutputFormatJSON); err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return
+	}
+	outputFormat := viper.GetString(constants.ArgOutput)
+
+	config, issues := steampipeconfig.ValidateConfig(filepaths.EnsureConfigDir(), viper.GetString(constants.ArgModLocation))
+	if viper.GetBool(constants.ArgPlugins) {
+		statushooks.Show(ctx)
+		issues = append(issues, validatePluginConnectionConfigs(ctx, config)...)
+		statushooks.Done(ctx)
+	}
+
+	if outputFormat == constants.OutputFormatJSON {
+		if issues == nil {
+			issues = steampipeconfig.ConfigValidationIssues{}
+		}
+		error_helpers.FailOnError(printJSON(issues))
+	} else {
+		showConfigValidationIssues(issues)
+	}
+	if issues.HasErrors() {
+		exitCode = constants.ExitCodeInvalidConfig
+	}
+}
+
+// validatePluginConnectionConfigs starts each plugin which has valid connections, and passes it the connection config
+func validatePluginConnectionConfigs(ctx context.Context, config *steampipeconfig.SteampipeConfig) steampipeconfig.ConfigValidationIssues {
+	var issues steampipeconfig.ConfigValidationIssues
+
+	// build map of plugin instance to connections (connections in error have no plugin instance)
+	pluginConnections := make(map[string][]*modconfig.Connection)
+	for _, name := range utils.SortedMapKeys(config.Connections) {
+		connection := config.Connections[name]
+		if connection.Error != nil || connection.PluginInstance == nil {
+			continue
+		}
+		pluginConnections[*connection.PluginInstance] = append(pluginConnections[*connection.PluginInstance], connection)
+	}
+
+	for _, pluginInstance := range utils.SortedMapKeys(pluginConnections) {
+		connections := pluginConnections[pluginInstance]
+		pluginConfig, ok := config.PluginsInstances[pluginInstance]
+		if !ok {
+			continue
+		}
+		statushooks.SetStatus(ctx, fmt.Sprintf("Validating config for plugin '%s'", pluginInstance))
+		failures, err := plugin.ValidateConnectionConfigs(pluginConfig, connections)
+		if err != nil {
+			issue := &steampipeconfig.ConfigValidationIssue{
+				Severity: steampipeconfig.ConfigIssueSeverityError,
+				Message:  fmt.Sprintf("plugin '%s' failed to validate connection config: %s", pluginInstance, err.Error()),
+				Resource: pluginInstance,
+			}
+			if pluginConfig.FileName != nil && pluginConfig.StartLineNumber != nil {
+				issue.FileName = *pluginConfig.FileName
+				issue.StartLine = *pluginConfig.StartLineNumber
+			}
+			issues = append(issues, issue)
+			continue
+		}
+		for _, connection := range connections {
+			if failure, ok := failures[connection.Name]; ok {
+				issues = append(issues, &steampipeconfig.ConfigValidationIssue{
+					Severity:  steampipeconfig.ConfigIssueSeverityError,
+					Message:   fmt.Sprintf("connection '%s': %s", connection.Name, failure),
+					Resource:  connection.Name,
+					FileName:  connection.DeclRange.Filename,
+					StartLine: connection.DeclRange.Start.Line,
+					EndLine:   connection.DeclRange.End.Line,
+				})
+			}
+		}
+	}
+	return issues
+}
+
+func showConfigValidationIssues(issues steampipeconfig.ConfigValidationIssues) {
+	if len(issues) == 0 {
+		fmt.Println("Connection config is valid")
+		return
+	}
+	var errorCount, warningCount int
+	for _, issue := range issues {
+		if issue.Severity == steampipeconfig.ConfigIssueSeverityError {
+			errorCount++
+			fmt.Printf("%s: %s\n", constants.Red("Error"), issue.String())
+		} else {
+			warningCount++
+			fmt.Printf("%s: %s\n", constants.Yellow("Warning"), issue.String())
+		}
+	}
+	var summary []string
+	if errorCount > 0 {
+		summary = append(summary, fmt.Sprintf("%d %s", errorCount, utils.Pluralize("error", errorCount)))
+	}
+	if warningCount > 0 {
+		summary = append(summary, fmt.Sprintf("%d %s", warningCount, utils.Pluralize("warning", warningCount)))
+	}
+	fmt.Printf("\n%s\n", strings.Join(summary, ", "))
+}
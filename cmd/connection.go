@@ -0,0 +1,642 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
+	sdkproto "github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	sdkplugin "github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe/pkg/cmdconfig"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/contexthelpers"
+	"github.com/turbot/steampipe/pkg/db/db_common"
+	"github.com/turbot/steampipe/pkg/db/db_local"
+	"github.com/turbot/steampipe/pkg/display"
+	"github.com/turbot/steampipe/pkg/error_helpers"
+	"github.com/turbot/steampipe/pkg/pluginmanager"
+	"github.com/turbot/steampipe/pkg/statushooks"
+	"github.com/turbot/steampipe/pkg/steampipeconfig"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/utils"
+	"golang.org/x/exp/maps"
+)
+
+// the maximum number of tables 'connection test' will query before reporting failure
+const connectionTestMaxTables = 3
+
+// connectionTestResult is the result of 'connection test'
+type connectionTestResult struct {
+	Connection string `json:"connection"`
+	Plugin     string `json:"plugin"`
+	Success    bool   `json:"success"`
+	// the table which was queried
+	Table      string `json:"table,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Connection management commands
+func connectionCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "connection [command]",
+		Args:  cobra.NoArgs,
+		Short: "Steampipe connection management",
+		Long: `Steampipe connection management.
+
+Connections are defined in .spc files in the config directory. These commands
+report the state of the connections loaded by the Steampipe service.
+
+Examples:
+
+  # List connections
+  steampipe connection list
+
+  # Show details of a connection
+  steampipe connection show aws_prod
+
+  # Test a connection by running a query against it
+  steampipe connection test aws_prod
+
+  # Show the updates which would be made to sync connections with the config
+  steampipe connection plan`,
+	}
+
+	cmd.AddCommand(connectionListCmd())
+	cmd.AddCommand(connectionShowCmd())
+	cmd.AddCommand(connectionTestCmd())
+	cmd.AddCommand(connectionPlanCmd())
+	cmd.Flags().BoolP(constants.ArgHelp, "h", false, "Help for connection")
+
+	return cmd
+}
+
+func connectionListCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "list",
+		Args:  cobra.NoArgs,
+		Run:   runConnectionListCmd,
+		Short: "List connections",
+		Long: `List connections and their state.
+
+Examples:
+
+  # List connections
+  steampipe connection list
+
+  # List connections output in json
+  steampipe connection list --output json`,
+	}
+
+	cmdconfig.
+		OnCmd(cmd).
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatTable, "Output format: table or json").
+		AddBoolFlag(constants.ArgHelp, false, "Help for connection list", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func connectionShowCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "show <name>",
+		Args:  cobra.ExactArgs(1),
+		Run:   runConnectionShowCmd,
+		Short: "Show details of a connection",
+		Long: `Show details of a connection, including its plugin, state and schema mode.
+
+Examples:
+
+  # Show details of a connection
+  steampipe connection show aws_prod
+
+  # Show details of a connection output in json
+  steampipe connection show aws_prod --output json`,
+	}
+
+	cmdconfig.
+		OnCmd(cmd).
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatTable, "Output format: table or json").
+		AddBoolFlag(constants.ArgHelp, false, "Help for connection show", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func connectionTestCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "test <name>",
+		Args:  cobra.ExactArgs(1),
+		Run:   runConnectionTestCmd,
+		Short: "Test a connection",
+		Long: `Test a connection.
+
+Starts the plugin for the connection (which validates the connection config)
+and runs a lightweight query against one of its tables, to verify the
+connection credentials.
+
+Examples:
+
+  # Test a connection
+  steampipe connection test aws_prod
+
+  # Test a connection output in json
+  steampipe connection test aws_prod --output json`,
+	}
+
+	cmdconfig.
+		OnCmd(cmd).
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatTable, "Output format: table or json").
+		AddBoolFlag(constants.ArgHelp, false, "Help for connection test", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func connectionPlanCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "plan",
+		Args:  cobra.NoArgs,
+		Run:   runConnectionPlanCmd,
+		Short: "Show the connection updates required by the current config",
+		Long: `Show the connection updates required by the current config, without applying them.
+
+Compares the connection config with the connection state saved by the last
+connection refresh, and lists the connections which would be created, updated
+or deleted, and the plugins which would be started or stopped.
+
+The service is not started and no plugins are run, so changes to the schema of
+plugins with dynamic schema are not detected.
+
+Examples:
+
+  # Show the connection updates required by the current config
+  steampipe connection plan
+
+  # Show the connection updates output in json
+  steampipe connection plan --output json`,
+	}
+
+	cmdconfig.
+		OnCmd(cmd).
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatTable, "Output format: table or json").
+		AddBoolFlag(constants.ArgHelp, false, "Help for connection plan", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func runConnectionListCmd(cmd *cobra.Command, _ []string) {
+	// setup a cancel context and start cancel handler
+	ctx, cancel := context.WithCancel(cmd.Context())
+	contexthelpers.StartCancelHandler(cancel)
+
+	utils.LogTime("runConnectionListCmd start")
+	defer func() {
+		utils.LogTime("runConnectionListCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	if err := cmdconfig.ValidateOutputFormat(constants.OutputFormatTable, constants.OutputFormatJSON); err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return
+	}
+
+	statushooks.Show(ctx)
+	connectionStateMap, res := loadConnectionState(ctx)
+	statushooks.Done(ctx)
+	if res.Error != nil {
+		error_helpers.ShowErrorWithMessage(ctx, res.Error, "connection listing failed")
+		exitCode = constants.ExitCodeUnknownErrorPanic
+		return
+	}
+
+	connectionStates := make([]*steampipeconfig.ConnectionState, 0, len(connectionStateMap))
+	for _, name := range utils.SortedMapKeys(connectionStateMap) {
+		connectionStates = append(connectionStates, connectionStateMap[name])
+	}
+
+	var err error
+	if viper.GetString(constants.ArgOutput) == constants.OutputFormatJSON {
+		err = printJSON(connectionStates)
+	} else {
+		showConnectionListAsTable(connectionStates)
+	}
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = constants.ExitCodeUnknownErrorPanic
+	}
+}
+
+func showConnectionListAsTable(connectionStates []*steampipeconfig.ConnectionState) {
+	headers := []string{"Connection", "Plugin", "Type", "State", "Schema Mode", "Comments", "Error"}
+	var rows [][]string
+	for _, state := range connectionStates {
+		rows = append(rows, []string{
+			state.ConnectionName,
+			state.Plugin,
+			state.GetType(),
+			state.State,
+			state.SchemaMode,
+			strconv.FormatBool(state.CommentsSet),
+			state.Error(),
+		})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, make([]string, len(headers)))
+	}
+	display.ShowWrappedTable(headers, rows, &display.ShowWrappedTableOptions{AutoMerge: false})
+	fmt.Println()
+}
+
+func runConnectionShowCmd(cmd *cobra.Command, args []string) {
+	// setup a cancel context and start cancel handler
+	ctx, cancel := context.WithCancel(cmd.Context())
+	contexthelpers.StartCancelHandler(cancel)
+
+	utils.LogTime("runConnectionShowCmd start")
+	defer func() {
+		utils.LogTime("runConnectionShowCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	if err := cmdconfig.ValidateOutputFormat(constants.OutputFormatTable, constants.OutputFormatJSON); err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return
+	}
+
+	connectionName := args[0]
+	statushooks.Show(ctx)
+	connectionStateMap, res := loadConnectionState(ctx)
+	statushooks.Done(ctx)
+	if res.Error != nil {
+		error_helpers.ShowError(ctx, res.Error)
+		exitCode = constants.ExitCodeUnknownErrorPanic
+		return
+	}
+	state, ok := connectionStateMap[connectionName]
+	if !ok {
+		error_helpers.ShowError(ctx, fmt.Errorf("connection '%s' not found", connectionName))
+		exitCode = constants.ExitCodeConnectionNotFound
+		return
+	}
+
+	var err error
+	if viper.GetString(constants.ArgOutput) == constants.OutputFormatJSON {
+		err = printJSON(state)
+	} else {
+		showConnectionAsTable(state)
+	}
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = constants.ExitCodeUnknownErrorPanic
+	}
+}
+
+func runConnectionPlanCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("runConnectionPlanCmd start")
+	defer func() {
+		utils.LogTime("runConnectionPlanCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	if err := cmdconfig.ValidateOutputFormat(constants.OutputFormatTable, constants.OutputFormatJSON); err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return
+	}
+
+	connectionStateMap, err := steampipeconfig.LoadConnectionStateFile()
+	if err != nil {
+		error_helpers.ShowErrorWithMessage(ctx, err, "failed to load connection state")
+		exitCode = constants.ExitCodeUnknownErrorPanic
+		return
+	}
+	updates, err := steampipeconfig.NewConnectionUpdatesFromState(connectionStateMap)
+	if err != nil {
+		error_helpers.ShowErrorWithMessage(ctx, err, "failed to determine connection updates")
+		exitCode = constants.ExitCodeUnknownErrorPanic
+		return
+	}
+	plan := updates.Plan()
+
+	if viper.GetString(constants.ArgOutput) == constants.OutputFormatJSON {
+		err = printJSON(plan)
+	} else {
+		showConnectionPlan(plan)
+	}
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = constants.ExitCodeUnknownErrorPanic
+	}
+}
+
+// showConnectionPlan displays the plan in the style of a terraform plan
+func showConnectionPlan(plan *steampipeconfig.ConnectionPlan) {
+	if !plan.HasChanges() {
+		fmt.Println("No changes. Connections are up to date with the config.")
+		return
+	}
+
+	fmt.Println("Steampipe will make the following connection updates:")
+	fmt.Println()
+	for _, change := range plan.Changes {
+		fmt.Printf("  %s %s (%s)\n", connectionPlanActionSymbol(change.Action), change.Connection, change.Plugin)
+		for _, reason := range change.Reasons {
+			fmt.Printf("      %s\n", reason)
+		}
+	}
+
+	pluginChanges := [][]string{
+		{"Plugins to start", strings.Join(plan.PluginStarts, ", ")},
+		{"Plugins to restart", strings.Join(plan.PluginRestarts, ", ")},
+		{"Plugins no longer used", strings.Join(plan.PluginStops, ", ")},
+	}
+	fmt.Println()
+	for _, c := range pluginChanges {
+		if c[1] != "" {
+			fmt.Printf("%s: %s\n", c[0], c[1])
+		}
+	}
+
+	fmt.Printf("\nPlan: %d to create, %d to update, %d to delete, %d in error, %d comment %s.\n",
+		plan.CountByAction(steampipeconfig.ConnectionPlanActionCreate),
+		plan.CountByAction(steampipeconfig.ConnectionPlanActionUpdate),
+		plan.CountByAction(steampipeconfig.ConnectionPlanActionDelete),
+		plan.CountByAction(steampipeconfig.ConnectionPlanActionError),
+		plan.CountByAction(steampipeconfig.ConnectionPlanActionUpdateComments),
+		utils.Pluralize("update", plan.CountByAction(steampipeconfig.ConnectionPlanActionUpdateComments)))
+}
+
+func connectionPlanActionSymbol(action string) string {
+	switch action {
+	case steampipeconfig.ConnectionPlanActionCreate:
+		return constants.Green("+").String()
+	case steampipeconfig.ConnectionPlanActionDelete:
+		return constants.Red("-").String()
+	case steampipeconfig.ConnectionPlanActionError:
+		return constants.Red("!").String()
+	default:
+		return constants.Yellow("~").String()
+	}
+}
+
+func showConnectionAsTable(state *steampipeconfig.ConnectionState) {
+	rows := [][]string{
+		{"Name", state.ConnectionName},
+		{"Plugin", state.Plugin},
+		{"Plugin Instance", typehelpers.SafeString(state.PluginInstance)},
+		{"Type", state.GetType()},
+		{"State", state.State},
+		{"Error", state.Error()},
+		{"Import Schema", state.ImportSchema},
+		{"Priority", strconv.Itoa(state.Priority)},
+		{"Schema Mode", state.SchemaMode},
+		{"Comments Loaded", strconv.FormatBool(state.CommentsSet)},
+		{"Plugin Modified", state.PluginModTime.Format(time.RFC3339)},
+		{"Connection Modified", state.ConnectionModTime.Format(time.RFC3339)},
+		{"File", fmt.Sprintf("%s:%d", state.FileName, state.StartLineNumber)},
+	}
+	if len(state.Tags) > 0 {
+		rows = append(rows, []string{"Tags", formatStringMap(state.Tags)})
+	}
+	if len(state.Connections) > 0 {
+		rows = append(rows, []string{"Connections", strings.Join(state.Connections, ",")})
+	}
+	if len(state.ConnectionSelector) > 0 {
+		rows = append(rows, []string{"Connection Selector", formatStringMap(state.ConnectionSelector)})
+	}
+	if len(state.TablesInclude) > 0 {
+		rows = append(rows, []string{"Tables Include", strings.Join(state.TablesInclude, ",")})
+	}
+	if len(state.TablesExclude) > 0 {
+		rows = append(rows, []string{"Tables Exclude", strings.Join(state.TablesExclude, ",")})
+	}
+	// show each default qual on its own row, so the restriction applied to each table is clear
+	for _, table := range utils.SortedMapKeys(state.DefaultQuals) {
+		rows = append(rows, []string{"Default Qual", fmt.Sprintf("%s: %s", table, state.DefaultQuals[table])})
+	}
+	display.ShowWrappedTable([]string{"Property", "Value"}, rows, &display.ShowWrappedTableOptions{AutoMerge: false})
+	fmt.Println()
+}
+
+func runConnectionTestCmd(cmd *cobra.Command, args []string) {
+	// setup a cancel context and start cancel handler
+	ctx, cancel := context.WithCancel(cmd.Context())
+	contexthelpers.StartCancelHandler(cancel)
+
+	utils.LogTime("runConnectionTestCmd start")
+	defer func() {
+		utils.LogTime("runConnectionTestCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	if err := cmdconfig.ValidateOutputFormat(constants.OutputFormatTable, constants.OutputFormatJSON); err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return
+	}
+
+	connectionName := args[0]
+	connection, ok := steampipeconfig.GlobalConfig.Connections[connectionName]
+	if !ok {
+		error_helpers.ShowError(ctx, fmt.Errorf("connection '%s' not found", connectionName))
+		exitCode = constants.ExitCodeConnectionNotFound
+		return
+	}
+
+	statushooks.Show(ctx)
+	result := testConnection(ctx, connection)
+	statushooks.Done(ctx)
+
+	var err error
+	if viper.GetString(constants.ArgOutput) == constants.OutputFormatJSON {
+		err = printJSON(result)
+	} else {
+		showConnectionTestResult(result)
+	}
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = constants.ExitCodeConnectionTestFailed
+		return
+	}
+	if !result.Success {
+		exitCode = constants.ExitCodeConnectionTestFailed
+	}
+}
+
+// testConnection starts the plugin for the connection via the plugin manager
+// and queries (up to connectionTestMaxTables) tables of the connection until a query succeeds
+func testConnection(ctx context.Context, connection *modconfig.Connection) *connectionTestResult {
+	start := time.Now()
+	result := &connectionTestResult{
+		Connection: connection.Name,
+		Plugin:     connection.Plugin,
+	}
+	defer func() {
+		result.DurationMs = time.Since(start).Milliseconds()
+	}()
+	setError := func(err error) *connectionTestResult {
+		result.Error = err.Error()
+		return result
+	}
+
+	// start the service - this starts the plugin manager, if needed
+	statushooks.SetStatus(ctx, "Starting service")
+	client, res := db_local.GetLocalClient(ctx, constants.InvokerConnection, nil)
+	if res.Error != nil {
+		return setError(res.Error)
+	}
+	defer client.Close(ctx)
+
+	// wait for the connection to be loaded
+	statushooks.SetStatus(ctx, fmt.Sprintf("Waiting for connection '%s'", connection.Name))
+	conn, err := client.AcquireManagementConnection(ctx)
+	if err != nil {
+		return setError(err)
+	}
+	connectionStateMap, err := steampipeconfig.LoadConnectionState(ctx, conn.Conn(), steampipeconfig.WithWaitUntilReady(connection.Name))
+	conn.Release()
+	if err != nil {
+		return setError(err)
+	}
+	state, ok := connectionStateMap[connection.Name]
+	if !ok {
+		return setError(fmt.Errorf("connection '%s' was not loaded", connection.Name))
+	}
+	if state.State == constants.ConnectionStateError {
+		return setError(errors.New(state.Error()))
+	}
+
+	// start the plugin - this validates the connection config
+	statushooks.SetStatus(ctx, fmt.Sprintf("Starting plugin '%s'", connection.Plugin))
+	pluginManager, err := pluginmanager.GetPluginManager()
+	if err != nil {
+		return setError(err)
+	}
+	connectionPluginMap, refreshResult := steampipeconfig.CreateConnectionPlugins(pluginManager, []string{connection.Name})
+	if refreshResult.Error != nil {
+		return setError(refreshResult.Error)
+	}
+	if failure, ok := refreshResult.FailedConnections[connection.Name]; ok {
+		return setError(errors.New(failure))
+	}
+	connectionPlugin, ok := connectionPluginMap[connection.Name]
+	if !ok {
+		return setError(fmt.Errorf("failed to start plugin '%s'", connection.Plugin))
+	}
+
+	if connection.ImportDisabled() {
+		// there is no schema to query - starting the plugin is the only test we can do
+		result.Success = true
+		return result
+	}
+
+	schema, err := connectionPlugin.GetSchema(connection.Name)
+	if err != nil {
+		return setError(err)
+	}
+	tables := connectionTestTables(schema, state)
+	if len(tables) == 0 {
+		return setError(fmt.Errorf("connection '%s' has no tables which can be queried without qualifiers", connection.Name))
+	}
+	for _, table := range tables {
+		statushooks.SetStatus(ctx, fmt.Sprintf("Querying %s.%s", connection.Name, table))
+		result.Table = table
+		query := fmt.Sprintf("select * from %s.%s limit 1", db_common.PgEscapeName(connection.Name), db_common.PgEscapeName(table))
+		if _, err = client.ExecuteSync(ctx, query); err == nil {
+			result.Success = true
+			return result
+		}
+	}
+	return setError(err)
+}
+
+// connectionTestTables returns the (sorted) names of up to connectionTestMaxTables tables of the schema
+// which are imported for the connection and can be listed without any required qualifiers
+func connectionTestTables(schema *sdkproto.Schema, state *steampipeconfig.ConnectionState) []string {
+	var res []string
+	for _, tableName := range utils.SortedMapKeys(schema.Schema) {
+		if len(res) == connectionTestMaxTables {
+			break
+		}
+		if !state.IncludesTable(tableName) || tableRequiresQuals(schema.Schema[tableName]) {
+			continue
+		}
+		res = append(res, tableName)
+	}
+	return res
+}
+
+func tableRequiresQuals(tableSchema *sdkproto.TableSchema) bool {
+	for _, keyColumn := range tableSchema.ListCallKeyColumnList {
+		if keyColumn.Require == sdkplugin.Required {
+			return true
+		}
+	}
+	return false
+}
+
+func showConnectionTestResult(result *connectionTestResult) {
+	if result.Success {
+		if result.Table == "" {
+			fmt.Printf("Connection '%s' OK - plugin started (%dms)\n", result.Connection, result.DurationMs)
+			return
+		}
+		fmt.Printf("Connection '%s' OK - queried %s (%dms)\n", result.Connection, result.Table, result.DurationMs)
+		return
+	}
+	fmt.Printf("Connection '%s' FAILED (%dms)\n", result.Connection, result.DurationMs)
+	error_helpers.ShowError(context.Background(), errors.New(result.Error))
+}
+
+// loadConnectionState loads the connection state, waiting until no connections are pending
+func loadConnectionState(ctx context.Context) (steampipeconfig.ConnectionStateMap, *error_helpers.ErrorAndWarnings) {
+	// start service
+	statushooks.SetStatus(ctx, "Starting service")
+	client, res := db_local.GetLocalClient(ctx, constants.InvokerConnection, nil)
+	if res.Error != nil {
+		return nil, res
+	}
+	defer client.Close(ctx)
+
+	conn, err := client.AcquireManagementConnection(ctx)
+	if err != nil {
+		res.Error = err
+		return nil, res
+	}
+	defer conn.Release()
+
+	statushooks.SetStatus(ctx, "Loading connection state")
+	connectionStateMap, err := steampipeconfig.LoadConnectionState(ctx, conn.Conn(), steampipeconfig.WithWaitUntilLoading())
+	if err != nil {
+		res.Error = err
+		return nil, res
+	}
+	return connectionStateMap, res
+}
+
+func formatStringMap(m map[string]string) string {
+	keys := maps.Keys(m)
+	sort.Strings(keys)
+	var items []string
+	for _, k := range keys {
+		items = append(items, fmt.Sprintf("%s=%s", k, m[k]))
+	}
+	return strings.Join(items, ", ")
+}
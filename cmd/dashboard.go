@@ -202,10 +202,8 @@ func validateDashboardArgs(ctx context.Context, args []string) (string, error) {
 		}
 	}
 
-	validOutputFormats := []string{constants.OutputFormatSnapshot, constants.OutputFormatSnapshotShort, constants.OutputFormatNone}
-	output := viper.GetString(constants.ArgOutput)
-	if !helpers.StringSliceContains(validOutputFormats, output) {
-		return "", fmt.Errorf("invalid output format: '%s', must be one of [%s]", output, strings.Join(validOutputFormats, ", "))
+	if err := cmdconfig.ValidateOutputFormat(constants.OutputFormatSnapshot, constants.OutputFormatSnapshotShort, constants.OutputFormatNone); err != nil {
+		return "", err
 	}
 
 	return dashboardName, nil
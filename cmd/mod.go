@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -10,14 +11,21 @@ import (
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/steampipe/pkg/cmdconfig"
 	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/display"
 	"github.com/turbot/steampipe/pkg/error_helpers"
 	"github.com/turbot/steampipe/pkg/filepaths"
 	"github.com/turbot/steampipe/pkg/modinstaller"
+	"github.com/turbot/steampipe/pkg/plugin"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/parse"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/versionmap"
 	"github.com/turbot/steampipe/pkg/utils"
+	"github.com/turbot/steampipe/pkg/workspace"
 )
 
+// the default file written by 'steampipe mod vendor', in the mod location
+const defaultModVendorFile = "mods.tar.gz"
+
 // mod management commands
 func modCmd() *cobra.Command {
 	var cmd = &cobra.Command{
@@ -43,9 +51,15 @@ Examples:
     
     # List installed mods
     steampipe mod list
+
+    # List installed mods with newer versions available
+    steampipe mod outdated
     
     # Uninstall a mod
     steampipe mod uninstall github.com/turbot/steampipe-mod-aws-compliance
+
+    # Package installed mods for offline installation
+    steampipe mod vendor mods.tar.gz
 	`,
 	}
 
@@ -53,7 +67,11 @@ Examples:
 	cmd.AddCommand(modUninstallCmd())
 	cmd.AddCommand(modUpdateCmd())
 	cmd.AddCommand(modListCmd())
+	cmd.AddCommand(modOutdatedCmd())
+	cmd.AddCommand(modVerifyCmd())
+	cmd.AddCommand(modReleaseCmd())
 	cmd.AddCommand(modInitCmd())
+	cmd.AddCommand(modVendorCmd())
 	cmd.Flags().BoolP(constants.ArgHelp, "h", false, "Help for mod")
 
 	cmdconfig.OnCmd(cmd).
@@ -88,11 +106,15 @@ Examples:
   steampipe mod install
 
   # Preview what steampipe mod install will do, without actually installing anything
-  steampipe mod install --dry-run`,
+  steampipe mod install --dry-run
+
+  # Install all mods specified in the mod.sp from a mirror created by steampipe mod vendor, without contacting git remotes
+  steampipe mod install --from mods.tar.gz`,
 	}
 
 	cmdconfig.OnCmd(cmd).
 		AddBoolFlag(constants.ArgPrune, true, "Remove unused dependencies after installation is complete").
+		AddStringFlag(constants.ArgFrom, "", "Install mods from a mirror (a directory or tarball created by 'steampipe mod vendor', or a directory of bare git repositories) rather than from git").
 		AddBoolFlag(constants.ArgDryRun, false, "Show which mods would be installed/updated/uninstalled without modifying them").
 		AddBoolFlag(constants.ArgForce, false, "Install mods even if plugin/cli version requirements are not met (cannot be used with --dry-run)").
 		AddBoolFlag(constants.ArgHelp, false, "Help for install", cmdconfig.FlagOptions.WithShortHand("h")).
@@ -255,10 +277,14 @@ func modListCmd() *cobra.Command {
 Example:
 
   # List installed mods
-  steampipe mod list`,
+  steampipe mod list
+
+  # List installed mods, including constraints and resolved versions, as json
+  steampipe mod list --output json`,
 	}
 
 	cmdconfig.OnCmd(cmd).
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatTable, "Output format: table or json").
 		AddBoolFlag(constants.ArgHelp, false, "Help for list", cmdconfig.FlagOptions.WithShortHand("h")).
 		AddModLocationFlag()
 	return cmd
@@ -275,11 +301,21 @@ func runModListCmd(cmd *cobra.Command, _ []string) {
 		}
 	}()
 
+	if err := cmdconfig.ValidateOutputFormat(constants.OutputFormatTable, constants.OutputFormatJSON); err != nil {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		error_helpers.FailOnError(err)
+	}
+	outputFormat := viper.GetString(constants.ArgOutput)
+
 	// try to load the workspace mod definition
 	// - if it does not exist, this will return a nil mod and a nil error
 	workspaceMod, err := parse.LoadModfile(viper.GetString(constants.ArgModLocation))
 	error_helpers.FailOnErrorWithMessage(err, "failed to load mod definition")
 	if workspaceMod == nil {
+		if outputFormat == constants.OutputFormatJSON {
+			error_helpers.FailOnError(showModListAsJSON(nil))
+			return
+		}
 		fmt.Println("No mods installed.")
 		return
 	}
@@ -288,6 +324,11 @@ func runModListCmd(cmd *cobra.Command, _ []string) {
 	installer, err := modinstaller.NewModInstaller(opts)
 	error_helpers.FailOnError(err)
 
+	if outputFormat == constants.OutputFormatJSON {
+		error_helpers.FailOnError(showModListAsJSON(installer.GetModTree()))
+		return
+	}
+
 	treeString := installer.GetModList()
 	if len(strings.Split(treeString, "\n")) > 1 {
 		fmt.Println()
@@ -295,6 +336,353 @@ func runModListCmd(cmd *cobra.Command, _ []string) {
 	fmt.Println(treeString)
 }
 
+func showModListAsJSON(tree *versionmap.DependencyTreeNode) error {
+	// if there is no workspace mod, output an empty tree
+	if tree == nil {
+		tree = &versionmap.DependencyTreeNode{Dependencies: []*versionmap.DependencyTreeNode{}}
+	}
+	return printJSON(tree)
+}
+
+// vendor
+func modVendorCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "vendor [path]",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runModVendorCmd,
+		Short: "Package installed mod dependencies for offline installation",
+		Long: `Package installed mod dependencies for offline installation.
+
+Copies all resolved dependencies and the workspace lock into a directory, or a
+tarball if the path ends in .tar.gz or .tgz. The result may be installed without
+access to git remotes using 'steampipe mod install --from <path>'.
+
+Example:
+
+  # Package installed mods into mods.tar.gz in the mod location
+  steampipe mod vendor
+
+  # Package installed mods into a directory
+  steampipe mod vendor ./mod-mirror`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for vendor", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddModLocationFlag()
+	return cmd
+}
+
+func runModVendorCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runModVendorCmd")
+	defer func() {
+		utils.LogTime("cmd.runModVendorCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	workspacePath := viper.GetString(constants.ArgModLocation)
+	dest := filepath.Join(workspacePath, defaultModVendorFile)
+	if len(args) == 1 {
+		dest = args[0]
+	}
+
+	count, err := modinstaller.VendorWorkspaceDependencies(workspacePath, dest)
+	error_helpers.FailOnError(err)
+
+	fmt.Printf("Vendored %d %s to %s\n", count, utils.Pluralize("mod", count), dest)
+}
+
+// outdated
+func modOutdatedCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "outdated",
+		Run:   runModOutdatedCmd,
+		Short: "List installed mods with newer versions available",
+		Long: `List installed mods with newer versions available.
+
+For each dependency, shows the installed version, the most recent version which
+satisfies the version constraint (wanted), and the most recent version available
+(latest). For dependencies which track a git branch, the installed commit and the
+head of the branch are shown.
+
+Example:
+
+  # List outdated mods
+  steampipe mod outdated
+
+  # List all installed mods, including those which are up to date, as json
+  steampipe mod outdated --all --output json`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgAll, false, "Include dependencies which are up to date").
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatTable, "Output format: table or json").
+		AddBoolFlag(constants.ArgHelp, false, "Help for outdated", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddModLocationFlag()
+	return cmd
+}
+
+func runModOutdatedCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runModOutdatedCmd")
+	defer func() {
+		utils.LogTime("cmd.runModOutdatedCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	if err := cmdconfig.ValidateOutputFormat(constants.OutputFormatTable, constants.OutputFormatJSON); err != nil {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		error_helpers.FailOnError(err)
+	}
+	outputFormat := viper.GetString(constants.ArgOutput)
+
+	var dependencies []*modinstaller.OutdatedDependency
+	workspaceMod, err := parse.LoadModfile(viper.GetString(constants.ArgModLocation))
+	error_helpers.FailOnErrorWithMessage(err, "failed to load mod definition")
+	if workspaceMod != nil {
+		installer, err := modinstaller.NewModInstaller(modinstaller.NewInstallOpts(workspaceMod))
+		error_helpers.FailOnError(err)
+		dependencies, err = installer.GetOutdatedDependencies()
+		error_helpers.FailOnError(err)
+	}
+
+	// unless --all is set, only show dependencies with newer versions available
+	if !viper.GetBool(constants.ArgAll) {
+		var outdated []*modinstaller.OutdatedDependency
+		for _, d := range dependencies {
+			if d.IsOutdated() {
+				outdated = append(outdated, d)
+			}
+		}
+		dependencies = outdated
+	}
+
+	if outputFormat == constants.OutputFormatJSON {
+		if dependencies == nil {
+			dependencies = []*modinstaller.OutdatedDependency{}
+		}
+		error_helpers.FailOnError(printJSON(dependencies))
+		return
+	}
+
+	if len(dependencies) == 0 {
+		fmt.Println("All mods are up to date.")
+		return
+	}
+	headers := []string{"Mod", "Required By", "Constraint", "Current", "Wanted", "Latest"}
+	var rows [][]string
+	for _, d := range dependencies {
+		constraint := d.Constraint
+		if d.Branch != "" {
+			constraint = fmt.Sprintf("branch %s", d.Branch)
+		} else if d.Commit != "" {
+			constraint = "commit"
+		}
+		rows = append(rows, []string{d.Name, d.Parent, constraint, d.Current, d.Wanted, d.Latest})
+	}
+	display.ShowWrappedTable(headers, rows, &display.ShowWrappedTableOptions{AutoMerge: false})
+}
+
+// verify
+func modVerifyCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "verify",
+		Run:   runModVerifyCmd,
+		Short: "Verify installed mods have not been modified",
+		Long: `Verify installed mods have not been modified.
+
+Compares the contents of each installed dependency with the integrity hash
+recorded in the workspace lock when it was installed, and reports any which
+have been modified or are missing.
+
+Example:
+
+  # Verify installed mods
+  steampipe mod verify`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatTable, "Output format: table or json").
+		AddBoolFlag(constants.ArgHelp, false, "Help for verify", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddModLocationFlag()
+	return cmd
+}
+
+func runModVerifyCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runModVerifyCmd")
+	defer func() {
+		utils.LogTime("cmd.runModVerifyCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	if err := cmdconfig.ValidateOutputFormat(constants.OutputFormatTable, constants.OutputFormatJSON); err != nil {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		error_helpers.FailOnError(err)
+	}
+	outputFormat := viper.GetString(constants.ArgOutput)
+
+	lock, err := versionmap.LoadWorkspaceLock(viper.GetString(constants.ArgModLocation))
+	error_helpers.FailOnError(err)
+	results, err := lock.Verify()
+	error_helpers.FailOnError(err)
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == versionmap.IntegrityModified || r.Status == versionmap.IntegrityMissing {
+			failed++
+		}
+	}
+	if failed > 0 {
+		exitCode = constants.ExitCodeModVerifyFailed
+	}
+
+	if outputFormat == constants.OutputFormatJSON {
+		if results == nil {
+			results = []*versionmap.IntegrityResult{}
+		}
+		error_helpers.FailOnError(printJSON(results))
+		return
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No mods installed.")
+		return
+	}
+	for _, r := range results {
+		switch r.Status {
+		case versionmap.IntegrityOk:
+			fmt.Printf("%s: ok\n", r.DependencyPath)
+		case versionmap.IntegrityModified:
+			fmt.Printf("%s: modified (expected %s, got %s)\n", r.DependencyPath, r.Expected, r.Actual)
+		case versionmap.IntegrityMissing:
+			fmt.Printf("%s: missing - run 'steampipe mod install'\n", r.DependencyPath)
+		case versionmap.IntegrityUnknown:
+			fmt.Printf("%s: no integrity hash recorded - run 'steampipe mod install' to record one\n", r.DependencyPath)
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\n%d %s failed verification\n", failed, utils.Pluralize("mod", failed))
+	}
+}
+
+// release
+func modReleaseCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "release",
+		Run:   runModReleaseCmd,
+		Short: "Validate the mod and create a release tag",
+		Long: `Validate the mod and create a release tag.
+
+Loads and validates the workspace mod, checks its 'require' block against the
+installed plugins and steampipe version, then creates an annotated git tag for
+the next version on the HEAD commit. The next version is computed from the most
+recent version tag.
+
+The tag is not pushed - run 'git push origin <tag>' to publish the release.
+
+Example:
+
+  # Release the next patch version
+  steampipe mod release
+
+  # Release the next minor version
+  steampipe mod release --bump minor
+
+  # Release a prerelease of the next major version (e.g. v2.0.0-rc.1), then the next prerelease (v2.0.0-rc.2)
+  steampipe mod release --bump major --prerelease rc
+  steampipe mod release --prerelease rc
+
+  # Show the release which would be created, without creating it
+  steampipe mod release --dry-run`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddStringFlag(constants.ArgBump, "", "The version to increment: major, minor or patch (defaults to patch, or the next prerelease if continuing a prerelease)").
+		AddStringFlag(constants.ArgPrerelease, "", "Create a prerelease with the given identifier, e.g. rc").
+		AddStringFlag(constants.ArgMessage, "", "The tag message (defaults to 'Release <tag>')").
+		AddBoolFlag(constants.ArgDryRun, false, "Validate the mod and show the release which would be created, without creating it").
+		AddBoolFlag(constants.ArgForce, false, "Release even if the git worktree has uncommitted changes").
+		AddStringSliceFlag(constants.ArgVarFile, nil, "Specify an .spvar file containing variable values").
+		// NOTE: use StringArrayFlag for ArgVariable, not StringSliceFlag
+		// Cobra will interpret values passed to a StringSliceFlag as CSV,
+		// where args passed to StringArrayFlag are not parsed and used raw
+		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable").
+		AddBoolFlag(constants.ArgInput, true, "Enable interactive prompts").
+		AddBoolFlag(constants.ArgHelp, false, "Help for release", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddModLocationFlag()
+	return cmd
+}
+
+func runModReleaseCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runModReleaseCmd")
+	defer func() {
+		utils.LogTime("cmd.runModReleaseCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	workspacePath := viper.GetString(constants.ArgModLocation)
+	if !parse.ModfileExists(workspacePath) {
+		exitCode = constants.ExitCodeNoModFile
+		error_helpers.FailOnError(fmt.Errorf("no mod definition file found in '%s'", workspacePath))
+	}
+
+	// determine the release first, so we fail fast if the repo is not in a releasable state
+	release, err := modinstaller.NewModRelease(workspacePath, viper.GetString(constants.ArgBump), viper.GetString(constants.ArgPrerelease), viper.GetBool(constants.ArgForce))
+	if err != nil {
+		exitCode = constants.ExitCodeModReleaseFailed
+		error_helpers.FailOnError(err)
+	}
+
+	// validate the workspace - this parses all resources and verifies runtime dependencies
+	w, errAndWarnings := workspace.LoadWorkspacePromptingForVariables(ctx)
+	if errAndWarnings.GetError() != nil {
+		exitCode = constants.ExitCodeModReleaseFailed
+		error_helpers.FailOnErrorWithMessage(errAndWarnings.GetError(), "mod validation failed")
+	}
+	defer w.Close()
+	for _, warning := range errAndWarnings.Warnings {
+		error_helpers.ShowWarning(warning)
+	}
+
+	// check the requirements against the installed plugins and steampipe version
+	installedPlugins, err := plugin.GetInstalledPlugins()
+	error_helpers.FailOnError(err)
+	if validationErrors := w.Mod.ValidateRequirements(installedPlugins); len(validationErrors) > 0 {
+		exitCode = constants.ExitCodeModReleaseFailed
+		error_helpers.FailOnErrorWithMessage(error_helpers.CombineErrors(validationErrors...), "mod requirements are not met")
+	}
+
+	previous := "none"
+	if release.PreviousVersion != nil {
+		previous = "v" + release.PreviousVersion.String()
+	}
+	if viper.GetBool(constants.ArgDryRun) {
+		fmt.Printf("Would create tag %s on commit %s (previous release: %s)\n", release.Tag, release.Commit.String()[:7], previous)
+		return
+	}
+
+	if err := release.CreateTag(viper.GetString(constants.ArgMessage)); err != nil {
+		exitCode = constants.ExitCodeModReleaseFailed
+		error_helpers.FailOnError(err)
+	}
+	fmt.Printf("Created tag %s on commit %s (previous release: %s)\n", release.Tag, release.Commit.String()[:7], previous)
+	fmt.Printf("To publish the release, run: git push origin %s\n", release.Tag)
+}
+
 // init
 func modInitCmd() *cobra.Command {
 	var cmd = &cobra.Command{
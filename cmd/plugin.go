@@ -2,13 +2,13 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/gosuri/uiprogress"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -20,20 +20,24 @@ import (
 	"github.com/turbot/steampipe/pkg/db/db_local"
 	"github.com/turbot/steampipe/pkg/display"
 	"github.com/turbot/steampipe/pkg/error_helpers"
+	"github.com/turbot/steampipe/pkg/filepaths"
 	"github.com/turbot/steampipe/pkg/installationstate"
 	"github.com/turbot/steampipe/pkg/ociinstaller"
 	"github.com/turbot/steampipe/pkg/ociinstaller/versionfile"
 	"github.com/turbot/steampipe/pkg/plugin"
+	"github.com/turbot/steampipe/pkg/pluginmanager"
 	"github.com/turbot/steampipe/pkg/statushooks"
 	"github.com/turbot/steampipe/pkg/steampipeconfig"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/options"
 	"github.com/turbot/steampipe/pkg/utils"
 )
 
 type installedPlugin struct {
-	Name        string   `json:"name"`
-	Version     string   `json:"version"`
-	Connections []string `json:"connections"`
+	Name            string   `json:"name"`
+	Version         string   `json:"version"`
+	Connections     []string `json:"connections"`
+	SignatureStatus string   `json:"signature_status,omitempty"`
 }
 
 type failedPlugin struct {
@@ -67,6 +71,9 @@ Examples:
   # Update a plugin
   steampipe plugin update aws
 
+  # Roll back a plugin to the previously installed version
+  steampipe plugin rollback aws
+
   # List installed plugins
   steampipe plugin list
 
@@ -82,6 +89,7 @@ Examples:
 	cmd.AddCommand(pluginListCmd())
 	cmd.AddCommand(pluginUninstallCmd())
 	cmd.AddCommand(pluginUpdateCmd())
+	cmd.AddCommand(pluginRollbackCmd())
 	cmd.Flags().BoolP(constants.ArgHelp, "h", false, "Help for plugin")
 
 	return cmd
@@ -101,6 +109,30 @@ The plugin name format is [registry/org/]name[@version]. The default
 registry is hub.steampipe.io, default org is turbot and default version
 is latest. The name is a required argument.
 
+To install on a host without registry access, pass the path of an OCI image
+layout directory, or a .tar, .tar.gz or .tgz archive of one, instead of a
+plugin name. The plugin is installed as org/name@latest, using the plugin
+name and organization from the image config.
+
+Credentials for private registries are read from the docker config and
+credential helpers. They may be configured per registry in the plugin options:
+
+  options "plugin" {
+    registry "registry.example.com" {
+      credential_helper = "ecr-login"
+    }
+  }
+
+Plugin image signatures (cosign format) are verified before anything is
+installed when the signature_policy plugin option is 'warn' or 'enforce'.
+Signatures are verified using the PEM public key at signature_public_key.
+
+The image ref and digest of every installed plugin are recorded in the plugin
+lock file (plugins/plugins.lock.json in the install dir). Use --locked to
+install exactly those images, e.g. on another host. Use --constraint to
+record a semver constraint for the plugin, which restricts the versions
+'plugin update' may update it to.
+
 Examples:
 
   # Install all missing plugins that are specified in configuration files
@@ -116,13 +148,24 @@ Examples:
   steampipe plugin install --progress=false aws
 
   # Skip creation of default plugin config file
-  steampipe plugin install --skip-config aws`,
+  steampipe plugin install --skip-config aws
+
+  # Install a plugin from a local OCI image layout archive
+  steampipe plugin install ./steampipe-plugin-foo.tar.gz
+
+  # Install the exact plugin images recorded in the plugin lock file
+  steampipe plugin install --locked
+
+  # Install a plugin and only allow updates within its current minor version
+  steampipe plugin install aws --constraint "~0.2"`,
 	}
 
 	cmdconfig.
 		OnCmd(cmd).
 		AddBoolFlag(constants.ArgProgress, true, "Display installation progress").
 		AddBoolFlag(constants.ArgSkipConfig, false, "Skip creating the default config file for plugin").
+		AddBoolFlag(constants.ArgLocked, false, "Install the plugin images recorded in the plugin lock file").
+		AddStringFlag(constants.ArgConstraint, "", "Semver constraint the installed version must satisfy, recorded in the plugin lock file for 'plugin update'").
 		AddBoolFlag(constants.ArgHelp, false, "Help for plugin install", cmdconfig.FlagOptions.WithShortHand("h"))
 	return cmd
 }
@@ -141,6 +184,19 @@ The plugin name format is [registry/org/]name[@version]. The default
 registry is hub.steampipe.io, default org is turbot and default version
 is latest. The name is a required argument.
 
+Credentials for private registries are read from the docker config and
+credential helpers. They may be configured per registry in the plugin options:
+
+  options "plugin" {
+    registry "registry.example.com" {
+      credential_helper = "ecr-login"
+    }
+  }
+
+A plugin is only updated if the latest version in its stream satisfies the
+constraint recorded for it in the plugin lock file (if any), e.g.
+"constraint": "~0.2". Constraints are recorded by 'plugin install --constraint'.
+
 Examples:
 
   # Update all plugins to their latest available version
@@ -220,6 +276,34 @@ Example:
 	return cmd
 }
 
+// Roll back a plugin
+func pluginRollbackCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "rollback [flags] [registry/org/]name",
+		Args:  cobra.ExactArgs(1),
+		Run:   runPluginRollbackCmd,
+		Short: "Roll back a plugin to the previously installed version",
+		Long: `Roll back a plugin to the previously installed version.
+
+When a plugin is updated, the previously installed version is kept. Rollback
+swaps it back into place, restarts any running instances of the plugin and
+records the rollback in the plugin versions file. The version that was rolled
+back is kept in turn, so running rollback again restores it.
+
+Example:
+
+  # Roll back the common plugin (turbot/aws)
+  steampipe plugin rollback aws
+
+`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for plugin rollback", cmdconfig.FlagOptions.WithShortHand("h"))
+
+	return cmd
+}
+
 var pluginInstallSteps = []string{
 	"Downloading",
 	"Installing Plugin",
@@ -247,6 +331,33 @@ func runPluginInstallCmd(cmd *cobra.Command, args []string) {
 	showProgress := viper.GetBool(constants.ArgProgress)
 	installReports := make(display.PluginInstallReports, 0, len(plugins))
 
+	// validate the constraint before installing anything
+	if constraint := viper.GetString(constants.ArgConstraint); constraint != "" {
+		var err error
+		if viper.GetBool(constants.ArgLocked) {
+			err = sperr.New("--%s cannot be used with --%s - locked plugins keep the constraint in the plugin lock file", constants.ArgConstraint, constants.ArgLocked)
+		} else if _, err = semver.NewConstraint(constraint); err != nil {
+			err = sperr.WrapWithMessage(err, "invalid constraint '%s'", constraint)
+		}
+		if err != nil {
+			error_helpers.ShowError(ctx, err)
+			exitCode = constants.ExitCodeInsufficientOrWrongInputs
+			return
+		}
+	}
+
+	// with --locked, install the exact images recorded in the plugin lock file
+	var lockedPlugins map[string]*versionfile.LockedPlugin
+	if viper.GetBool(constants.ArgLocked) {
+		var err error
+		plugins, lockedPlugins, err = resolveLockedPlugins(args)
+		if err != nil {
+			error_helpers.ShowError(ctx, err)
+			exitCode = constants.ExitCodeInsufficientOrWrongInputs
+			return
+		}
+	}
+
 	if len(plugins) == 0 {
 		if len(steampipeconfig.GlobalConfig.Plugins) == 0 {
 			error_helpers.ShowError(ctx, sperr.New("No connections or plugins configured"))
@@ -273,7 +384,7 @@ func runPluginInstallCmd(cmd *cobra.Command, args []string) {
 	for _, pluginName := range plugins {
 		installWaitGroup.Add(1)
 		bar := createProgressBar(pluginName, progressBars)
-		go doPluginInstall(ctx, bar, pluginName, installWaitGroup, reportChannel)
+		go doPluginInstall(ctx, bar, pluginName, lockedPlugins[pluginName], installWaitGroup, reportChannel)
 	}
 	go func() {
 		installWaitGroup.Wait()
@@ -312,11 +423,88 @@ func runPluginInstallCmd(cmd *cobra.Command, args []string) {
 	fmt.Println()
 }
 
-func doPluginInstall(ctx context.Context, bar *uiprogress.Bar, pluginName string, wg *sync.WaitGroup, returnChannel chan *display.PluginInstallReport) {
+// resolveLockedPlugins returns the names of the plugins to install from the plugin lock file,
+// and a map of the lock file entries keyed by these names
+// if no plugins are specified, all plugins in the lock file are installed
+// specified plugins which are not in the lock file are returned without a lock file entry, and are not installed
+func resolveLockedPlugins(args []string) ([]string, map[string]*versionfile.LockedPlugin, error) {
+	lockFile, err := versionfile.LoadPluginLockFile()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(lockFile.Plugins) == 0 {
+		return nil, nil, fmt.Errorf("there are no plugins in the plugin lock file %s", filepaths.PluginLockFilePath())
+	}
+
+	var plugins []string
+	lockedPlugins := make(map[string]*versionfile.LockedPlugin)
+	addLockedPlugin := func(locked *versionfile.LockedPlugin) {
+		pluginName := ociinstaller.NewSteampipeImageRef(locked.Name).GetFriendlyName()
+		// a plugin installed from a local image layout is reinstalled from the same layout
+		if ociinstaller.IsLocalImageLayout(locked.ImageRef) {
+			pluginName = locked.ImageRef
+		}
+		plugins = append(plugins, pluginName)
+		lockedPlugins[pluginName] = locked
+	}
+
+	if len(args) == 0 {
+		for _, name := range utils.SortedMapKeys(lockFile.Plugins) {
+			addLockedPlugin(lockFile.Plugins[name])
+		}
+		return plugins, lockedPlugins, nil
+	}
+
+	for _, arg := range args {
+		locked, ok := lockFile.Plugins[ociinstaller.NewSteampipeImageRef(arg).DisplayImageRef()]
+		if !ok {
+			plugins = append(plugins, arg)
+			continue
+		}
+		addLockedPlugin(locked)
+	}
+	return plugins, lockedPlugins, nil
+}
+
+// isLockedImageInstalled returns whether the installed image of the plugin is the locked image
+func isLockedImageInstalled(locked *versionfile.LockedPlugin) bool {
+	versionData, err := versionfile.LoadPluginVersionFile()
+	if err != nil {
+		return false
+	}
+	installed, ok := versionData.Plugins[locked.Name]
+	return ok && installed.ImageDigest == locked.ImageDigest
+}
+
+func doPluginInstall(ctx context.Context, bar *uiprogress.Bar, pluginName string, locked *versionfile.LockedPlugin, wg *sync.WaitGroup, returnChannel chan *display.PluginInstallReport) {
 	var report *display.PluginInstallReport
 
 	pluginAlreadyInstalled, _ := plugin.Exists(pluginName)
-	if pluginAlreadyInstalled {
+	var opts []ociinstaller.PluginInstallOption
+	if constraint := viper.GetString(constants.ArgConstraint); constraint != "" {
+		opts = append(opts, ociinstaller.WithConstraint(constraint))
+	}
+	if locked != nil {
+		// install the locked image, unless it is already installed
+		pluginAlreadyInstalled = isLockedImageInstalled(locked)
+		if !ociinstaller.IsLocalImageLayout(pluginName) {
+			opts = append(opts, ociinstaller.WithImageDigest(locked.ImageDigest))
+		}
+	}
+	if viper.GetBool(constants.ArgLocked) && locked == nil {
+		// with --locked, only plugins in the plugin lock file are installed
+		//nolint:golint,errcheck // the error happens if we set this over the max value
+		bar.Set(len(pluginInstallSteps))
+		bar.AppendFunc(func(b *uiprogress.Bar) string {
+			return helpers.Resize(constants.InstallMessagePluginNotLocked, 20)
+		})
+		report = &display.PluginInstallReport{
+			Plugin:         pluginName,
+			Skipped:        true,
+			SkipReason:     constants.InstallMessagePluginNotLocked,
+			IsUpdateReport: false,
+		}
+	} else if pluginAlreadyInstalled {
 		// set the bar to MAX
 		//nolint:golint,errcheck // the error happens if we set this over the max value
 		bar.Set(len(pluginInstallSteps))
@@ -343,7 +531,7 @@ func doPluginInstall(ctx context.Context, bar *uiprogress.Bar, pluginName string
 				return helpers.Resize(pluginInstallSteps[b.Current()-1], 20)
 			}
 		})
-		report = installPlugin(ctx, pluginName, false, bar)
+		report = installPlugin(ctx, pluginName, false, bar, opts...)
 	}
 	returnChannel <- report
 	wg.Done()
@@ -400,6 +588,14 @@ func runPluginUpdateCmd(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	// the constraints in the plugin lock file may prevent updates
+	lockFile, err := versionfile.LoadPluginLockFile()
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = constants.ExitCodePluginLoadingError
+		return
+	}
+
 	var runUpdatesFor []*versionfile.InstalledVersion
 	updateResults := make(display.PluginInstallReports, 0, len(plugins))
 
@@ -475,7 +671,7 @@ func runPluginUpdateCmd(cmd *cobra.Command, args []string) {
 		report := reports[key]
 		updateWaitGroup.Add(1)
 		bar := createProgressBar(report.ShortNameWithStream(), progressBars)
-		go doPluginUpdate(ctx, bar, report, updateWaitGroup, reportChannel)
+		go doPluginUpdate(ctx, bar, report, lockFile, updateWaitGroup, reportChannel)
 	}
 	go func() {
 		updateWaitGroup.Wait()
@@ -499,10 +695,18 @@ func runPluginUpdateCmd(cmd *cobra.Command, args []string) {
 	fmt.Println()
 }
 
-func doPluginUpdate(ctx context.Context, bar *uiprogress.Bar, pvr plugin.VersionCheckReport, wg *sync.WaitGroup, returnChannel chan *display.PluginInstallReport) {
+func doPluginUpdate(ctx context.Context, bar *uiprogress.Bar, pvr plugin.VersionCheckReport, lockFile *versionfile.PluginLockFile, wg *sync.WaitGroup, returnChannel chan *display.PluginInstallReport) {
 	var report *display.PluginInstallReport
 
-	if skip, skipReason := plugin.SkipUpdate(pvr); skip {
+	skip, skipReason := plugin.SkipUpdate(pvr)
+	if !skip {
+		var err error
+		skip, skipReason, err = plugin.SkipUpdateForConstraint(pvr, lockFile)
+		if err != nil {
+			skip, skipReason = true, err.Error()
+		}
+	}
+	if skip {
 		bar.AppendFunc(func(b *uiprogress.Bar) string {
 			// set the progress bar to append itself with "Already Installed"
 			return helpers.Resize(skipReason, 30)
@@ -538,7 +742,7 @@ func createProgressBar(plugin string, parentProgressBars *uiprogress.Progress) *
 	return bar
 }
 
-func installPlugin(ctx context.Context, pluginName string, isUpdate bool, bar *uiprogress.Bar) *display.PluginInstallReport {
+func installPlugin(ctx context.Context, pluginName string, isUpdate bool, bar *uiprogress.Bar, opts ...ociinstaller.PluginInstallOption) *display.PluginInstallReport {
 	// start a channel for progress publications from plugin.Install
 	progress := make(chan struct{}, 5)
 	defer func() {
@@ -554,10 +758,18 @@ func installPlugin(ctx context.Context, pluginName string, isUpdate bool, bar *u
 		}
 	}()
 
-	image, err := plugin.Install(ctx, pluginName, progress, ociinstaller.WithSkipConfig(viper.GetBool(constants.ArgSkipConfig)))
+	opts = append(opts,
+		ociinstaller.WithSkipConfig(viper.GetBool(constants.ArgSkipConfig)),
+		ociinstaller.WithRegistries(pluginRegistries()),
+		ociinstaller.WithSignatureVerification(viper.GetString(constants.ArgPluginSignaturePolicy), viper.GetString(constants.ArgPluginSignatureKey)))
+	image, err := plugin.Install(ctx, pluginName, progress, opts...)
 	if err != nil {
 		msg := ""
-		_, name, stream := ociinstaller.NewSteampipeImageRef(pluginName).GetOrgNameAndStream()
+		reportName := pluginName
+		if !ociinstaller.IsLocalImageLayout(pluginName) {
+			_, name, stream := ociinstaller.NewSteampipeImageRef(pluginName).GetOrgNameAndStream()
+			reportName = fmt.Sprintf("%s@%s", name, stream)
+		}
 		if isPluginNotFoundErr(err) {
 			exitCode = constants.ExitCodePluginNotFound
 			msg = constants.InstallMessagePluginNotFound
@@ -565,7 +777,7 @@ func installPlugin(ctx context.Context, pluginName string, isUpdate bool, bar *u
 			msg = err.Error()
 		}
 		return &display.PluginInstallReport{
-			Plugin:         fmt.Sprintf("%s@%s", name, stream),
+			Plugin:         reportName,
 			Skipped:        true,
 			SkipReason:     msg,
 			IsUpdateReport: isUpdate,
@@ -579,12 +791,21 @@ func installPlugin(ctx context.Context, pluginName string, isUpdate bool, bar *u
 	}
 	docURL := fmt.Sprintf("https://hub.steampipe.io/plugins/%s/%s", org, name)
 	return &display.PluginInstallReport{
-		Plugin:         fmt.Sprintf("%s@%s", name, stream),
-		Skipped:        false,
-		Version:        versionString,
-		DocURL:         docURL,
-		IsUpdateReport: isUpdate,
+		Plugin:          fmt.Sprintf("%s@%s", name, stream),
+		Skipped:         false,
+		Version:         versionString,
+		DocURL:          docURL,
+		SignatureStatus: string(image.SignatureStatus),
+		IsUpdateReport:  isUpdate,
+	}
+}
+
+// pluginRegistries returns the private registry credential config from the plugin options
+func pluginRegistries() []*options.PluginRegistry {
+	if steampipeconfig.GlobalConfig == nil || steampipeconfig.GlobalConfig.PluginOptions == nil {
+		return nil
 	}
+	return steampipeconfig.GlobalConfig.PluginOptions.Registries
 }
 
 func isPluginNotFoundErr(err error) bool {
@@ -649,11 +870,26 @@ func showPluginListOutput(pluginList []plugin.PluginListItem, failedPluginMap, m
 
 func showPluginListAsTable(pluginList []plugin.PluginListItem, failedPluginMap, missingPluginMap map[string][]*modconfig.Connection, res *error_helpers.ErrorAndWarnings) error {
 	headers := []string{"Installed", "Version", "Connections"}
+	// only show the signature column if the signature of any plugin was verified when it was installed
+	showSignature := false
+	for _, item := range pluginList {
+		if item.SignatureStatus != "" {
+			showSignature = true
+			break
+		}
+	}
+	if showSignature {
+		headers = append(headers, "Signature")
+	}
 	var rows [][]string
 	// List installed plugins in a table
 	if len(pluginList) != 0 {
 		for _, item := range pluginList {
-			rows = append(rows, []string{item.Name, item.Version.String(), strings.Join(item.Connections, ",")})
+			row := []string{item.Name, item.Version.String(), strings.Join(item.Connections, ",")}
+			if showSignature {
+				row = append(row, item.SignatureStatus)
+			}
+			rows = append(rows, row)
 		}
 	} else {
 		rows = append(rows, []string{"", "", ""})
@@ -702,9 +938,10 @@ func showPluginListAsJSON(pluginList []plugin.PluginListItem, failedPluginMap, m
 
 	for _, item := range pluginList {
 		installed := installedPlugin{
-			Name:        item.Name,
-			Version:     item.Version.String(),
-			Connections: item.Connections,
+			Name:            item.Name,
+			Version:         item.Version.String(),
+			Connections:     item.Connections,
+			SignatureStatus: item.SignatureStatus,
 		}
 		output.Installed = append(output.Installed, installed)
 	}
@@ -739,11 +976,9 @@ func showPluginListAsJSON(pluginList []plugin.PluginListItem, failedPluginMap, m
 		output.Warnings = res.Warnings
 	}
 
-	jsonOutput, err := json.MarshalIndent(output, "", "  ")
-	if err != nil {
+	if err := printJSON(output); err != nil {
 		return err
 	}
-	fmt.Println(string(jsonOutput))
 	fmt.Println()
 	return nil
 }
@@ -798,6 +1033,39 @@ func runPluginUninstallCmd(cmd *cobra.Command, args []string) {
 	reports.Print()
 }
 
+func runPluginRollbackCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("runPluginRollbackCmd start")
+
+	defer func() {
+		utils.LogTime("runPluginRollbackCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	pluginName := args[0]
+	restored, err := plugin.Rollback(ctx, pluginName)
+	statushooks.Done(ctx)
+	if err != nil {
+		if errors.Is(err, ociinstaller.ErrPluginNotInstalled) {
+			exitCode = constants.ExitCodePluginNotFound
+		} else {
+			exitCode = constants.ExitCodePluginInstallFailure
+		}
+		error_helpers.ShowErrorWithMessage(ctx, err, fmt.Sprintf("Failed to roll back plugin '%s'", pluginName))
+		return
+	}
+
+	// restart any running instances of the plugin
+	if err := pluginmanager.RefreshConnectionsIfRunning(); err != nil {
+		error_helpers.ShowWarning(fmt.Sprintf("failed to restart plugin '%s': %s", pluginName, err.Error()))
+	}
+
+	fmt.Printf("Rolled back plugin %s from %s to %s\n", restored.Name, restored.RolledBackFrom, restored.Version)
+}
+
 func getPluginList(ctx context.Context) (pluginList []plugin.PluginListItem, failedPluginMap, missingPluginMap map[string][]*modconfig.Connection, res *error_helpers.ErrorAndWarnings) {
 	statushooks.Show(ctx)
 	defer statushooks.Done(ctx)
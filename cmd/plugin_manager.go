@@ -1,26 +1,37 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/hashicorp/go-hclog"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/go-kit/filewatcher"
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/go-kit/logging"
 	"github.com/turbot/go-kit/types"
 	sdklogging "github.com/turbot/steampipe-plugin-sdk/v5/logging"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe/pkg/auditlog"
 	"github.com/turbot/steampipe/pkg/cmdconfig"
 	"github.com/turbot/steampipe/pkg/connection"
 	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/db/db_local"
 	"github.com/turbot/steampipe/pkg/filepaths"
 	"github.com/turbot/steampipe/pkg/pluginmanager_service"
+	"github.com/turbot/steampipe/pkg/servicehealth"
 	"github.com/turbot/steampipe/pkg/steampipeconfig"
 )
 
@@ -67,6 +78,19 @@ func doRunPluginManager(cmd *cobra.Command) error {
 		defer connectionWatcher.Close()
 	}
 
+	if healthPort := viper.GetInt(constants.ArgDatabaseHealthPort); healthPort > 0 {
+		healthServer := newServiceHealthServer(pluginManager.Pool(), healthPort)
+		healthServer.start()
+		defer healthServer.stop()
+	}
+
+	if viper.GetBool(constants.ArgDatabaseAuditLog) {
+		log.Printf("[INFO] starting audit log collector")
+		auditCollector := auditlog.NewCollector(filepaths.EnsureLogDir(), filepaths.AuditLogStateFilePath())
+		auditCollector.Start()
+		defer auditCollector.Stop()
+	}
+
 	log.Printf("[INFO] about to serve")
 	pluginManager.Serve()
 	return nil
@@ -106,6 +130,102 @@ func createPluginManager(cmd *cobra.Command) (*pluginmanager_service.PluginManag
 	return pluginManager, nil
 }
 
+// serviceHealthServer runs the health server for the service
+//
+// the health server is only run for the service - not when the db is started by another command
+// if the db was started by another command, the db state file is watched, and the health server is started
+// if 'service start' makes the service persistent
+type serviceHealthServer struct {
+	pool    *pgxpool.Pool
+	port    int
+	server  *servicehealth.HealthServer
+	watcher *filewatcher.FileWatcher
+	// set once the health server has been started (or has failed to start)
+	started bool
+	mut     sync.Mutex
+}
+
+func newServiceHealthServer(pool *pgxpool.Pool, port int) *serviceHealthServer {
+	return &serviceHealthServer{pool: pool, port: port}
+}
+
+func (s *serviceHealthServer) start() {
+	if s.startIfService() {
+		return
+	}
+
+	log.Printf("[INFO] db was not started by the service - health server will be started if the service is made persistent")
+	watcher, err := filewatcher.NewWatcher(&filewatcher.WatcherOptions{
+		Directories: []string{filepaths.EnsureInternalDir()},
+		Include:     filehelpers.InclusionsFromFiles([]string{filepath.Base(filepaths.RunningInfoFilePath())}),
+		ListFlag:    filehelpers.FilesFlat,
+		EventMask:   fsnotify.Create | fsnotify.Write | fsnotify.Rename,
+		OnChange: func([]fsnotify.Event) {
+			s.startIfService()
+		},
+	})
+	if err != nil {
+		log.Printf("[WARN] failed to watch the db state file - health server will not be started: %s", err.Error())
+		return
+	}
+	s.mut.Lock()
+	s.watcher = watcher
+	s.mut.Unlock()
+	watcher.Start()
+	// the invoker may have been converted before the watcher started
+	s.startIfService()
+}
+
+// startIfService starts the health server if the running db was started (or has been made persistent) by the service command
+// it returns whether the health server has been started
+func (s *serviceHealthServer) startIfService() bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.started {
+		return true
+	}
+	if !isServiceInvoker() {
+		return false
+	}
+	s.started = true
+
+	// only listen on all interfaces if explicitly configured
+	healthListen := "localhost"
+	if viper.GetString(constants.ArgDatabaseHealthListen) == constants.HealthListenNetwork {
+		healthListen = ""
+	}
+	healthServer := servicehealth.NewHealthServer(s.pool, healthListen, s.port)
+	// failure to start the health server should not prevent the plugin manager from running
+	if err := healthServer.Start(); err != nil {
+		log.Printf("[WARN] failed to start health server on port %d: %s", s.port, err.Error())
+	} else {
+		s.server = healthServer
+	}
+	return true
+}
+
+func (s *serviceHealthServer) stop() {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.watcher != nil {
+		s.watcher.Close()
+	}
+	if s.server != nil {
+		s.server.Shutdown(context.Background())
+	}
+}
+
+// isServiceInvoker returns whether the running db was started by the service command
+func isServiceInvoker() bool {
+	dbState, err := db_local.GetState()
+	if err != nil || dbState == nil {
+		return false
+	}
+	return dbState.Invoker == constants.InvokerService
+}
+
 func shouldRunConnectionWatcher() bool {
 	// if EnvConnectionWatcher is set, overwrite the value in DefaultConnectionOptions
 	if envStr, ok := os.LookupEnv(constants.EnvConnectionWatcher); ok {
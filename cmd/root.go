@@ -98,6 +98,9 @@ func AddCommands() {
 		dashboardCmd(),
 		variableCmd(),
 		loginCmd(),
+		secretCmd(),
+		connectionCmd(),
+		configCmd(),
 	)
 }
 
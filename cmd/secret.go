@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe/pkg/cmdconfig"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/error_helpers"
+	"github.com/turbot/steampipe/pkg/secrets"
+)
+
+// Secret management commands
+func secretCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "secret [command]",
+		Args:  cobra.NoArgs,
+		Short: "Steampipe secret management",
+		Long: fmt.Sprintf(`Steampipe secret management.
+
+Secrets are stored in a local file, encrypted with the key given by %s.
+Connection config may reference them using the 'secret' function, so
+credentials need not be stored in plain text in .spc files:
+
+  connection "aws_prod" {
+    plugin     = "aws"
+    access_key = secret("aws_prod_access_key")
+    secret_key = secret("aws_prod_secret_key")
+    profile    = env("AWS_PROD_PROFILE")
+  }
+
+Secrets may also be resolved by a credential helper command, given by
+%s. The secret name is passed as the final argument of the
+command and the value is read from stdout. If the helper exits with code 3
+the secret is looked up in the secrets file.
+
+Examples:
+
+  # Generate an encryption key for the secrets file
+  export %s=$(steampipe secret generate-key)
+
+  # Add a secret, reading the value from stdin
+  steampipe secret set aws_prod_access_key < access_key.txt
+
+  # List the names of stored secrets
+  steampipe secret list`, constants.EnvSecretsKey, constants.EnvSecretsHelper, constants.EnvSecretsKey),
+	}
+
+	cmd.AddCommand(secretSetCmd())
+	cmd.AddCommand(secretRemoveCmd())
+	cmd.AddCommand(secretListCmd())
+	cmd.AddCommand(secretGenerateKeyCmd())
+	cmd.Flags().BoolP(constants.ArgHelp, "h", false, "Help for secret")
+
+	return cmd
+}
+
+func secretSetCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "set <name>",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSecretSetCmd,
+		Short: "Add or update a secret",
+		Long: `Add or update a secret in the secrets file.
+
+The secret value is read from stdin, so it does not appear in shell history.
+
+Example:
+
+  # Add a secret
+  steampipe secret set aws_prod_access_key < access_key.txt
+`,
+	}
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for secret set", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func secretRemoveCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "remove <name>",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSecretRemoveCmd,
+		Short: "Remove a secret",
+		Long: `Remove a secret from the secrets file.
+
+Example:
+
+  # Remove a secret
+  steampipe secret remove aws_prod_access_key
+`,
+	}
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for secret remove", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func secretListCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "list",
+		Args:  cobra.NoArgs,
+		Run:   runSecretListCmd,
+		Short: "List the names of stored secrets",
+		Long: `List the names of the secrets in the secrets file. Secret values are never shown.
+
+Example:
+
+  # List secrets
+  steampipe secret list
+`,
+	}
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for secret list", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func secretGenerateKeyCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "generate-key",
+		Args:  cobra.NoArgs,
+		Run:   runSecretGenerateKeyCmd,
+		Short: "Generate an encryption key for the secrets file",
+		Long: fmt.Sprintf(`Generate a random encryption key for the secrets file.
+
+Store the key securely and set it as %s.
+
+Example:
+
+  # Generate a key
+  export %s=$(steampipe secret generate-key)
+`, constants.EnvSecretsKey, constants.EnvSecretsKey),
+	}
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for secret generate-key", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func runSecretSetCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	defer func() {
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	provider, err := secretsFileProvider()
+	error_helpers.FailOnError(err)
+
+	value, err := io.ReadAll(os.Stdin)
+	error_helpers.FailOnErrorWithMessage(err, "failed to read secret value from stdin")
+
+	// remove the trailing newline added when the value is piped or typed
+	error_helpers.FailOnError(provider.Set(args[0], strings.TrimRight(string(value), "\r\n")))
+	fmt.Printf("Set secret '%s'\n", args[0])
+}
+
+func runSecretRemoveCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	defer func() {
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	provider, err := secretsFileProvider()
+	error_helpers.FailOnError(err)
+
+	removed, err := provider.Remove(args[0])
+	error_helpers.FailOnError(err)
+	if !removed {
+		error_helpers.ShowError(ctx, fmt.Errorf("secret '%s' not found", args[0]))
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return
+	}
+	fmt.Printf("Removed secret '%s'\n", args[0])
+}
+
+func runSecretListCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	defer func() {
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	provider, err := secretsFileProvider()
+	error_helpers.FailOnError(err)
+
+	names, err := provider.Names()
+	error_helpers.FailOnError(err)
+	for _, name := range names {
+		fmt.Println(name)
+	}
+}
+
+func runSecretGenerateKeyCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	defer func() {
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	key, err := secrets.GenerateKey()
+	error_helpers.FailOnError(err)
+	fmt.Println(key)
+}
+
+// secretsFileProvider returns a provider for the secrets file, using the key from the environment
+func secretsFileProvider() (*secrets.FileProvider, error) {
+	key, ok := os.LookupEnv(constants.EnvSecretsKey)
+	if !ok || key == "" {
+		return nil, fmt.Errorf("%s must be set to manage secrets - use 'steampipe secret generate-key' to create a key", constants.EnvSecretsKey)
+	}
+	return secrets.NewFileProvider(secrets.SecretsFilePath(), key)
+}
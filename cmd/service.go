@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,6 +18,7 @@ import (
 	"github.com/spf13/viper"
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"github.com/turbot/steampipe/pkg/auditlog"
 	"github.com/turbot/steampipe/pkg/cmdconfig"
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/dashboard/dashboardserver"
@@ -43,6 +47,7 @@ connection from any Postgres compatible database client.`,
 	cmd.AddCommand(serviceStatusCmd())
 	cmd.AddCommand(serviceStopCmd())
 	cmd.AddCommand(serviceRestartCmd())
+	cmd.AddCommand(serviceLogsCmd())
 	cmd.Flags().BoolP(constants.ArgHelp, "h", false, "Help for service")
 	return cmd
 }
@@ -98,14 +103,23 @@ func serviceStatusCmd() *cobra.Command {
 		Short: "Status of the Steampipe service",
 		Long: `Status of the Steampipe service.
 
-Report current status of the Steampipe database service.`,
+Report current status of the Steampipe database service.
+
+Examples:
+
+  # Get the status of the service
+  steampipe service status
+
+  # Get the status of the service as json
+  steampipe service status --output json`,
 	}
 
 	cmdconfig.OnCmd(cmd).
 		AddBoolFlag(constants.ArgHelp, false, "Help for service status", cmdconfig.FlagOptions.WithShortHand("h")).
 		// default is false and hides the database user password from service start prompt
 		AddBoolFlag(constants.ArgServiceShowPassword, false, "View database password for connecting from another machine").
-		AddBoolFlag(constants.ArgAll, false, "Bypasses the INSTALL_DIR and reports status of all running steampipe services")
+		AddBoolFlag(constants.ArgAll, false, "Bypasses the INSTALL_DIR and reports status of all running steampipe services").
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatTable, "Output format: table or json")
 
 	return cmd
 }
@@ -146,6 +160,42 @@ func serviceRestartCmd() *cobra.Command {
 	return cmd
 }
 
+// handler for service logs
+func serviceLogsCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "logs",
+		Args:  cobra.NoArgs,
+		Run:   runServiceLogsCmd,
+		Short: "View the Steampipe service logs",
+		Long: `View the Steampipe service logs.
+
+Show the most recent entries of the database log or, with --audit, the query
+audit log. The audit log is only written if 'audit_log' is enabled in the
+database options. Queries are audited when they complete, with their duration
+(the number of rows returned is not available from the database log).
+
+Examples:
+
+  # Show the last 100 lines of the database log
+  steampipe service logs
+
+  # Show the last 20 queries run against the service
+  steampipe service logs --audit --tail 20
+
+  # Show the audit log as json lines
+  steampipe service logs --audit --output json`,
+	}
+
+	cmdconfig.
+		OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for service logs", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddBoolFlag(constants.ArgAudit, false, "Show the query audit log").
+		AddIntFlag(constants.ArgTail, 100, "Number of most recent entries to show (0 to show all)").
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatTable, "Output format for the audit log: table or json")
+
+	return cmd
+}
+
 func runServiceStartCmd(cmd *cobra.Command, _ []string) {
 	ctx := cmd.Context()
 	utils.LogTime("runServiceStartCmd start")
@@ -478,13 +528,24 @@ func runServiceStatusCmd(cmd *cobra.Command, _ []string) {
 		}
 	}()
 
+	if err := cmdconfig.ValidateOutputFormat(constants.OutputFormatTable, constants.OutputFormatJSON); err != nil {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		error_helpers.FailOnError(err)
+	}
+	outputFormat := viper.GetString(constants.ArgOutput)
+	jsonOutput := outputFormat == constants.OutputFormatJSON
+
 	if !db_local.IsDBInstalled() || !db_local.IsFDWInstalled() {
+		if jsonOutput {
+			error_helpers.FailOnError(printJSON(&serviceStatusJsonOutput{}))
+			return
+		}
 		fmt.Println("Steampipe service is not installed.")
 		return
 	}
 
 	if viper.GetBool(constants.ArgAll) {
-		showAllStatus(cmd.Context())
+		showAllStatus(cmd.Context(), jsonOutput)
 	} else {
 		dbState, dbStateErr := db_local.GetState()
 		pmState, pmStateErr := pluginmanager.LoadState()
@@ -494,10 +555,69 @@ func runServiceStatusCmd(cmd *cobra.Command, _ []string) {
 			error_helpers.ShowError(ctx, composeStateError(dbStateErr, pmStateErr, dashboardStateErr))
 			return
 		}
+		if jsonOutput {
+			error_helpers.FailOnError(printStatusAsJSON(dbState, pmState, dashboardState))
+			return
+		}
 		printStatus(ctx, dbState, pmState, dashboardState, false)
 	}
 }
 
+// serviceStatusJsonOutput is the json representation of the service status
+type serviceStatusJsonOutput struct {
+	Installed     bool                                   `json:"installed"`
+	Running       bool                                   `json:"running"`
+	Database      *db_local.RunningDBInstanceInfo        `json:"database,omitempty"`
+	PluginManager *pluginManagerStatus                   `json:"plugin_manager,omitempty"`
+	Dashboard     *dashboardserver.DashboardServiceState `json:"dashboard,omitempty"`
+}
+
+type pluginManagerStatus struct {
+	Running bool `json:"running"`
+	Pid     int  `json:"pid,omitempty"`
+}
+
+// serviceProcessJsonOutput is the json representation of a single service found by 'service status --all'
+type serviceProcessJsonOutput struct {
+	Pid        int    `json:"pid"`
+	InstallDir string `json:"install_dir"`
+	Port       string `json:"port"`
+	Listen     string `json:"listen"`
+}
+
+func printStatusAsJSON(dbState *db_local.RunningDBInstanceInfo, pmState *pluginmanager.State, dashboardState *dashboardserver.DashboardServiceState) error {
+	output := &serviceStatusJsonOutput{
+		Installed: true,
+		Running:   dbState != nil,
+		Dashboard: dashboardState,
+	}
+	if dbState != nil {
+		// take a copy so we can redact the password
+		database := *dbState
+		if !viper.GetBool(constants.ArgServiceShowPassword) {
+			database.Password = ""
+		}
+		output.Database = &database
+	}
+	if pmState != nil {
+		output.PluginManager = &pluginManagerStatus{Running: pmState.Running}
+		if pmState.Running {
+			output.PluginManager.Pid = pmState.Pid
+		}
+	}
+	return printJSON(output)
+}
+
+// printJSON prints the output as indented json - this is used by all commands which support '--output json'
+func printJSON(output any) error {
+	jsonOutput, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
 func composeStateError(dbStateErr error, pmStateErr error, dashboardStateErr error) error {
 	msg := "could not get Steampipe service status:"
 
@@ -619,7 +739,7 @@ to force a shutdown.
 	}
 }
 
-func showAllStatus(ctx context.Context) {
+func showAllStatus(ctx context.Context, jsonOutput bool) {
 	var processes []*psutils.Process
 	var err error
 
@@ -629,6 +749,21 @@ func showAllStatus(ctx context.Context) {
 
 	error_helpers.FailOnError(err)
 
+	if jsonOutput {
+		output := make([]serviceProcessJsonOutput, len(processes))
+		for i, process := range processes {
+			_, installDir, port, listen := getServiceProcessDetails(process)
+			output[i] = serviceProcessJsonOutput{
+				Pid:        int(process.Pid),
+				InstallDir: installDir,
+				Port:       port,
+				Listen:     string(listen),
+			}
+		}
+		error_helpers.FailOnError(printJSON(output))
+		return
+	}
+
 	if len(processes) == 0 {
 		fmt.Println("There are no steampipe services running.")
 		return
@@ -821,3 +956,94 @@ Not shutting down service as there as clients connected.
 To force shutdown, press Ctrl+C again.
 	`
 }
+
+func runServiceLogsCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("runServiceLogsCmd start")
+	defer func() {
+		utils.LogTime("runServiceLogsCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			if exitCode == constants.ExitCodeSuccessful {
+				exitCode = constants.ExitCodeUnknownErrorPanic
+			}
+		}
+	}()
+
+	if err := cmdconfig.ValidateOutputFormat(constants.OutputFormatTable, constants.OutputFormatJSON); err != nil {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		error_helpers.FailOnError(err)
+	}
+	outputFormat := viper.GetString(constants.ArgOutput)
+	tail := viper.GetInt(constants.ArgTail)
+
+	if !viper.GetBool(constants.ArgAudit) {
+		error_helpers.FailOnError(showDatabaseLog(tail))
+		return
+	}
+
+	records, err := auditlog.ReadRecords(filepaths.EnsureLogDir(), tail)
+	error_helpers.FailOnError(err)
+	if len(records) == 0 && outputFormat == constants.OutputFormatTable {
+		if !viper.GetBool(constants.ArgDatabaseAuditLog) {
+			fmt.Printf("The audit log is not enabled. To enable it, set %s in the database options.\n", constants.Bold("audit_log = true"))
+		} else {
+			fmt.Println("No queries have been audited.")
+		}
+		return
+	}
+	for _, r := range records {
+		if outputFormat == constants.OutputFormatJSON {
+			line, err := json.Marshal(r)
+			error_helpers.FailOnError(err)
+			fmt.Println(string(line))
+			continue
+		}
+		fmt.Println(formatAuditRecord(r))
+	}
+}
+
+func formatAuditRecord(r auditlog.Record) string {
+	prefix := fmt.Sprintf("%s %s@%s [%s]", r.Time.Format(time.RFC3339), r.User, r.ClientAddress, r.ApplicationName)
+	if r.Type == auditlog.RecordTypeConnection {
+		return fmt.Sprintf("%s connected to %s", prefix, r.Database)
+	}
+	// show the query on a single line
+	query := strings.Join(strings.Fields(r.Query), " ")
+	if r.Error != "" {
+		return fmt.Sprintf("%s ERROR: %s: %s", prefix, r.Error, query)
+	}
+	if r.DurationMs > 0 {
+		return fmt.Sprintf("%s (%.3fms): %s", prefix, r.DurationMs, query)
+	}
+	return fmt.Sprintf("%s: %s", prefix, query)
+}
+
+// showDatabaseLog prints the last tail lines of the most recent database log file
+// (if the audit log is enabled, the database only writes a csvlog)
+func showDatabaseLog(tail int) error {
+	logGlob := "database-*.log"
+	if viper.GetBool(constants.ArgDatabaseAuditLog) {
+		logGlob = "database-*.csv"
+	}
+	logFiles, err := filepath.Glob(filepath.Join(filepaths.EnsureLogDir(), logGlob))
+	if err != nil {
+		return err
+	}
+	if len(logFiles) == 0 {
+		fmt.Println("No database logs found.")
+		return nil
+	}
+	// file names contain the date - the last one is the most recent
+	sort.Strings(logFiles)
+	content, err := os.ReadFile(logFiles[len(logFiles)-1])
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if tail > 0 && len(lines) > tail {
+		lines = lines[len(lines)-tail:]
+	}
+	fmt.Println(strings.Join(lines, "\n"))
+	return nil
+}
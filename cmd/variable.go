@@ -1,8 +1,6 @@
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/turbot/go-kit/helpers"
@@ -71,9 +69,8 @@ func runVariableListCmd(cmd *cobra.Command, _ []string) {
 	}()
 
 	// validate output arg
-	output := viper.GetString(constants.ArgOutput)
-	if !helpers.StringSliceContains([]string{constants.OutputFormatTable, constants.OutputFormatJSON}, output) {
-		error_helpers.ShowError(ctx, fmt.Errorf("output flag must be either 'json' or 'table'"))
+	if err := cmdconfig.ValidateOutputFormat(constants.OutputFormatTable, constants.OutputFormatJSON); err != nil {
+		error_helpers.ShowError(ctx, err)
 		return
 	}
 
@@ -0,0 +1,181 @@
+package auditlog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/turbot/go-kit/logging"
+)
+
+const (
+	// FilePrefix is the prefix of the audit log files - these are named {prefix}-YYYY-MM-DD.log
+	FilePrefix = "audit"
+	// the csvlog files written by the database - the database log_filename with a .csv extension
+	databaseCsvLogGlob = "database-*.csv"
+
+	collectorPollInterval = 2 * time.Second
+)
+
+// collectorState is persisted so that a restarted collector does not re-audit queries
+type collectorState struct {
+	File   string `json:"file"`
+	Offset int64  `json:"offset"`
+}
+
+// Collector tails the csvlog files written by the database and writes an audit record
+// for every query as a line of JSON to a daily rotating file in the log directory
+type Collector struct {
+	logDir    string
+	statePath string
+	state     collectorState
+	writer    io.Writer
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewCollector(logDir, statePath string) *Collector {
+	c := &Collector{
+		logDir:    logDir,
+		statePath: statePath,
+		writer:    logging.NewRotatingLogWriter(logDir, FilePrefix),
+		stopCh:    make(chan struct{}),
+	}
+	c.loadState()
+	return c
+}
+
+// Start polls the database csvlog for new records until Stop is called
+func (c *Collector) Start() {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(collectorPollInterval)
+		defer ticker.Stop()
+		for {
+			if err := c.collect(); err != nil {
+				log.Printf("[WARN] audit log collection failed: %s", err.Error())
+			}
+			select {
+			case <-c.stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops polling, after writing any records which have already been logged
+func (c *Collector) Stop() {
+	close(c.stopCh)
+	c.wg.Wait()
+	if err := c.collect(); err != nil {
+		log.Printf("[WARN] audit log collection failed: %s", err.Error())
+	}
+}
+
+// collect writes audit records for all complete csvlog records written since the last call
+func (c *Collector) collect() error {
+	files, err := filepath.Glob(filepath.Join(c.logDir, databaseCsvLogGlob))
+	if err != nil {
+		return err
+	}
+	// the file names contain the date, so sorting by name sorts by date
+	sort.Strings(files)
+
+	for _, f := range files {
+		name := filepath.Base(f)
+		if name < c.state.File {
+			continue
+		}
+		if name != c.state.File {
+			c.state = collectorState{File: name}
+		}
+		offset, err := c.collectFile(f, c.state.Offset)
+		if err != nil {
+			return err
+		}
+		c.state.Offset = offset
+	}
+	return c.saveState()
+}
+
+// collectFile processes the complete records in the file after offset, returning the offset after the last complete record
+func (c *Collector) collectFile(path string, offset int64) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return offset, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return offset, err
+	}
+	// the database may be part way through writing a record - only consider data up to the last newline
+	// (a record may contain newlines - if we cut one short, it will fail to parse and be picked up next time)
+	end := bytes.LastIndexByte(data, '\n')
+	if end == -1 {
+		return offset, nil
+	}
+	reader := csv.NewReader(bytes.NewReader(data[:end+1]))
+	reader.FieldsPerRecord = -1
+
+	var consumed int64
+	for {
+		fields, err := reader.Read()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				log.Printf("[TRACE] stopping audit log read of %s at incomplete record: %s", path, err.Error())
+			}
+			break
+		}
+		consumed = reader.InputOffset()
+		if record := parseRecord(fields); record != nil {
+			if err := c.write(record); err != nil {
+				return offset + consumed, err
+			}
+		}
+	}
+	return offset + consumed, nil
+}
+
+func (c *Collector) write(record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = c.writer.Write(append(line, '\n'))
+	return err
+}
+
+func (c *Collector) loadState() {
+	data, err := os.ReadFile(c.statePath)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &c.state); err != nil {
+		log.Printf("[WARN] ignoring invalid audit log state file %s: %s", c.statePath, err.Error())
+		c.state = collectorState{}
+	}
+}
+
+func (c *Collector) saveState() error {
+	data, err := json.Marshal(c.state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.statePath, data, 0600)
+}
@@ -0,0 +1,55 @@
+package auditlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ReadRecords returns the audit records in the log directory, oldest first
+// if tail is greater than zero, only the most recent tail records are returned
+func ReadRecords(logDir string, tail int) ([]Record, error) {
+	files, err := filepath.Glob(filepath.Join(logDir, FilePrefix+"-*.log"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var res []Record
+	// read the newest files first, so we can stop once we have enough records
+	for i := len(files) - 1; i >= 0; i-- {
+		records, err := readFile(files[i])
+		if err != nil {
+			return nil, err
+		}
+		res = append(records, res...)
+		if tail > 0 && len(res) >= tail {
+			return res[len(res)-tail:], nil
+		}
+	}
+	return res, nil
+}
+
+func readFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var res []Record
+	scanner := bufio.NewScanner(f)
+	// queries may be long - allow lines of up to 10Mb
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// skip lines we cannot parse - the file may have been partially written
+			continue
+		}
+		res = append(res, r)
+	}
+	return res, scanner.Err()
+}
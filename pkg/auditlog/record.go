@@ -0,0 +1,110 @@
+package auditlog
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RecordTypeConnection = "connection"
+	RecordTypeQuery      = "query"
+)
+
+// Record is a single entry in the audit log - a connection to the service, or a query which was run against it
+//
+// NOTE: the number of rows returned by a query is not included - postgres does not write it to the csvlog
+type Record struct {
+	Time            time.Time `json:"time"`
+	Type            string    `json:"type"`
+	ClientAddress   string    `json:"client_address"`
+	ApplicationName string    `json:"application_name"`
+	User            string    `json:"user"`
+	Database        string    `json:"database"`
+	SessionId       string    `json:"session_id"`
+	Query           string    `json:"query,omitempty"`
+	DurationMs      float64   `json:"duration_ms,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// indexes of the fields of a postgres csvlog record
+// https://www.postgresql.org/docs/14/runtime-config-logging.html#RUNTIME-CONFIG-LOGGING-CSVLOG
+const (
+	csvLogTime         = 0
+	csvUser            = 1
+	csvDatabase        = 2
+	csvConnectionFrom  = 4
+	csvSessionId       = 5
+	csvErrorSeverity   = 11
+	csvMessage         = 13
+	csvQuery           = 19
+	csvApplicationName = 22
+	csvBackendType     = 23
+	csvMinFields       = 23
+)
+
+const csvLogTimeFormat = "2006-01-02 15:04:05.000 MST"
+
+var (
+	// statement: select 1
+	// execute <unnamed>: select 1
+	// duration: 0.052 ms  statement: select 1
+	// duration: 0.052 ms  execute <unnamed>: select 1
+	statementRegex = regexp.MustCompile(`(?s)^(?:duration: ([0-9.]+) ms\s+)?(?:statement|execute [^:]*): (.*)$`)
+	// connection authorized: user=steampipe database=steampipe application_name=psql
+	connectionRegex = regexp.MustCompile(`^connection authorized: `)
+)
+
+// parseRecord returns the audit record for the csvlog record,
+// or nil if the record is not a connection, a statement or a failed statement
+func parseRecord(fields []string) *Record {
+	if len(fields) < csvMinFields {
+		return nil
+	}
+	// only audit client sessions (ignore autovacuum, checkpointer etc.)
+	if len(fields) > csvBackendType && fields[csvBackendType] != "client backend" {
+		return nil
+	}
+
+	message := fields[csvMessage]
+
+	switch fields[csvErrorSeverity] {
+	case "ERROR", "FATAL":
+		if fields[csvQuery] == "" {
+			return nil
+		}
+		r := newRecord(fields, RecordTypeQuery)
+		r.Query = fields[csvQuery]
+		r.Error = message
+		return r
+	case "LOG":
+		if connectionRegex.MatchString(message) {
+			return newRecord(fields, RecordTypeConnection)
+		}
+		match := statementRegex.FindStringSubmatch(message)
+		if match == nil {
+			return nil
+		}
+		r := newRecord(fields, RecordTypeQuery)
+		r.Query = strings.TrimSpace(match[2])
+		if match[1] != "" {
+			r.DurationMs, _ = strconv.ParseFloat(match[1], 64)
+		}
+		return r
+	}
+	return nil
+}
+
+func newRecord(fields []string, recordType string) *Record {
+	logTime, _ := time.Parse(csvLogTimeFormat, fields[csvLogTime])
+	return &Record{
+		Time:            logTime,
+		Type:            recordType,
+		ClientAddress:   fields[csvConnectionFrom],
+		ApplicationName: fields[csvApplicationName],
+		User:            fields[csvUser],
+		Database:        fields[csvDatabase],
+		SessionId:       fields[csvSessionId],
+	}
+}
@@ -0,0 +1,74 @@
+package auditlog
+
+import (
+	"testing"
+)
+
+// build a PG14 csvlog record with the given severity, message and query
+func csvRecord(session, severity, message, query string) []string {
+	fields := make([]string, 26)
+	fields[csvLogTime] = "2023-06-01 10:00:00.000 UTC"
+	fields[csvUser] = "steampipe"
+	fields[csvDatabase] = "steampipe"
+	fields[csvConnectionFrom] = "10.0.0.1:54321"
+	fields[csvSessionId] = session
+	fields[csvErrorSeverity] = severity
+	fields[csvMessage] = message
+	fields[csvQuery] = query
+	fields[csvApplicationName] = "psql"
+	fields[csvBackendType] = "client backend"
+	return fields
+}
+
+func TestParseRecord(t *testing.T) {
+	// connections are audited
+	r := parseRecord(csvRecord("s1", "LOG", "connection authorized: user=steampipe database=steampipe application_name=psql", ""))
+	if r == nil || r.Type != RecordTypeConnection || r.User != "steampipe" || r.ClientAddress != "10.0.0.1:54321" || r.ApplicationName != "psql" {
+		t.Errorf("unexpected record %+v", r)
+	}
+
+	// connection received records are not audited - they do not have the user
+	if r := parseRecord(csvRecord("s1", "LOG", "connection received: host=10.0.0.1 port=54321", "")); r != nil {
+		t.Errorf("expected connection received record to be skipped, got %v", r)
+	}
+
+	// simple protocol
+	r = parseRecord(csvRecord("s1", "LOG", "statement: select 1", ""))
+	if r == nil || r.Type != RecordTypeQuery || r.Query != "select 1" || r.SessionId != "s1" {
+		t.Errorf("unexpected record %+v", r)
+	}
+
+	// extended protocol
+	r = parseRecord(csvRecord("s2", "LOG", "execute <unnamed>: select * from aws_s3_bucket", ""))
+	if r == nil || r.Query != "select * from aws_s3_bucket" {
+		t.Errorf("unexpected record %+v", r)
+	}
+
+	// statements logged on completion include the duration
+	r = parseRecord(csvRecord("s2", "LOG", "duration: 12.345 ms  statement: select 1", ""))
+	if r == nil || r.Query != "select 1" || r.DurationMs != 12.345 {
+		t.Errorf("unexpected record %+v", r)
+	}
+	r = parseRecord(csvRecord("s2", "LOG", "duration: 0.052 ms  execute <unnamed>: select * from aws_s3_bucket", ""))
+	if r == nil || r.Query != "select * from aws_s3_bucket" || r.DurationMs != 0.052 {
+		t.Errorf("unexpected record %+v", r)
+	}
+
+	// the parse and bind steps of the extended protocol are not audited
+	if r := parseRecord(csvRecord("s2", "LOG", "duration: 0.010 ms  parse <unnamed>: select 1", "")); r != nil {
+		t.Errorf("expected parse record to be skipped, got %v", r)
+	}
+
+	// errors are audited with the failing statement
+	r = parseRecord(csvRecord("s3", "ERROR", "relation \"foo\" does not exist", "select * from foo"))
+	if r == nil || r.Error != "relation \"foo\" does not exist" || r.Query != "select * from foo" {
+		t.Errorf("unexpected record %+v", r)
+	}
+
+	// other backends are ignored
+	fields := csvRecord("s4", "LOG", "statement: vacuum", "")
+	fields[csvBackendType] = "autovacuum worker"
+	if r := parseRecord(fields); r != nil {
+		t.Errorf("expected non client backend record to be skipped, got %v", r)
+	}
+}
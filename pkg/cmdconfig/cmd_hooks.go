@@ -206,6 +206,13 @@ func initGlobalConfig() *error_helpers.ErrorAndWarnings {
 
 	// load the connection config and HCL options
 	config, loadConfigErrorsAndWarnings := steampipeconfig.LoadSteampipeConfig(viper.GetString(constants.ArgModLocation), cmd.Name())
+	// commands which report on the config themselves (i.e. 'config validate') must be able to run when the config is invalid
+	if viper.GetBool(constants.ArgIgnoreConfigErrors) {
+		if loadConfigErrorsAndWarnings.Error != nil {
+			config = steampipeconfig.NewSteampipeConfig(cmd.Name())
+		}
+		loadConfigErrorsAndWarnings = error_helpers.NewErrorsAndWarning(nil)
+	}
 	if loadConfigErrorsAndWarnings.Error != nil {
 		return loadConfigErrorsAndWarnings
 	}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/spf13/viper"
 	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/steampipe/pkg/cloud"
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/error_helpers"
@@ -99,3 +100,12 @@ func validateSnapshotTags() error {
 	}
 	return nil
 }
+
+// ValidateOutputFormat returns an error if the output arg is not one of the given formats
+func ValidateOutputFormat(validFormats ...string) error {
+	output := viper.GetString(constants.ArgOutput)
+	if !helpers.StringSliceContains(validFormats, output) {
+		return fmt.Errorf("invalid output format: '%s', must be one of [%s]", output, strings.Join(validFormats, ", "))
+	}
+	return nil
+}
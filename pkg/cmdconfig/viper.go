@@ -103,6 +103,8 @@ func setBaseDefaults() {
 		constants.ArgDatabaseStartTimeout: constants.DBStartTimeout.Seconds(),
 		constants.ArgServiceCacheEnabled:  true,
 		constants.ArgCacheMaxTtl:          300,
+		constants.ArgDatabaseHealthListen: constants.HealthListenLocal,
+		constants.ArgDatabaseAuditLog:     false,
 
 		// dashboard
 		constants.ArgDashboardStartTimeout: constants.DashboardStartTimeout.Seconds(),
@@ -110,6 +112,9 @@ func setBaseDefaults() {
 		// memory
 		constants.ArgMemoryMaxMbPlugin: 1024,
 		constants.ArgMemoryMaxMb:       1024,
+
+		// plugin
+		constants.ArgPluginSignaturePolicy: constants.PluginSignaturePolicyOff,
 	}
 
 	for k, v := range defaults {
@@ -164,11 +169,16 @@ func setDefaultsFromEnv() {
 		constants.EnvMaxParallel:           {[]string{constants.ArgMaxParallel}, Int},
 		constants.EnvQueryTimeout:          {[]string{constants.ArgDatabaseQueryTimeout}, Int},
 		constants.EnvDatabaseStartTimeout:  {[]string{constants.ArgDatabaseStartTimeout}, Int},
+		constants.EnvDatabaseHealthListen:  {[]string{constants.ArgDatabaseHealthListen}, String},
+		constants.EnvDatabaseHealthPort:    {[]string{constants.ArgDatabaseHealthPort}, Int},
+		constants.EnvDatabaseAuditLog:      {[]string{constants.ArgDatabaseAuditLog}, Bool},
 		constants.EnvDashboardStartTimeout: {[]string{constants.ArgDashboardStartTimeout}, Int},
 		constants.EnvCacheTTL:              {[]string{constants.ArgCacheTtl}, Int},
 		constants.EnvCacheMaxTTL:           {[]string{constants.ArgCacheMaxTtl}, Int},
 		constants.EnvMemoryMaxMb:           {[]string{constants.ArgMemoryMaxMb}, Int},
 		constants.EnvMemoryMaxMbPlugin:     {[]string{constants.ArgMemoryMaxMbPlugin}, Int},
+		constants.EnvPluginSignaturePolicy: {[]string{constants.ArgPluginSignaturePolicy}, String},
+		constants.EnvPluginSignatureKey:    {[]string{constants.ArgPluginSignatureKey}, String},
 
 		// we need this value to go into different locations
 		constants.EnvCacheEnabled: {[]string{
@@ -0,0 +1,319 @@
+package connection
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"github.com/turbot/steampipe/pkg/db/db_common"
+	"github.com/turbot/steampipe/pkg/steampipeconfig"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/utils"
+	"golang.org/x/exp/maps"
+)
+
+// the schema comment of a cross-plugin aggregator - this is followed by a hash of the view definitions
+const crossPluginAggregatorSchemaComment = "steampipe cross-plugin aggregator"
+
+// columns of the plugin tables which are never included in an aggregator table
+// NOTE: sp_connection_name is added to every aggregator table, with the name of the child connection
+var crossPluginAggregatorExcludedColumns = map[string]struct{}{
+	"sp_connection_name": {},
+	"sp_ctx":             {},
+	"_ctx":               {},
+}
+
+// map of schema name to table name to the (ordered) column names of the table
+type schemaColumnMap map[string]map[string][]string
+
+// updateCrossPluginAggregators creates the views of all cross-plugin aggregators and deletes the schemas
+// of any cross-plugin aggregators which are no longer in the config
+//
+// this must be called after the connection schemas have been updated, as recreating a connection schema
+// drops any aggregator views which reference it
+// an aggregator schema is only recreated if its view definitions have changed (or any of its views have been dropped)
+// failures are reported as warnings
+func (s *refreshConnectionState) updateCrossPluginAggregators(ctx context.Context) {
+	aggregators := steampipeconfig.GlobalConfig.CrossPluginAggregators
+	existingSchemas, err := s.getCrossPluginAggregatorSchemas(ctx)
+	if err != nil {
+		s.res.AddWarning(fmt.Sprintf("failed to load cross-plugin aggregator schemas: %s", err.Error()))
+		return
+	}
+
+	// delete the schemas of aggregators which are no longer in config
+	for schemaName := range existingSchemas {
+		if _, ok := aggregators[schemaName]; ok {
+			continue
+		}
+		log.Printf("[INFO] deleting cross-plugin aggregator schema '%s'", schemaName)
+		if _, err := s.pool.Exec(ctx, db_common.GetDeleteConnectionQuery(schemaName)); err != nil {
+			s.res.AddWarning(fmt.Sprintf("failed to delete cross-plugin aggregator '%s': %s", schemaName, err.Error()))
+		}
+	}
+	if len(aggregators) == 0 {
+		return
+	}
+
+	// load the columns of the plugin tables of all child connections
+	// (child connections with lazy schema import whose schema has not been imported have no tables,
+	// so are not included in the views until a later refresh imports their schema)
+	childSchemas := make(map[string]struct{})
+	for _, aggregator := range aggregators {
+		for connectionName := range aggregator.Connections {
+			if s.connectionIsLazy(connectionName) {
+				continue
+			}
+			childSchemas[connectionName] = struct{}{}
+		}
+	}
+	columns, err := s.getSchemaColumns(ctx, maps.Keys(childSchemas))
+	if err != nil {
+		s.res.AddWarning(fmt.Sprintf("failed to load columns for cross-plugin aggregators: %s", err.Error()))
+		return
+	}
+
+	for _, aggregatorName := range utils.SortedMapKeys(aggregators) {
+		aggregator := aggregators[aggregatorName]
+		viewSql, warnings := getCrossPluginAggregatorViewsSql(aggregator, columns)
+		s.res.AddWarning(warnings...)
+
+		// if the views are unchanged, there is nothing to do
+		comment := getCrossPluginAggregatorSchemaComment(viewSql)
+		if existing, ok := existingSchemas[aggregatorName]; ok && existing.comment == comment && existing.viewCount == len(viewSql) {
+			log.Printf("[TRACE] cross-plugin aggregator '%s' is up to date", aggregatorName)
+			continue
+		}
+		log.Printf("[INFO] updating cross-plugin aggregator '%s' (%d %s)", aggregatorName, len(viewSql), utils.Pluralize("view", len(viewSql)))
+		if err := s.executeCrossPluginAggregatorQuery(ctx, aggregatorName, comment, viewSql); err != nil {
+			s.res.AddWarning(fmt.Sprintf("failed to update cross-plugin aggregator '%s': %s", aggregatorName, err.Error()))
+		}
+	}
+}
+
+// connectionIsLazy returns whether the connection has lazy schema import and its schema has not been imported
+func (s *refreshConnectionState) connectionIsLazy(connectionName string) bool {
+	if s.connectionUpdates == nil {
+		return false
+	}
+	connectionState, ok := s.connectionUpdates.FinalConnectionState[connectionName]
+	return ok && connectionState.Lazy()
+}
+
+type crossPluginAggregatorSchema struct {
+	comment   string
+	viewCount int
+}
+
+// getCrossPluginAggregatorSchemas returns the existing cross-plugin aggregator schemas,
+// keyed by schema name, identified by their schema comment
+func (s *refreshConnectionState) getCrossPluginAggregatorSchemas(ctx context.Context) (map[string]crossPluginAggregatorSchema, error) {
+	query := `SELECT n.nspname, obj_description(n.oid, 'pg_namespace'),
+  (SELECT count(*) FROM pg_class c WHERE c.relnamespace = n.oid AND c.relkind = 'v')
+FROM pg_namespace n
+WHERE obj_description(n.oid, 'pg_namespace') LIKE $1`
+	rows, err := s.pool.Query(ctx, query, crossPluginAggregatorSchemaComment+"%")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make(map[string]crossPluginAggregatorSchema)
+	for rows.Next() {
+		var name, comment string
+		var viewCount int
+		if err := rows.Scan(&name, &comment, &viewCount); err != nil {
+			return nil, err
+		}
+		res[name] = crossPluginAggregatorSchema{comment: comment, viewCount: viewCount}
+	}
+	return res, rows.Err()
+}
+
+// getSchemaColumns returns the columns of all tables in the given schemas
+func (s *refreshConnectionState) getSchemaColumns(ctx context.Context, schemas []string) (schemaColumnMap, error) {
+	query := `SELECT table_schema, table_name, column_name
+FROM information_schema.columns
+WHERE table_schema = ANY($1)
+ORDER BY table_schema, table_name, ordinal_position`
+	rows, err := s.pool.Query(ctx, query, schemas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make(schemaColumnMap)
+	for rows.Next() {
+		var schema, table, column string
+		if err := rows.Scan(&schema, &table, &column); err != nil {
+			return nil, err
+		}
+		if res[schema] == nil {
+			res[schema] = make(map[string][]string)
+		}
+		res[schema][table] = append(res[schema][table], column)
+	}
+	return res, rows.Err()
+}
+
+// executeCrossPluginAggregatorQuery recreates the aggregator schema and its views in a single transaction
+func (s *refreshConnectionState) executeCrossPluginAggregatorQuery(ctx context.Context, aggregatorName, comment string, viewSql []string) (err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return sperr.WrapWithMessage(err, "failed to create transaction to update cross-plugin aggregator")
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	schemaName := db_common.PgEscapeName(aggregatorName)
+	var statements strings.Builder
+	statements.WriteString(fmt.Sprintf("drop schema if exists %s cascade;\n", schemaName))
+	statements.WriteString(fmt.Sprintf("create schema %s;\n", schemaName))
+	statements.WriteString(fmt.Sprintf("comment on schema %s is %s;\n", schemaName, db_common.PgEscapeString(comment)))
+	statements.WriteString(fmt.Sprintf("grant usage on schema %s to steampipe_users;\n", schemaName))
+	for _, sql := range viewSql {
+		statements.WriteString(sql)
+	}
+	statements.WriteString(fmt.Sprintf("grant select on all tables in schema %s to steampipe_users;\n", schemaName))
+
+	_, err = tx.Exec(ctx, statements.String())
+	return err
+}
+
+// getCrossPluginAggregatorSchemaComment returns the schema comment for an aggregator with the given views
+// this includes a hash of the view definitions, so we can determine whether the views need recreating
+func getCrossPluginAggregatorSchemaComment(viewSql []string) string {
+	hash := md5.Sum([]byte(strings.Join(viewSql, "")))
+	return fmt.Sprintf("%s: %x", crossPluginAggregatorSchemaComment, hash)
+}
+
+// getCrossPluginAggregatorViewsSql returns the sql to create a view for each table of the aggregator
+// tables with no source tables in any child connection are skipped, with a warning
+func getCrossPluginAggregatorViewsSql(aggregator *modconfig.CrossPluginAggregator, columns schemaColumnMap) (viewSql []string, warnings []string) {
+	for _, table := range aggregator.Tables {
+		sql, err := getAggregatorTableViewSql(aggregator.Name, maps.Keys(aggregator.Connections), table, columns)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+			continue
+		}
+		viewSql = append(viewSql, sql)
+	}
+	return viewSql, warnings
+}
+
+// aggregatorTableSelect is a single source table of a child connection which provides rows for an aggregator table
+type aggregatorTableSelect struct {
+	connectionName string
+	source         *modconfig.AggregatorTableSource
+	// the source table columns
+	columns map[string]struct{}
+	// the aggregator column names provided by the source table, in source table column order
+	aggregatorColumns []string
+}
+
+// getAggregatorTableViewSql returns the sql to create a view for an aggregator table
+// the view is the union of the source tables of all child connections
+// if the table does not declare its columns, the columns common to all the source tables are used
+func getAggregatorTableViewSql(aggregatorName string, connectionNames []string, table *modconfig.AggregatorTable, columns schemaColumnMap) (string, error) {
+	sort.Strings(connectionNames)
+
+	var selects []*aggregatorTableSelect
+	for _, connectionName := range connectionNames {
+		for _, source := range table.Sources {
+			sourceColumns, ok := columns[connectionName][source.Table]
+			if !ok {
+				continue
+			}
+			selects = append(selects, newAggregatorTableSelect(connectionName, source, sourceColumns))
+		}
+	}
+	if len(selects) == 0 {
+		return "", sperr.New("table '%s' of cross-plugin aggregator '%s' has no source tables in its child connections", table.Name, aggregatorName)
+	}
+
+	aggregatorColumns := table.Columns
+	if len(aggregatorColumns) == 0 {
+		aggregatorColumns = getCommonAggregatorColumns(selects)
+	}
+	if len(aggregatorColumns) == 0 {
+		return "", sperr.New("the source tables of table '%s' of cross-plugin aggregator '%s' have no common columns", table.Name, aggregatorName)
+	}
+
+	selectStatements := make([]string, len(selects))
+	for i, sel := range selects {
+		var selectColumns []string
+		for _, column := range aggregatorColumns {
+			sourceColumn := sel.source.SourceColumn(column)
+			if _, ok := sel.columns[sourceColumn]; !ok {
+				// a declared column which this source table does not provide
+				selectColumns = append(selectColumns, fmt.Sprintf("NULL AS %s", db_common.PgEscapeName(column)))
+				continue
+			}
+			selectColumns = append(selectColumns, fmt.Sprintf("%s AS %s", db_common.PgEscapeName(sourceColumn), db_common.PgEscapeName(column)))
+		}
+		selectColumns = append(selectColumns, fmt.Sprintf("%s AS sp_connection_name", db_common.PgEscapeString(sel.connectionName)))
+		selectStatements[i] = fmt.Sprintf("SELECT %s FROM %s.%s",
+			strings.Join(selectColumns, ", "),
+			db_common.PgEscapeName(sel.connectionName),
+			db_common.PgEscapeName(sel.source.Table))
+	}
+
+	return fmt.Sprintf("create view %s.%s as\n%s;\n",
+		db_common.PgEscapeName(aggregatorName),
+		db_common.PgEscapeName(table.Name),
+		strings.Join(selectStatements, "\nUNION ALL\n")), nil
+}
+
+func newAggregatorTableSelect(connectionName string, source *modconfig.AggregatorTableSource, sourceColumns []string) *aggregatorTableSelect {
+	// build a reverse lookup of the column map, to get the aggregator name of each source column
+	aggregatorColumnLookup := make(map[string]string)
+	for aggregatorColumn, sourceColumn := range source.ColumnMap {
+		aggregatorColumnLookup[sourceColumn] = aggregatorColumn
+	}
+
+	res := &aggregatorTableSelect{
+		connectionName: connectionName,
+		source:         source,
+		columns:        make(map[string]struct{}),
+	}
+	for _, sourceColumn := range sourceColumns {
+		res.columns[sourceColumn] = struct{}{}
+		if _, excluded := crossPluginAggregatorExcludedColumns[sourceColumn]; excluded {
+			continue
+		}
+		aggregatorColumn, ok := aggregatorColumnLookup[sourceColumn]
+		if !ok {
+			aggregatorColumn = sourceColumn
+		}
+		res.aggregatorColumns = append(res.aggregatorColumns, aggregatorColumn)
+	}
+	return res
+}
+
+// getCommonAggregatorColumns returns the aggregator columns provided by all the selects,
+// in the column order of the first select
+func getCommonAggregatorColumns(selects []*aggregatorTableSelect) []string {
+	var res []string
+	for _, column := range selects[0].aggregatorColumns {
+		common := true
+		for _, sel := range selects[1:] {
+			if _, ok := sel.columns[sel.source.SourceColumn(column)]; !ok {
+				common = false
+				break
+			}
+		}
+		if common {
+			res = append(res, column)
+		}
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+package connection
+
+import (
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+var testAggregatorColumns = schemaColumnMap{
+	"aws_dev": {
+		"aws_ec2_instance": {"instance_id", "region", "instance_type", "sp_connection_name", "sp_ctx", "_ctx"},
+	},
+	"azure_dev": {
+		"azure_compute_virtual_machine": {"id", "region", "size", "sp_connection_name", "sp_ctx", "_ctx"},
+	},
+}
+
+var testAggregatorSources = []*modconfig.AggregatorTableSource{
+	{Table: "aws_ec2_instance", ColumnMap: map[string]string{"id": "instance_id"}},
+	{Table: "azure_compute_virtual_machine", ColumnMap: map[string]string{"instance_type": "size"}},
+}
+
+type aggregatorTableViewSqlTest struct {
+	connections []string
+	table       *modconfig.AggregatorTable
+	expected    string
+}
+
+var testCasesAggregatorTableViewSql = map[string]aggregatorTableViewSqlTest{
+	"common columns": {
+		connections: []string{"azure_dev", "aws_dev"},
+		table:       &modconfig.AggregatorTable{Name: "instance", Sources: testAggregatorSources},
+		expected: `create view "fleet"."instance" as
+SELECT "instance_id" AS "id", "region" AS "region", "instance_type" AS "instance_type", $steampipe_escape$aws_dev$steampipe_escape$ AS sp_connection_name FROM "aws_dev"."aws_ec2_instance"
+UNION ALL
+SELECT "id" AS "id", "region" AS "region", "size" AS "instance_type", $steampipe_escape$azure_dev$steampipe_escape$ AS sp_connection_name FROM "azure_dev"."azure_compute_virtual_machine";
+`,
+	},
+	"declared columns": {
+		connections: []string{"aws_dev", "azure_dev"},
+		table:       &modconfig.AggregatorTable{Name: "instance", Columns: []string{"id", "size"}, Sources: testAggregatorSources},
+		expected: `create view "fleet"."instance" as
+SELECT "instance_id" AS "id", NULL AS "size", $steampipe_escape$aws_dev$steampipe_escape$ AS sp_connection_name FROM "aws_dev"."aws_ec2_instance"
+UNION ALL
+SELECT "id" AS "id", "size" AS "size", $steampipe_escape$azure_dev$steampipe_escape$ AS sp_connection_name FROM "azure_dev"."azure_compute_virtual_machine";
+`,
+	},
+	"missing source table": {
+		connections: []string{"aws_dev", "gcp_dev"},
+		table:       &modconfig.AggregatorTable{Name: "instance", Sources: []*modconfig.AggregatorTableSource{{Table: "aws_ec2_instance"}}},
+		expected: `create view "fleet"."instance" as
+SELECT "instance_id" AS "instance_id", "region" AS "region", "instance_type" AS "instance_type", $steampipe_escape$aws_dev$steampipe_escape$ AS sp_connection_name FROM "aws_dev"."aws_ec2_instance";
+`,
+	},
+	"no source tables": {
+		connections: []string{"gcp_dev"},
+		table:       &modconfig.AggregatorTable{Name: "instance", Sources: testAggregatorSources},
+		expected:    "ERROR",
+	},
+}
+
+func TestAggregatorTableViewSql(t *testing.T) {
+	for name, test := range testCasesAggregatorTableViewSql {
+		sql, err := getAggregatorTableViewSql("fleet", test.connections, test.table, testAggregatorColumns)
+		if test.expected == "ERROR" {
+			if err == nil {
+				t.Errorf("Test: '%s' FAILED : expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test: '%s' FAILED : unexpected error: %s", name, err.Error())
+			continue
+		}
+		if sql != test.expected {
+			t.Errorf("Test: '%s' FAILED : expected:\n%s\ngot:\n%s", name, test.expected, sql)
+		}
+	}
+}
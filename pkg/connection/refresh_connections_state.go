@@ -5,15 +5,18 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 	"github.com/turbot/steampipe/pkg/constants"
@@ -40,6 +43,8 @@ type refreshConnectionState struct {
 	tableUpdater               *connectionStateTableUpdater
 	res                        *steampipeconfig.RefreshConnectionResult
 	forceUpdateConnectionNames []string
+	// lazy connections which have been referenced so must have their schema imported
+	lazyLoadConnectionNames []string
 	// properties for schema/comment cloning
 	exemplarSchemaMapMut sync.Mutex
 
@@ -67,6 +72,7 @@ func newRefreshConnectionState(ctx context.Context, pluginManager pluginManager,
 		pool:                       pool,
 		searchPath:                 searchPath,
 		forceUpdateConnectionNames: forceUpdateConnectionNames,
+		lazyLoadConnectionNames:    steampipeconfig.TakeLazyLoadRequests(),
 		pluginManager:              pluginManager,
 	}
 
@@ -99,6 +105,9 @@ func (s *refreshConnectionState) refreshConnections(ctx context.Context) {
 	if len(s.forceUpdateConnectionNames) > 0 {
 		opts = append(opts, steampipeconfig.WithForceUpdate(s.forceUpdateConnectionNames))
 	}
+	if len(s.lazyLoadConnectionNames) > 0 {
+		opts = append(opts, steampipeconfig.WithLazyLoad(s.lazyLoadConnectionNames))
+	}
 
 	// build a ConnectionUpdates struct
 	// this determines any necessary connection updates and starts any necessary plugins
@@ -160,6 +169,8 @@ func (s *refreshConnectionState) refreshConnections(ctx context.Context) {
 	// if there are no updates, just return
 	if !s.connectionUpdates.HasUpdates() {
 		log.Println("[INFO] no updates required")
+		// cross-plugin aggregator config may have changed even if no connections need updating
+		s.updateCrossPluginAggregators(ctx)
 		return
 	}
 
@@ -173,6 +184,9 @@ func (s *refreshConnectionState) refreshConnections(ctx context.Context) {
 	}
 
 	s.res.UpdatedConnections = true
+
+	// recreating connection schemas drops any cross-plugin aggregator views which reference them
+	s.updateCrossPluginAggregators(ctx)
 }
 
 func (s *refreshConnectionState) addMissingPluginWarnings() {
@@ -322,9 +336,11 @@ func (s *refreshConnectionState) executeUpdateQueries(ctx context.Context) {
 	log.Printf("[INFO] Execute %d remaining %s",
 		len(remainingUpdates),
 		utils.Pluralize("updates", len(remainingUpdates)))
-	// now execute remaining updates
-	moreErrors = s.executeUpdatesInParallel(ctx, remainingUpdates)
-	errors = append(errors, moreErrors...)
+	// now execute remaining updates - connections with a higher priority are updated first
+	for _, priorityUpdates := range updatesByPriority(remainingUpdates) {
+		moreErrors = s.executeUpdatesInParallel(ctx, priorityUpdates)
+		errors = append(errors, moreErrors...)
+	}
 
 	log.Printf("[INFO] Set comments for %d remaining %s and %d %s missing comments",
 		len(remainingUpdates),
@@ -464,22 +480,30 @@ func (s *refreshConnectionState) executeUpdateForConnections(ctx context.Context
 		connectionName := connectionState.ConnectionName
 		pluginSchemaName := utils.PluginFQNToSchemaName(connectionState.Plugin)
 		var sql string
+		// if the connection has a table filter, determine which tables to import
+		includedTables, excludedTables := s.getFilteredTables(connectionState)
 
 		s.exemplarSchemaMapMut.Lock()
 		// is this plugin in the exemplarSchemaMap
 		exemplarSchemaName, haveExemplarSchema := s.exemplarSchemaMap[connectionState.Plugin]
 		if haveExemplarSchema && cloneSchemaEnabled {
 			// we can clone!
-			sql = getCloneSchemaQuery(exemplarSchemaName, connectionState)
+			sql = getCloneSchemaQuery(exemplarSchemaName, connectionState, excludedTables)
 		} else {
 			// just get sql to execute update query, and update the connection state table, in a transaction
-			sql = db_common.GetUpdateConnectionQuery(connectionName, pluginSchemaName)
+			sql = db_common.GetUpdateConnectionQuery(connectionName, pluginSchemaName, includedTables)
 		}
 		s.exemplarSchemaMapMut.Unlock()
 
+		// apply any default quals to the imported tables
+		var defaultQualsQueries []string
+		if defaultQuals := s.getDefaultQuals(connectionState); len(defaultQuals) > 0 {
+			defaultQualsQueries = db_common.GetDefaultQualsQueries(connectionName, defaultQuals)
+		}
+
 		// the only error this will return is the failure to update the state table
 		// - all other errors are written to the state table
-		if err := s.executeUpdateQuery(ctx, sql, connectionName); err != nil {
+		if err := s.executeUpdateQuery(ctx, sql, defaultQualsQueries, connectionName); err != nil {
 			errChan <- &connectionError{connectionName, err}
 		} else {
 			// we can clone this plugin, add to exemplarSchemaMap
@@ -491,7 +515,7 @@ func (s *refreshConnectionState) executeUpdateForConnections(ctx context.Context
 	}
 }
 
-func (s *refreshConnectionState) executeUpdateQuery(ctx context.Context, sql, connectionName string) (err error) {
+func (s *refreshConnectionState) executeUpdateQuery(ctx context.Context, sql string, defaultQualsQueries []string, connectionName string) (err error) {
 	log.Println("[DEBUG] refreshConnectionState.executeUpdateQuery start")
 	defer log.Println("[DEBUG] refreshConnectionState.executeUpdateQuery end")
 
@@ -510,6 +534,9 @@ func (s *refreshConnectionState) executeUpdateQuery(ctx context.Context, sql, co
 
 	// execute update sql
 	_, err = tx.Exec(ctx, sql)
+	if err == nil {
+		err = executeDefaultQualsQueries(ctx, tx, defaultQualsQueries)
+	}
 	if err != nil {
 		// update failed connections in result
 		s.res.AddFailedConnection(connectionName, err.Error())
@@ -534,6 +561,17 @@ func (s *refreshConnectionState) executeUpdateQuery(ctx context.Context, sql, co
 	return nil
 }
 
+// executeDefaultQualsQueries executes each of the default quals queries on its own
+// the extended query protocol is used, so a query containing multiple statements is rejected
+func executeDefaultQualsQueries(ctx context.Context, tx pgx.Tx, queries []string) error {
+	for _, query := range queries {
+		if _, err := tx.Conn().PgConn().ExecParams(ctx, query, nil, nil, nil, nil).Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // set connection comments
 
 func (s *refreshConnectionState) UpdateCommentsInParallel(ctx context.Context, updates []*steampipeconfig.ConnectionState, plugins map[string]*steampipeconfig.ConnectionPlugin) (errors []error) {
@@ -601,8 +639,15 @@ func (s *refreshConnectionState) updateCommentsForConnection(ctx context.Context
 	}
 
 	schema := connectionPlugin.ConnectionMap[connectionName].Schema.Schema
+	// only comment the tables which pass the table filter of the connection
+	if connectionState.HasTableFilter() {
+		schema = maps.Clone(schema)
+		maps.DeleteFunc(schema, func(table string, _ *proto.TableSchema) bool {
+			return !connectionState.IncludesTable(table)
+		})
+	}
 	// just get sql to execute update query, and update the connection state table, in a transaction
-	sql = db_common.GetCommentsQueryForPlugin(connectionName, schema)
+	sql = db_common.GetCommentsQueryForPlugin(connectionName, schema, s.getDefaultQuals(connectionState))
 
 	// comment cloning disabled for now
 	//// if this schema is static, add to the exemplar map
@@ -672,8 +717,74 @@ func (s *refreshConnectionState) executeCommentQuery(ctx context.Context, sql, c
 	return nil
 }
 
-func getCloneSchemaQuery(exemplarSchemaName string, connectionState *steampipeconfig.ConnectionState) string {
-	return fmt.Sprintf("select clone_foreign_schema('%s', '%s', '%s');", exemplarSchemaName, connectionState.ConnectionName, connectionState.Plugin)
+// getCloneSchemaQuery returns the sql to clone the exemplar schema for the connection,
+// then drop any tables which are excluded by the table filter of the connection
+func getCloneSchemaQuery(exemplarSchemaName string, connectionState *steampipeconfig.ConnectionState, excludedTables []string) string {
+	sql := fmt.Sprintf("select clone_foreign_schema('%s', '%s', '%s');", exemplarSchemaName, connectionState.ConnectionName, connectionState.Plugin)
+	if len(excludedTables) > 0 {
+		sql += "\n" + db_common.GetDropForeignTablesQuery(connectionState.ConnectionName, excludedTables)
+	}
+	return sql
+}
+
+// getFilteredTables applies the table filter of the connection to the tables in the plugin schema
+// and returns the included and excluded tables (sorted)
+// if the connection has no table filter, or the schema is not available, return nil for both
+func (s *refreshConnectionState) getFilteredTables(connectionState *steampipeconfig.ConnectionState) (included, excluded []string) {
+	if !connectionState.HasTableFilter() {
+		return nil, nil
+	}
+	schema := s.getConnectionSchema(connectionState.ConnectionName)
+	if schema == nil {
+		log.Printf("[WARN] no schema loaded for connection '%s' - table filter will not be applied", connectionState.ConnectionName)
+		return nil, nil
+	}
+	// NOTE: initialise included to an empty slice as nil means 'import all tables'
+	included = []string{}
+	for _, table := range utils.SortedMapKeys(schema.Schema) {
+		if connectionState.IncludesTable(table) {
+			included = append(included, table)
+		} else {
+			excluded = append(excluded, table)
+		}
+	}
+	return included, excluded
+}
+
+// getDefaultQuals returns the default quals of the connection for the tables which are imported into the connection schema
+// (if the schema is not available, all default quals are returned)
+func (s *refreshConnectionState) getDefaultQuals(connectionState *steampipeconfig.ConnectionState) map[string]string {
+	if len(connectionState.DefaultQuals) == 0 {
+		return nil
+	}
+	schema := s.getConnectionSchema(connectionState.ConnectionName)
+	res := make(map[string]string)
+	for table, qual := range connectionState.DefaultQuals {
+		if !connectionState.IncludesTable(table) {
+			continue
+		}
+		if schema != nil {
+			if _, ok := schema.Schema[table]; !ok {
+				log.Printf("[WARN] connection '%s' has a default qual for table '%s' which is not in the plugin schema", connectionState.ConnectionName, table)
+				continue
+			}
+		}
+		res[table] = qual
+	}
+	return res
+}
+
+// getConnectionSchema returns the plugin schema of the connection, if the connection plugin is loaded
+func (s *refreshConnectionState) getConnectionSchema(connectionName string) *proto.Schema {
+	connectionPlugin, ok := s.connectionUpdates.ConnectionPlugins[connectionName]
+	if !ok {
+		return nil
+	}
+	connectionData, ok := connectionPlugin.ConnectionMap[connectionName]
+	if !ok || connectionData.Schema == nil {
+		return nil
+	}
+	return connectionData.Schema
 }
 
 func (s *refreshConnectionState) getInitialAndRemainingUpdates() (initialUpdates, remainingUpdates map[string]*steampipeconfig.ConnectionState, dynamicUpdates map[string][]*steampipeconfig.ConnectionState) {
@@ -708,6 +819,26 @@ func (s *refreshConnectionState) getInitialAndRemainingUpdates() (initialUpdates
 	return initialUpdates, remainingUpdates, dynamicUpdates
 }
 
+// updatesByPriority splits the updates into sets of connections with the same priority, in descending order of priority
+func updatesByPriority(updates map[string]*steampipeconfig.ConnectionState) []map[string]*steampipeconfig.ConnectionState {
+	priorityMap := make(map[int]map[string]*steampipeconfig.ConnectionState)
+	for connectionName, connectionState := range updates {
+		if priorityMap[connectionState.Priority] == nil {
+			priorityMap[connectionState.Priority] = make(map[string]*steampipeconfig.ConnectionState)
+		}
+		priorityMap[connectionState.Priority][connectionName] = connectionState
+	}
+
+	priorities := maps.Keys(priorityMap)
+	sort.Sort(sort.Reverse(sort.IntSlice(priorities)))
+
+	res := make([]map[string]*steampipeconfig.ConnectionState, len(priorities))
+	for i, priority := range priorities {
+		res[i] = priorityMap[priority]
+	}
+	return res
+}
+
 func (s *refreshConnectionState) executeDeleteQueries(ctx context.Context, deletions []string) error {
 	t := time.Now()
 	log.Printf("[INFO] execute %d delete %s", len(deletions), utils.Pluralize("query", len(deletions)))
@@ -0,0 +1,46 @@
+package connection
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/steampipeconfig"
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+type updatesByPriorityTest struct {
+	priorities map[string]int
+	expected   [][]string
+}
+
+var testCasesUpdatesByPriority = map[string]updatesByPriorityTest{
+	"no updates": {
+		priorities: map[string]int{},
+		expected:   [][]string{},
+	},
+	"default priority": {
+		priorities: map[string]int{"aws_dev": 0, "aws_prod": 0},
+		expected:   [][]string{{"aws_dev", "aws_prod"}},
+	},
+	"descending priority": {
+		priorities: map[string]int{"aws_dev": 0, "aws_prod": 10, "aws_archive": -10, "gcp_prod": 10},
+		expected:   [][]string{{"aws_prod", "gcp_prod"}, {"aws_dev"}, {"aws_archive"}},
+	},
+}
+
+func TestUpdatesByPriority(t *testing.T) {
+	for name, test := range testCasesUpdatesByPriority {
+		updates := make(map[string]*steampipeconfig.ConnectionState)
+		for connectionName, priority := range test.priorities {
+			updates[connectionName] = &steampipeconfig.ConnectionState{ConnectionName: connectionName, Priority: priority}
+		}
+
+		res := [][]string{}
+		for _, priorityUpdates := range updatesByPriority(updates) {
+			res = append(res, utils.SortedMapKeys(priorityUpdates))
+		}
+		if !reflect.DeepEqual(res, test.expected) {
+			t.Errorf("Test: '%s' FAILED : expected %v, got %v", name, test.expected, res)
+		}
+	}
+}
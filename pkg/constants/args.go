@@ -10,9 +10,15 @@ const (
 	ArgOn                      = "on"
 	ArgOff                     = "off"
 	ArgClear                   = "clear"
+	ArgStats                   = "stats"
 	ArgDatabaseListenAddresses = "database-listen"
 	ArgDatabasePort            = "database-port"
 	ArgDatabaseQueryTimeout    = "query-timeout"
+	ArgDatabaseHealthListen    = "database-health-listen"
+	ArgDatabaseHealthPort      = "database-health-port"
+	ArgDatabaseAuditLog        = "database-audit-log"
+	ArgAudit                   = "audit"
+	ArgTail                    = "tail"
 	ArgServicePassword         = "database-password"
 	ArgServiceShowPassword     = "show-password"
 	ArgDashboard               = "dashboard"
@@ -45,6 +51,12 @@ const (
 	ArgConnectionString        = "connection-string"
 	ArgDisplayWidth            = "display-width"
 	ArgPrune                   = "prune"
+	ArgFrom                    = "from"
+	ArgLocked                  = "locked"
+	ArgConstraint              = "constraint"
+	ArgBump                    = "bump"
+	ArgPrerelease              = "prerelease"
+	ArgMessage                 = "message"
 	ArgModInstall              = "mod-install"
 	ArgServiceMode             = "service-mode"
 	ArgBrowser                 = "browser"
@@ -66,6 +78,10 @@ const (
 	ArgDatabaseStartTimeout    = "database-start-timeout"
 	ArgMemoryMaxMb             = "memory-max-mb"
 	ArgMemoryMaxMbPlugin       = "memory-max-mb-plugin"
+	ArgPluginSignaturePolicy   = "plugin-signature-policy"
+	ArgPluginSignatureKey      = "plugin-signature-public-key"
+	ArgPlugins                 = "plugins"
+	ArgIgnoreConfigErrors      = "ignore-config-errors"
 )
 
 // metaquery mode arguments
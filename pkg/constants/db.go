@@ -25,6 +25,12 @@ const (
 	DefaultMaxConnections            = 10
 )
 
+// listen types for the service health server
+const (
+	HealthListenLocal   = "local"
+	HealthListenNetwork = "network"
+)
+
 // constants for installing db and fdw images
 const (
 	DatabaseVersion = "14.2.0"
@@ -68,6 +74,8 @@ const (
 	ConnectionStateDeleting          = "deleting"
 	ConnectionStateDisabled          = "disabled"
 	ConnectionStateError             = "error"
+	// ConnectionStateLazy is the state of a connection with lazy schema import whose schema has not been imported yet
+	ConnectionStateLazy = "lazy"
 
 	// foreign tables in internal schema
 	ForeignTableScanMetadata              = "steampipe_scan_metadata"
@@ -81,6 +89,7 @@ const (
 	FunctionCacheSet             = "meta_cache"
 	FunctionConnectionCacheClear = "meta_connection_cache_clear"
 	FunctionCacheSetTtl          = "meta_cache_ttl"
+	FunctionCacheStats           = "meta_cache_stats"
 
 	// legacy
 	LegacyCommandSchema = "steampipe_command"
@@ -102,6 +111,7 @@ var ConnectionStates = []string{
 	ConnectionStateUpdating,
 	ConnectionStateDeleting,
 	ConnectionStateError,
+	ConnectionStateLazy,
 }
 
 var ReservedConnectionNames = []string{
@@ -110,6 +120,12 @@ var ReservedConnectionNames = []string{
 
 const ReservedConnectionNamePrefix = "steampipe_"
 
+// UnrestrictedTablePrefix is the prefix of the foreign tables which are replaced by views when default quals are applied
+const UnrestrictedTablePrefix = ReservedConnectionNamePrefix + "unrestricted_"
+
+// MaxIdentifierLength is the maximum length of a postgres identifier
+const MaxIdentifierLength = 63
+
 // introspection table names
 const (
 	IntrospectionTableQuery              = "steampipe_query"
@@ -147,6 +163,8 @@ const (
 	InvokerCheck = "check"
 	// InvokerPlugin is set when invoked by a plugin command
 	InvokerPlugin = "plugin"
+	// InvokerConnection is set when invoked by a connection command
+	InvokerConnection = "connection"
 	// InvokerDashboard is set when invoked by dashboard command
 	InvokerDashboard = "dashboard"
 	// InvokerConnectionWatcher is set when invoked by the connection watcher process
@@ -156,8 +174,8 @@ const (
 // IsValid is a validator for Invoker known values
 func (i Invoker) IsValid() error {
 	switch i {
-	case InvokerService, InvokerQuery, InvokerCheck, InvokerPlugin, InvokerDashboard:
+	case InvokerService, InvokerQuery, InvokerCheck, InvokerPlugin, InvokerConnection, InvokerDashboard:
 		return nil
 	}
-	return fmt.Errorf("invalid invoker. Can be one of '%v', '%v', '%v', '%v' or '%v' ", InvokerService, InvokerQuery, InvokerPlugin, InvokerCheck, InvokerDashboard)
+	return fmt.Errorf("invalid invoker. Can be one of '%v', '%v', '%v', '%v', '%v' or '%v' ", InvokerService, InvokerQuery, InvokerPlugin, InvokerCheck, InvokerConnection, InvokerDashboard)
 }
@@ -17,6 +17,9 @@ const DefaultConnectionConfigContent = `
 #   cache              = true                  # true, false
 #   cache_max_ttl      = 900                   # max expiration (TTL) in seconds
 #   cache_max_size_mb  = 1024                  # max total size of cache across all plugins
+#   health_port        = 9195                  # port for the /healthz and /readyz endpoints (disabled if not set)
+#   health_listen      = "local"               # local, network
+#   audit_log          = false                 # true, false - write an audit record of every connection and query to the logs directory
 # }
 
 # options "dashboard" {
@@ -34,4 +37,12 @@ const DefaultConnectionConfigContent = `
 # options "plugin" {
 #   memory_max_mb    = "1024"	# the default maximum memory to allow a plugin process - used if there is not max memory specified in the 'plugin' block' for that plugin
 # }
+
+# git_host "github.example.com" {                  # host of private mod dependencies, e.g. github.example.com/org/mod
+#   url               = "ssh://git@github.example.com" # base url used in place of https://github.example.com (ssh or https)
+#   username          = "git"                          # username to authenticate with
+#   token_env         = "GHE_TOKEN"                    # environment variable containing an access token (https)
+#   credential_helper = false                          # use 'git credential fill' to get credentials (https)
+#   ssh_key           = "~/.ssh/id_ed25519"            # ssh private key - the ssh agent is used if not set (ssh)
+# }
 `
@@ -9,6 +9,9 @@ const (
 	EnvMaxParallel     = "STEAMPIPE_MAX_PARALLEL"
 
 	EnvDatabaseStartTimeout  = "STEAMPIPE_DATABASE_START_TIMEOUT"
+	EnvDatabaseHealthListen  = "STEAMPIPE_DATABASE_HEALTH_LISTEN"
+	EnvDatabaseHealthPort    = "STEAMPIPE_DATABASE_HEALTH_PORT"
+	EnvDatabaseAuditLog      = "STEAMPIPE_DATABASE_AUDIT_LOG"
 	EnvDashboardStartTimeout = "STEAMPIPE_DASHBOARD_START_TIMEOUT"
 
 	EnvSnapshotLocation  = "STEAMPIPE_SNAPSHOT_LOCATION"
@@ -42,4 +45,12 @@ const (
 
 	EnvMemoryMaxMb       = "STEAMPIPE_MEMORY_MAX_MB"
 	EnvMemoryMaxMbPlugin = "STEAMPIPE_PLUGIN_MEMORY_MAX_MB"
+
+	EnvPluginSignaturePolicy = "STEAMPIPE_PLUGIN_SIGNATURE_POLICY"
+	EnvPluginSignatureKey    = "STEAMPIPE_PLUGIN_SIGNATURE_PUBLIC_KEY"
+
+	// secret lookup for connection config
+	EnvSecretsFile   = "STEAMPIPE_SECRETS_FILE"
+	EnvSecretsKey    = "STEAMPIPE_SECRETS_KEY"
+	EnvSecretsHelper = "STEAMPIPE_SECRETS_HELPER"
 )
@@ -17,6 +17,11 @@ const (
 	ExitCodeLoginCloudConnectionFailed  = 51  // login - connecting to cloud failed
 	ExitCodeModInitFailed               = 61  // mod - init failed
 	ExitCodeModInstallFailed            = 62  // mod - install failed
+	ExitCodeModVerifyFailed             = 63  // mod - 1 or more dependencies failed verification
+	ExitCodeModReleaseFailed            = 64  // mod - release failed
+	ExitCodeConnectionNotFound          = 71  // connection - not found
+	ExitCodeConnectionTestFailed        = 72  // connection - test failed
+	ExitCodeInvalidConfig               = 81  // config - validation found 1 or more errors
 	ExitCodeInvalidExecutionEnvironment = 249 // common - when steampipe is run in an unsupported environment
 	ExitCodeInitializationFailed        = 250 // common - initialization failed
 	ExitCodeBindPortUnavailable         = 251 // common(service/dashboard) - port binding failed
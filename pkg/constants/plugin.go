@@ -5,8 +5,20 @@ const (
 	InstallMessagePluginLatestAlreadyInstalled = "Latest already installed"
 	InstallMessagePluginNotInstalled           = "Not installed"
 	InstallMessagePluginNotFound               = "Not found"
+	InstallMessagePluginConstraintPrevents     = "Update prevented by lock constraint"
+	InstallMessagePluginNotLocked              = "Not in plugin lock file"
 	ConnectionErrorPluginFailedToStart         = "plugin failed to start"
 	ConnectionErrorPluginNotInstalled          = "plugin not installed"
 
 	SteampipeHubOCIBase = "hub.steampipe.io/"
 )
+
+// plugin image signature verification policies
+const (
+	// do not verify plugin image signatures
+	PluginSignaturePolicyOff = "off"
+	// verify plugin image signatures and warn if a signature cannot be verified
+	PluginSignaturePolicyWarn = "warn"
+	// fail the installation of any plugin image whose signature cannot be verified
+	PluginSignaturePolicyEnforce = "enforce"
+)
@@ -84,6 +84,15 @@ func (c *DbClient) startQueryWithRetries(ctx context.Context, session *db_common
 
 			// we need the first search path connection for each plugin to be loaded
 			searchPath := c.GetRequiredSessionSearchPath()
+
+			// if the search path includes lazy connections whose schema has not been imported, import them now
+			if lazyConnections := connectionStateMap.LazyConnections(searchPath...); len(lazyConnections) > 0 && c.isLocalService {
+				if err := c.loadLazyConnections(ctx, sysConn.Conn(), lazyConnections); err != nil {
+					return err
+				}
+				return retry.RetryableError(queryError)
+			}
+
 			requiredConnections := connectionStateMap.GetFirstSearchPathConnectionForPlugins(searchPath)
 			// if required connections are ready (and have been for more than the backoff interval) , just return the relation not found error
 			if connectionStateMap.Loaded(requiredConnections...) && time.Since(connectionStateMap.ConnectionModTime()) > backoffInterval {
@@ -114,6 +123,19 @@ func (c *DbClient) startQueryWithRetries(ctx context.Context, session *db_common
 			return queryError
 		}
 
+		// if the connection has lazy schema import and its schema has not been imported, import it now
+		if connectionState.Lazy() {
+			log.Println("[TRACE] schema", missingSchema, "is lazy")
+			// the schema import is performed by the plugin manager, so is only possible for a local service
+			if !c.isLocalService {
+				return queryError
+			}
+			if err := c.loadLazyConnections(ctx, sysConn.Conn(), []string{missingSchema}); err != nil {
+				return err
+			}
+			return retry.RetryableError(queryError)
+		}
+
 		// if the connection is ready (and has been for more than the backoff interval) , just return the relation not found error
 		if connectionState.State == constants.ConnectionStateReady && time.Since(connectionState.ConnectionModTime) > backoffInterval {
 			log.Println("[TRACE] schema", missingSchema, "has been ready for a long time")
@@ -0,0 +1,54 @@
+package db_client
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/turbot/steampipe/pkg/pluginmanager"
+	"github.com/turbot/steampipe/pkg/steampipeconfig"
+)
+
+// if the search path includes any connections with lazy schema import whose schema has not been imported,
+// import them now and wait for the import to complete
+// this ensures unqualified queries are resolved using the schemas in the search path
+func (c *DbClient) loadLazySearchPathConnections(ctx context.Context, searchPath []string) error {
+	// the schema import is performed by the plugin manager, so is only possible for a local service
+	if !c.isLocalService {
+		return nil
+	}
+
+	conn, err := c.managementPool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	connectionStateMap, err := steampipeconfig.LoadConnectionState(ctx, conn.Conn())
+	if err != nil {
+		return err
+	}
+
+	lazyConnections := connectionStateMap.LazyConnections(searchPath...)
+	if len(lazyConnections) == 0 {
+		return nil
+	}
+	return c.loadLazyConnections(ctx, conn.Conn(), lazyConnections)
+}
+
+// ask the plugin manager to import the schemas of the given connections, which have lazy schema import,
+// and wait for the import to complete
+func (c *DbClient) loadLazyConnections(ctx context.Context, conn *pgx.Conn, connectionNames []string) error {
+	log.Printf("[INFO] importing schema for lazy connections: %s", strings.Join(connectionNames, ","))
+
+	// the request is read by the plugin manager when it next refreshes connections
+	if err := steampipeconfig.SaveLazyLoadRequest(connectionNames); err != nil {
+		return err
+	}
+	if err := pluginmanager.RefreshConnectionsIfRunning(); err != nil {
+		return err
+	}
+	_, err := steampipeconfig.LoadConnectionState(ctx, conn, steampipeconfig.WithWaitForLazyLoad(connectionNames...))
+	return err
+}
@@ -106,6 +106,13 @@ func (c *DbClient) ensureSessionSearchPath(ctx context.Context, session *db_comm
 		return nil
 	}
 
+	// a custom search path is a reference to its connections - import the schema of any lazy connections it includes
+	if c.customSearchPath != nil {
+		if err := c.loadLazySearchPathConnections(ctx, requiredSearchPath); err != nil {
+			log.Printf("[WARN] failed to import schemas of lazy connections in search path: %s", err.Error())
+		}
+	}
+
 	// so we need to set the search path
 	log.Printf("[TRACE] session search path will be updated to  %s", strings.Join(c.customSearchPath, ","))
 
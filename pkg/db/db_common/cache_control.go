@@ -22,6 +22,45 @@ func CacheClear(ctx context.Context, connection *pgx.Conn) error {
 	return executeCacheSetFunction(ctx, "clear", connection)
 }
 
+// ConnectionCacheClear clears the cache for a single connection
+func ConnectionCacheClear(ctx context.Context, connectionName string, connection *pgx.Conn) error {
+	return ExecuteSystemClientCall(ctx, connection, func(ctx context.Context, tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, fmt.Sprintf(
+			"select %s.%s($1)",
+			constants.InternalSchema,
+			constants.FunctionConnectionCacheClear,
+		), connectionName)
+		return err
+	})
+}
+
+// ConnectionCacheStats is the cache usage of a single connection, as returned by the cache stats function
+type ConnectionCacheStats struct {
+	Connection  string  `db:"connection"`
+	Scans       int64   `db:"scans"`
+	CacheHits   int64   `db:"cache_hits"`
+	RowsFetched float64 `db:"rows_fetched"`
+	HitRate     float64 `db:"hit_rate"`
+}
+
+// CacheStats returns the per connection cache usage for the current session
+func CacheStats(ctx context.Context, connection *pgx.Conn) ([]ConnectionCacheStats, error) {
+	var stats []ConnectionCacheStats
+	err := ExecuteSystemClientCall(ctx, connection, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, fmt.Sprintf(
+			"select * from %s.%s()",
+			constants.InternalSchema,
+			constants.FunctionCacheStats,
+		))
+		if err != nil {
+			return err
+		}
+		stats, err = pgx.CollectRows(rows, pgx.RowToStructByName[ConnectionCacheStats])
+		return err
+	})
+	return stats, err
+}
+
 // SetCacheEnabled enables/disables the cache
 func SetCacheEnabled(ctx context.Context, enabled bool, connection *pgx.Conn) error {
 	value := "off"
@@ -58,6 +58,30 @@ end;
 begin
 	INSERT INTO steampipe_internal.steampipe_settings("name","value") VALUES ('cache_ttl',duration);
 end;
+`,
+	},
+	{
+		// the query cache itself lives in the plugin processes, so entry counts and sizes are not visible here
+		// - the stats are derived from the scan metadata recorded by the FDW for the current session
+		Name:     constants.FunctionCacheStats,
+		Params:   map[string]string{},
+		Returns:  "table(connection text, scans bigint, cache_hits bigint, rows_fetched numeric, hit_rate numeric)",
+		Language: "plpgsql",
+		Body: `
+begin
+	RETURN QUERY SELECT
+		m.connection::text,
+		count(*),
+		count(*) FILTER (WHERE m.cache_hit),
+		coalesce(sum(m.rows_fetched), 0)::numeric,
+		round(100.0 * count(*) FILTER (WHERE m.cache_hit) / count(*), 1)
+	FROM
+		steampipe_internal.steampipe_scan_metadata m
+	GROUP BY
+		m.connection
+	ORDER BY
+		m.connection;
+end;
 `,
 	},
 }
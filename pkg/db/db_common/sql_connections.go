@@ -2,18 +2,27 @@ package db_common
 
 import (
 	"fmt"
-	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/utils"
 )
 
-func GetCommentsQueryForPlugin(connectionName string, p map[string]*proto.TableSchema) string {
+// GetCommentsQueryForPlugin returns the sql to set the table and column comments of the connection schema
+// tables with default quals are views, rather than foreign tables (see GetDefaultQualsQueries)
+func GetCommentsQueryForPlugin(connectionName string, p map[string]*proto.TableSchema, defaultQuals map[string]string) string {
 	var statements strings.Builder
 	for t, schema := range p {
 		table := PgEscapeName(t)
 		schemaName := PgEscapeName(connectionName)
 		if schema.Description != "" {
 			tableDescription := PgEscapeString(schema.Description)
-			statements.WriteString(fmt.Sprintf("COMMENT ON FOREIGN TABLE %s.%s is %s;\n", schemaName, table, tableDescription))
+			objectType := "FOREIGN TABLE"
+			if _, isView := defaultQuals[t]; isView {
+				objectType = "VIEW"
+			}
+			statements.WriteString(fmt.Sprintf("COMMENT ON %s %s.%s is %s;\n", objectType, schemaName, table, tableDescription))
 		}
 		for _, c := range schema.Columns {
 			if c.Description != "" {
@@ -26,7 +35,9 @@ func GetCommentsQueryForPlugin(connectionName string, p map[string]*proto.TableS
 	return statements.String()
 }
 
-func GetUpdateConnectionQuery(connectionName, pluginSchemaName string) string {
+// GetUpdateConnectionQuery returns the sql to recreate the connection schema and import the foreign schema of the plugin
+// if tables is non-nil, only these tables are imported
+func GetUpdateConnectionQuery(connectionName, pluginSchemaName string, tables []string) string {
 	// escape the name
 	connectionName = PgEscapeName(connectionName)
 
@@ -53,11 +64,57 @@ func GetUpdateConnectionQuery(connectionName, pluginSchemaName string) string {
 	statements.WriteString(fmt.Sprintf("grant select on all tables in schema %s to steampipe_users;\n", connectionName))
 
 	// Import the foreign schema into this connection.
-	statements.WriteString(fmt.Sprintf("import foreign schema \"%s\" from server steampipe into %s;\n", pluginSchemaName, connectionName))
+	switch {
+	case tables == nil:
+		statements.WriteString(fmt.Sprintf("import foreign schema \"%s\" from server steampipe into %s;\n", pluginSchemaName, connectionName))
+	case len(tables) > 0:
+		escapedTables := make([]string, len(tables))
+		for i, t := range tables {
+			escapedTables[i] = PgEscapeName(t)
+		}
+		statements.WriteString(fmt.Sprintf("import foreign schema \"%s\" limit to (%s) from server steampipe into %s;\n", pluginSchemaName, strings.Join(escapedTables, ", "), connectionName))
+	}
+
+	return statements.String()
+}
 
+// GetDropForeignTablesQuery returns the sql to drop the given foreign tables from the connection schema
+func GetDropForeignTablesQuery(connectionName string, tables []string) string {
+	var statements strings.Builder
+	for _, t := range tables {
+		statements.WriteString(fmt.Sprintf("drop foreign table if exists %s.%s;\n", PgEscapeName(connectionName), PgEscapeName(t)))
+	}
 	return statements.String()
 }
 
 func GetDeleteConnectionQuery(name string) string {
 	return fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE;\n", PgEscapeName(name))
 }
+
+// GetDefaultQualsQueries returns the queries to apply default quals to tables of the connection schema
+//
+// for each table, the foreign table is renamed and replaced by a view which selects from it, filtered by the default qual
+// - postgres passes the view conditions to the foreign table scan, so these are received by the plugin as quals
+// steampipe users are not granted access to the renamed foreign table, so the default quals cannot be bypassed
+//
+// each query is a single statement, and must be executed on its own
+// the default qual is validated against the foreign table with an explain before the view is created
+func GetDefaultQualsQueries(connectionName string, defaultQuals map[string]string) []string {
+	schemaName := PgEscapeName(connectionName)
+
+	var queries []string
+	for _, table := range utils.SortedMapKeys(defaultQuals) {
+		unrestrictedTable := PgEscapeName(constants.UnrestrictedTablePrefix + table)
+		view := fmt.Sprintf("%s.%s", schemaName, PgEscapeName(table))
+		foreignTable := fmt.Sprintf("%s.%s", schemaName, unrestrictedTable)
+
+		queries = append(queries,
+			fmt.Sprintf("alter foreign table %s rename to %s", view, unrestrictedTable),
+			fmt.Sprintf("revoke all on %s from %s", foreignTable, constants.DatabaseUsersRole),
+			fmt.Sprintf("explain select * from %s where (%s)", foreignTable, defaultQuals[table]),
+			// use a security barrier view so functions in user queries cannot see rows which fail the default qual
+			fmt.Sprintf("create view %s with (security_barrier) as select * from %s where (%s)", view, foreignTable, defaultQuals[table]),
+		)
+	}
+	return queries
+}
@@ -0,0 +1,24 @@
+package db_local
+
+import (
+	"github.com/spf13/viper"
+	"github.com/turbot/steampipe/pkg/constants"
+)
+
+// auditLogArgs returns the postgres arguments needed to capture the audit log, if it is enabled
+//
+// every statement and connection is logged to a csvlog in place of the database log
+// (so statements are not written twice). This is converted into the audit log by the plugin manager (see auditlog.Collector)
+//
+// statements are logged on completion using log_min_duration_statement=0 rather than log_statement=all,
+// so that each statement is a single log entry which includes its duration
+func auditLogArgs() []string {
+	if !viper.GetBool(constants.ArgDatabaseAuditLog) {
+		return nil
+	}
+	return []string{
+		"-c", "log_destination=csvlog",
+		"-c", "log_min_duration_statement=0",
+		"-c", "log_connections=on",
+	}
+}
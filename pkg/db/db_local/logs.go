@@ -25,7 +25,8 @@ func TrimLogs() {
 		}
 
 		fileName := fi.Name()
-		if filepath.Ext(fileName) != ".log" {
+		// .csv files are written by the database when the audit log is enabled
+		if ext := filepath.Ext(fileName); ext != ".log" && ext != ".csv" {
 			continue
 		}
 
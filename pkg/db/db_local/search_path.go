@@ -79,13 +79,16 @@ func SetUserSearchPath(ctx context.Context, pool *pgxpool.Pool) ([]string, error
 
 // GetDefaultSearchPath builds default search path from the connection schemas, book-ended with public and internal
 func getDefaultSearchPath() []string {
-	// add all connections to the seatrch path (UNLESS ImportSchema is disabled)
+	// add all connections to the seatrch path (UNLESS ImportSchema is disabled or lazy)
 	var searchPath []string
 	for connectionName, connection := range steampipeconfig.GlobalConfig.Connections {
 		if connection.ImportSchema == modconfig.ImportSchemaEnabled {
 			searchPath = append(searchPath, connectionName)
 		}
 	}
+	for aggregatorName := range steampipeconfig.GlobalConfig.CrossPluginAggregators {
+		searchPath = append(searchPath, aggregatorName)
+	}
 
 	sort.Strings(searchPath)
 	// add the 'public' schema as the first schema in the search_path. This makes it
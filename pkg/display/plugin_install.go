@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/error_helpers"
 	"github.com/turbot/steampipe/pkg/ociinstaller"
 	"github.com/turbot/steampipe/pkg/utils"
 )
@@ -18,12 +19,14 @@ func (i PluginInstallReports) Swap(lIdx, rIdx int)      { i[lIdx], i[rIdx] = i[r
 func (i PluginInstallReports) Less(lIdx, rIdx int) bool { return i[lIdx].Plugin < i[rIdx].Plugin }
 
 type PluginInstallReport struct {
-	Skipped        bool
-	Plugin         string
-	SkipReason     string
-	DocURL         string
-	Version        string
-	IsUpdateReport bool
+	Skipped    bool
+	Plugin     string
+	SkipReason string
+	DocURL     string
+	Version    string
+	// the result of verifying the image signature - empty if the signature was not verified
+	SignatureStatus string
+	IsUpdateReport  bool
 }
 
 func (i *PluginInstallReport) skipString() string {
@@ -46,6 +49,12 @@ func (i *PluginInstallReport) installString() string {
 				fmt.Sprintf("Documentation:  %s", i.DocURL),
 			)
 		}
+		if len(i.SignatureStatus) > 0 {
+			thisReport = append(
+				thisReport,
+				fmt.Sprintf("Signature:      %s", i.SignatureStatus),
+			)
+		}
 	} else {
 		thisReport = append(
 			thisReport,
@@ -57,11 +66,26 @@ func (i *PluginInstallReport) installString() string {
 				fmt.Sprintf("Documentation:    %s", i.DocURL),
 			)
 		}
+		if len(i.SignatureStatus) > 0 {
+			thisReport = append(
+				thisReport,
+				fmt.Sprintf("Signature:        %s", i.SignatureStatus),
+			)
+		}
 	}
 
 	return strings.Join(thisReport, "\n")
 }
 
+// signatureWarning returns a warning if the signature of the installed image was checked and could not be verified
+// (this is only possible if the signature policy is 'warn' - with 'enforce' the install fails)
+func (i *PluginInstallReport) signatureWarning() string {
+	if i.Skipped || i.SignatureStatus == "" || i.SignatureStatus == string(ociinstaller.SignatureStatusVerified) {
+		return ""
+	}
+	return fmt.Sprintf("the image signature of plugin %s could not be verified (%s)", i.Plugin, i.SignatureStatus)
+}
+
 func (i *PluginInstallReport) String() string {
 	if !i.Skipped {
 		return i.installString()
@@ -105,6 +129,20 @@ func PrintInstallReports(reports PluginInstallReports, isUpdateReport bool) {
 			asString = append(asString, report.installString())
 		}
 		fmt.Println(strings.Join(asString, "\n\n"))
+
+		// warn about any plugins whose signature could not be verified
+		var signatureWarnings []string
+		for _, report := range installedOrUpdated {
+			if warning := report.signatureWarning(); warning != "" {
+				signatureWarnings = append(signatureWarnings, warning)
+			}
+		}
+		if len(signatureWarnings) > 0 {
+			fmt.Println()
+			for _, warning := range signatureWarnings {
+				error_helpers.ShowWarning(warning)
+			}
+		}
 	}
 
 	if len(installedOrUpdated) < len(reports) {
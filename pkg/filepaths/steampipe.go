@@ -16,13 +16,16 @@ const (
 
 	connectionsStateFileName     = "connection.json"
 	versionFileName              = "versions.json"
+	pluginLockFileName           = "plugins.lock.json"
 	databaseRunningInfoFileName  = "steampipe.json"
 	pluginManagerStateFileName   = "plugin_manager.json"
 	dashboardServerStateFileName = "dashboard_service.json"
+	auditLogStateFileName        = "audit_log.json"
 	stateFileName                = "update_check.json"
 	legacyStateFileName          = "update-check.json"
 	availableVersionsFileName    = "available_versions.json"
 	legacyNotificationsFileName  = "notifications.json"
+	secretsFileName              = "secrets.enc"
 )
 
 var SteampipeDir string
@@ -65,6 +68,18 @@ func EnsureInternalDir() string {
 	return ensureSteampipeSubDir("internal")
 }
 
+// EnsurePluginRollbackDir returns the path to the directory containing the previously installed plugin versions (creates if missing)
+// NOTE: this must not be under the plugins directory, as the plugin version file is recomposed from the version files found there
+func EnsurePluginRollbackDir() string {
+	return ensureSteampipeSubDir(filepath.Join("internal", "plugin_rollback"))
+}
+
+// EnsureLazyLoadRequestsDir returns the path to the directory containing requests to import the schemas
+// of connections with lazy schema import (creates if missing)
+func EnsureLazyLoadRequestsDir() string {
+	return ensureSteampipeSubDir(filepath.Join("internal", "lazy_load_requests"))
+}
+
 // EnsureBackupsDir returns the path to the backups directory (creates if missing)
 func EnsureBackupsDir() string {
 	return ensureSteampipeSubDir("backups")
@@ -133,6 +148,11 @@ func ConnectionStatePath() string {
 	return filepath.Join(EnsureInternalDir(), connectionsStateFileName)
 }
 
+// SecretsFilePath returns the path of the default encrypted secrets file
+func SecretsFilePath() string {
+	return filepath.Join(EnsureInternalDir(), secretsFileName)
+}
+
 // LegacyVersionFilePath returns the legacy version file path
 func LegacyVersionFilePath() string {
 	return filepath.Join(EnsureInternalDir(), versionFileName)
@@ -143,6 +163,11 @@ func PluginVersionFilePath() string {
 	return filepath.Join(EnsurePluginDir(), versionFileName)
 }
 
+// PluginLockFilePath returns the path of the plugin lock file
+func PluginLockFilePath() string {
+	return filepath.Join(EnsurePluginDir(), pluginLockFileName)
+}
+
 // DatabaseVersionFilePath returns the plugin version file path
 func DatabaseVersionFilePath() string {
 	return filepath.Join(EnsureDatabaseDir(), versionFileName)
@@ -165,6 +190,11 @@ func DashboardServiceStateFilePath() string {
 	return filepath.Join(EnsureInternalDir(), dashboardServerStateFileName)
 }
 
+// AuditLogStateFilePath returns the path of the file used to record how much of the database log has been audited
+func AuditLogStateFilePath() string {
+	return filepath.Join(EnsureInternalDir(), auditLogStateFileName)
+}
+
 func StateFileName() string {
 	return stateFileName
 }
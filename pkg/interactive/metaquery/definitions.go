@@ -101,12 +101,13 @@ func init() {
 		constants.CmdCache: {
 			title:       constants.CmdCache,
 			handler:     cacheControl,
-			validator:   validatorFromArgsOf(constants.CmdCache),
-			description: "Enable, disable or clear the query cache",
+			validator:   cacheValidator,
+			description: "Enable, disable, clear or show usage of the query cache",
 			args: []metaQueryArg{
 				{value: constants.ArgOn, description: "Turn on caching"},
 				{value: constants.ArgOff, description: "Turn off caching"},
-				{value: constants.ArgClear, description: "Clear the cache"},
+				{value: constants.ArgClear, description: "Clear the cache - optionally for a single connection: .cache clear <connection>"},
+				{value: constants.ArgStats, description: "Show the scans and cache hits of each connection in this session"},
 			},
 			completer: completerFromArgsOf(constants.CmdCache),
 		},
@@ -8,10 +8,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/spf13/viper"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/db/db_common"
+	"github.com/turbot/steampipe/pkg/display"
 )
 
 // controls the cache in the connected FDW
@@ -48,12 +50,54 @@ func cacheControl(ctx context.Context, input *HandlerInput) error {
 		viper.Set(constants.ArgClientCacheEnabled, false)
 		return db_common.SetCacheEnabled(ctx, false, conn)
 	case constants.ArgClear:
+		if len(input.args()) > 1 {
+			return connectionCacheClear(ctx, input, conn, input.args()[1])
+		}
 		return db_common.CacheClear(ctx, conn)
+	case constants.ArgStats:
+		return showCacheStats(ctx, conn)
 	}
 
 	return fmt.Errorf("invalid command")
 }
 
+// clears the cache for a single connection
+func connectionCacheClear(ctx context.Context, input *HandlerInput, conn *pgx.Conn, connectionName string) error {
+	if input.ConnectionState != nil {
+		if _, ok := input.ConnectionState[connectionName]; !ok {
+			return sperr.New("connection '%s' does not exist", connectionName)
+		}
+	}
+	return db_common.ConnectionCacheClear(ctx, connectionName, conn)
+}
+
+// shows the cache usage of each connection scanned in this session
+// NOTE: the cache is held by the plugins, so only scan counts and hits are available - not entry counts or sizes
+func showCacheStats(ctx context.Context, conn *pgx.Conn) error {
+	stats, err := db_common.CacheStats(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		fmt.Println("No tables have been scanned in this session.")
+		return nil
+	}
+
+	header := []string{"Connection", "Scans", "Cache hits", "Rows fetched", "Hit rate"}
+	var rows [][]string
+	for _, s := range stats {
+		rows = append(rows, []string{
+			s.Connection,
+			fmt.Sprintf("%d", s.Scans),
+			fmt.Sprintf("%d", s.CacheHits),
+			fmt.Sprintf("%.0f", s.RowsFetched),
+			fmt.Sprintf("%.1f%%", s.HitRate),
+		})
+	}
+	display.ShowWrappedTable(header, rows, &display.ShowWrappedTableOptions{AutoMerge: false})
+	return nil
+}
+
 // sets the cache TTL
 func cacheTTL(ctx context.Context, input *HandlerInput) error {
 	if len(input.args()) == 0 {
@@ -242,6 +242,10 @@ func inspectConnection(connectionName string, input *HandlerInput) bool {
 		error_helpers.ShowWarning(fmt.Sprintf("connection '%s' has schema import disabled", connectionName))
 		return true
 	}
+	if connectionState.Lazy() {
+		error_helpers.ShowWarning(fmt.Sprintf("connection '%s' has lazy schema import - its schema will be imported when it is first queried", connectionName))
+		return true
+	}
 
 	// have we loaded the schema for this connection yet?
 	schema, found := input.Schema.Schemas[connectionName]
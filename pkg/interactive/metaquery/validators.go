@@ -103,6 +103,21 @@ func validatorFromArgsOf(cmd string) validator {
 	}
 }
 
+// cacheValidator validates the args of the .cache command
+// the first arg must be one of the defined args - 'clear' may be followed by a connection name
+func cacheValidator(args []string) ValidationResult {
+	if len(args) == 0 {
+		return ValidationResult{ShouldRun: true}
+	}
+	if res := validatorFromArgsOf(constants.CmdCache)(args[:1]); res.Err != nil {
+		return res
+	}
+	if strings.ToLower(args[0]) == constants.ArgClear {
+		return atMostNArgs(2)(args)
+	}
+	return exactlyNArgs(1)(args)
+}
+
 var atLeastNArgs = func(n int) validator {
 	return func(args []string) ValidationResult {
 		numArgs := len(args)
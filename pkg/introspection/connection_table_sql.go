@@ -32,7 +32,13 @@ func GetConnectionStateTableCreateSql() []db_common.QueryWithArgs {
 	plugin_mod_time TIMESTAMPTZ,
 	file_name TEXT, 
 	start_line_number INTEGER, 
-	end_line_number INTEGER
+	end_line_number INTEGER,
+	connection_selector JSONB NULL,
+	tags JSONB NULL,
+	tables_include TEXT[] NULL,
+	tables_exclude TEXT[] NULL,
+	default_quals JSONB NULL,
+	priority INTEGER DEFAULT 0
 );`
 	return getConnectionStateQueries(queryFormat, nil)
 }
@@ -69,6 +75,7 @@ SET state = '%s',
 WHERE
 	state <> 'ready' 
 AND state <> 'disabled' 
+AND state <> 'lazy' 
 AND state <> 'error' 
 	`,
 		constants.ConnectionStateError)
@@ -94,8 +101,14 @@ func GetUpsertConnectionStateSql(c *steampipeconfig.ConnectionState) []db_common
 		plugin_mod_time,
 	    file_name,
 	    start_line_number,
-	    end_line_number)
-VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,now(),$12,$13,$14,$15) 
+	    end_line_number,
+	    connection_selector,
+	    tags,
+	    tables_include,
+	    tables_exclude,
+	    default_quals,
+	    priority)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,now(),$12,$13,$14,$15,$16,$17,$18,$19,$20,$21) 
 ON CONFLICT (name) 
 DO 
    UPDATE SET 
@@ -113,7 +126,13 @@ DO
 			  plugin_mod_time = $12,
 			  file_name = $13,
 	    	  start_line_number = $14,
-	     	  end_line_number = $15
+	     	  end_line_number = $15,
+	     	  connection_selector = $16,
+	     	  tags = $17,
+	     	  tables_include = $18,
+	     	  tables_exclude = $19,
+	     	  default_quals = $20,
+	     	  priority = $21
 			  
 `
 	args := []any{
@@ -132,6 +151,12 @@ DO
 		c.FileName,
 		c.StartLineNumber,
 		c.EndLineNumber,
+		c.ConnectionSelector,
+		c.Tags,
+		c.TablesInclude,
+		c.TablesExclude,
+		c.DefaultQuals,
+		c.Priority,
 	}
 	return getConnectionStateQueries(queryFormat, args)
 }
@@ -152,16 +177,27 @@ func GetNewConnectionStateFromConnectionInsertSql(c *modconfig.Connection) []db_
 		plugin_mod_time,
 		file_name,
 	    start_line_number,
-	    end_line_number)
-VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,now(),now(),$12,$13,$14) 
+	    end_line_number,
+	    connection_selector,
+	    tags,
+	    tables_include,
+	    tables_exclude,
+	    default_quals,
+	    priority)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,now(),now(),$12,$13,$14,$15,$16,$17,$18,$19,$20) 
 `
 	schemaMode := ""
 	commentsSet := false
 	schemaHash := ""
+	// a connection with lazy schema import will not be loaded by the connection refresh
+	state := constants.ConnectionStatePendingIncomplete
+	if c.ImportLazy() {
+		state = constants.ConnectionStateLazy
+	}
 
 	args := []any{
 		c.Name,
-		constants.ConnectionStatePendingIncomplete,
+		state,
 		c.Type,
 		maps.Keys(c.Connections),
 		c.ImportSchema,
@@ -174,6 +210,12 @@ VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,now(),now(),$12,$13,$14)
 		c.DeclRange.Filename,
 		c.DeclRange.Start.Line,
 		c.DeclRange.End.Line,
+		c.ConnectionTagSelector,
+		c.Tags,
+		c.TablesInclude,
+		c.TablesExclude,
+		c.DefaultQuals,
+		c.Priority,
 	}
 
 	return getConnectionStateQueries(queryFormat, args)
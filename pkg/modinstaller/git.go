@@ -7,22 +7,30 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
 )
 
-func getGitUrl(modName string) string {
-	return fmt.Sprintf("https://%s", modName)
+// getGitUrl returns the url of the repo for the mod, applying the url rewrite of the git host config (if any)
+func getGitUrl(modName string, gitHost *modconfig.GitHost) string {
+	return gitHost.GetRepoUrl(modName)
 }
 
-func getTags(repo string) ([]string, error) {
+// listRefs lists the references of the remote repository
+func listRefs(remote *gitRemote) ([]*plumbing.Reference, error) {
 	// Create the remote with repository URL
 	rem := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
 		Name: "origin",
-		URLs: []string{repo},
+		URLs: []string{remote.Url},
 	})
 
 	// load remote references
-	refs, err := rem.List(&git.ListOptions{})
+	return rem.List(&git.ListOptions{Auth: remote.Auth})
+}
+
+func getTags(remote *gitRemote) ([]string, error) {
+	refs, err := listRefs(remote)
 	if err != nil {
 		return nil, err
 	}
@@ -38,8 +46,23 @@ func getTags(repo string) ([]string, error) {
 	return tags, nil
 }
 
-func getTagVersionsFromGit(repo string, includePrerelease bool) (semver.Collection, error) {
-	tags, err := getTags(repo)
+// getBranchHead returns the commit sha at the head of the given branch
+func getBranchHead(remote *gitRemote, branch string) (string, error) {
+	refs, err := listRefs(remote)
+	if err != nil {
+		return "", err
+	}
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	for _, ref := range refs {
+		if ref.Name() == branchRef {
+			return ref.Hash().String(), nil
+		}
+	}
+	return "", fmt.Errorf("branch '%s' not found", branch)
+}
+
+func getTagVersionsFromGit(remote *gitRemote, includePrerelease bool) (semver.Collection, error) {
+	tags, err := getTags(remote)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,136 @@
+package modinstaller
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+const defaultGitUsername = "git"
+
+// scp style ssh url, e.g. git@github.com:turbot/steampipe-mod-aws-compliance
+var scpUrlRegex = regexp.MustCompile(`^([A-Za-z0-9._-]+)@[A-Za-z0-9._-]+:`)
+
+// gitRemote is the url and credentials used to list tags and clone a mod dependency
+type gitRemote struct {
+	Url  string
+	Auth transport.AuthMethod
+	// is this a repository on the local filesystem (i.e. a mirror)
+	// - local repositories do not support shallow clones
+	Local bool
+}
+
+// getGitRemote returns the git remote for a mod dependency, applying the url rewrite
+// and credentials of the git_host config for the mod host (if any)
+// the same remote is used for tag listing and cloning
+func getGitRemote(modName string, gitHosts map[string]*modconfig.GitHost) (*gitRemote, error) {
+	gitHost := gitHosts[modconfig.GitHostForMod(modName)]
+	remote := &gitRemote{Url: getGitUrl(modName, gitHost)}
+	if gitHost == nil {
+		return remote, nil
+	}
+
+	var err error
+	if isSshUrl(remote.Url) {
+		remote.Auth, err = getSshAuth(remote.Url, gitHost)
+	} else {
+		remote.Auth, err = getHttpAuth(remote.Url, gitHost)
+	}
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to get credentials for git host '%s'", gitHost.Host)
+	}
+	return remote, nil
+}
+
+func getSshAuth(repoUrl string, gitHost *modconfig.GitHost) (transport.AuthMethod, error) {
+	username := sshUsername(repoUrl)
+	if gitHost.Username != nil {
+		username = *gitHost.Username
+	}
+	if gitHost.SshKey != nil {
+		keyPath, err := filehelpers.Tildefy(*gitHost.SshKey)
+		if err != nil {
+			return nil, err
+		}
+		return gitssh.NewPublicKeysFromFile(username, keyPath, "")
+	}
+	return gitssh.NewSSHAgentAuth(username)
+}
+
+func getHttpAuth(repoUrl string, gitHost *modconfig.GitHost) (transport.AuthMethod, error) {
+	username := defaultGitUsername
+	if gitHost.Username != nil {
+		username = *gitHost.Username
+	}
+	if gitHost.TokenEnv != nil {
+		token, ok := os.LookupEnv(*gitHost.TokenEnv)
+		if !ok || token == "" {
+			return nil, fmt.Errorf("environment variable '%s' is not set", *gitHost.TokenEnv)
+		}
+		return &http.BasicAuth{Username: username, Password: token}, nil
+	}
+	if gitHost.CredentialHelper != nil && *gitHost.CredentialHelper {
+		return gitCredentialFill(repoUrl)
+	}
+	// no credentials configured
+	return nil, nil
+}
+
+// gitCredentialFill retrieves the credentials for the url from the git credential helper
+func gitCredentialFill(repoUrl string) (transport.AuthMethod, error) {
+	u, err := url.Parse(repoUrl)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=%s\nhost=%s\npath=%s\n\n", u.Scheme, u.Host, strings.TrimPrefix(u.Path, "/")))
+	// never prompt - we may not be running in a terminal
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git credential fill failed: %s %s", err.Error(), strings.TrimSpace(stderr.String()))
+	}
+
+	auth := &http.BasicAuth{}
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		switch key {
+		case "username":
+			auth.Username = value
+		case "password":
+			auth.Password = value
+		}
+	}
+	if auth.Password == "" {
+		return nil, fmt.Errorf("git credential helper returned no password for %s", u.Host)
+	}
+	return auth, nil
+}
+
+func isSshUrl(repoUrl string) bool {
+	return strings.HasPrefix(repoUrl, "ssh://") || scpUrlRegex.MatchString(repoUrl)
+}
+
+// sshUsername returns the user specified in the ssh url, defaulting to 'git'
+func sshUsername(repoUrl string) string {
+	if match := scpUrlRegex.FindStringSubmatch(repoUrl); match != nil {
+		return match[1]
+	}
+	if u, err := url.Parse(repoUrl); err == nil && u.User != nil && u.User.Username() != "" {
+		return u.User.Username()
+	}
+	return defaultGitUsername
+}
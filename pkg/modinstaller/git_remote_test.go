@@ -0,0 +1,80 @@
+package modinstaller
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+type getGitRemoteTest struct {
+	modName      string
+	expectedUrl  string
+	expectedUser string
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestGetGitRemote(t *testing.T) {
+	t.Setenv("TEST_GHE_TOKEN", "secret")
+	gitHosts := map[string]*modconfig.GitHost{
+		"github.example.com": {
+			Host:     "github.example.com",
+			TokenEnv: strPtr("TEST_GHE_TOKEN"),
+		},
+		"gitlab.example.com": {
+			Host: "gitlab.example.com",
+			Url:  strPtr("https://mirror.example.com/gitlab/"),
+		},
+		"bitbucket.example.com": {
+			Host: "bitbucket.example.com",
+			Url:  strPtr("deploy@bitbucket.example.com:"),
+		},
+	}
+
+	tests := map[string]getGitRemoteTest{
+		"no host config": {
+			modName:     "github.com/turbot/steampipe-mod-aws-compliance",
+			expectedUrl: "https://github.com/turbot/steampipe-mod-aws-compliance",
+		},
+		"token from env": {
+			modName:      "github.example.com/org/mod",
+			expectedUrl:  "https://github.example.com/org/mod",
+			expectedUser: defaultGitUsername,
+		},
+		"https rewrite": {
+			modName:     "gitlab.example.com/group/sub/mod",
+			expectedUrl: "https://mirror.example.com/gitlab/group/sub/mod",
+		},
+	}
+
+	for name, test := range tests {
+		remote, err := getGitRemote(test.modName, gitHosts)
+		if err != nil {
+			t.Errorf("Test: '%s' FAILED: %s", name, err.Error())
+			continue
+		}
+		if remote.Url != test.expectedUrl {
+			t.Errorf("Test: '%s' FAILED: expected url %s, got %s", name, test.expectedUrl, remote.Url)
+		}
+		if test.expectedUser == "" {
+			continue
+		}
+		auth, ok := remote.Auth.(*http.BasicAuth)
+		if !ok || auth.Username != test.expectedUser || auth.Password != "secret" {
+			t.Errorf("Test: '%s' FAILED: expected basic auth for user %s, got %v", name, test.expectedUser, remote.Auth)
+		}
+	}
+
+	// scp style rewrite
+	url := getGitUrl("bitbucket.example.com/team/mod", gitHosts["bitbucket.example.com"])
+	if url != "deploy@bitbucket.example.com:team/mod" {
+		t.Errorf("expected scp url, got %s", url)
+	}
+	if !isSshUrl(url) || sshUsername(url) != "deploy" {
+		t.Errorf("expected %s to be an ssh url for user 'deploy'", url)
+	}
+	if sshUsername("ssh://git@github.example.com:7999/org/mod") != "git" {
+		t.Errorf("expected ssh url user 'git'")
+	}
+}
@@ -1,6 +1,8 @@
 package modinstaller
 
 import (
+	"strings"
+
 	"github.com/Masterminds/semver/v3"
 	"github.com/turbot/steampipe/pkg/versionhelpers"
 )
@@ -14,3 +16,15 @@ func getVersionSatisfyingConstraint(constraint *versionhelpers.Constraints, avai
 	}
 	return nil
 }
+
+// versionsString returns a comma separated list of the versions, for use in messages
+func versionsString(versions []*semver.Version) string {
+	if len(versions) == 0 {
+		return "none"
+	}
+	strs := make([]string, len(versions))
+	for i, v := range versions {
+		strs[i] = v.String()
+	}
+	return strings.Join(strs, ", ")
+}
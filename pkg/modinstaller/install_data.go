@@ -2,6 +2,7 @@ package modinstaller
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
@@ -27,12 +28,18 @@ type InstallData struct {
 	// list of dependencies which have been uninstalled
 	Uninstalled  versionmap.DependencyVersionMap
 	WorkspaceMod *modconfig.Mod
+
+	// git host config, used to resolve the url and credentials for each dependency
+	gitHosts map[string]*modconfig.GitHost
+	// if set, dependencies are resolved and installed from this mirror rather than from git remotes
+	mirror *modMirror
 }
 
-func NewInstallData(workspaceLock *versionmap.WorkspaceLock, workspaceMod *modconfig.Mod) *InstallData {
+func NewInstallData(workspaceLock *versionmap.WorkspaceLock, workspaceMod *modconfig.Mod, gitHosts map[string]*modconfig.GitHost) *InstallData {
 	return &InstallData{
 		Lock:         workspaceLock,
 		WorkspaceMod: workspaceMod,
+		gitHosts:     gitHosts,
 		NewLock:      versionmap.EmptyWorkspaceLock(workspaceLock),
 		allAvailable: make(versionmap.VersionListMap),
 		Installed:    make(versionmap.DependencyVersionMap),
@@ -47,6 +54,10 @@ func (d *InstallData) GetAvailableUpdates() (versionmap.DependencyVersionMap, er
 	res := make(versionmap.DependencyVersionMap)
 	for parent, deps := range d.Lock.InstallCache {
 		for name, resolvedConstraint := range deps {
+			// updates for dependencies pinned to a git branch or commit are not version based
+			if resolvedConstraint.IsGitRef() {
+				continue
+			}
 			includePrerelease := resolvedConstraint.IsPrerelease()
 			availableVersions, err := d.getAvailableModVersions(name, includePrerelease)
 			if err != nil {
@@ -66,17 +77,36 @@ func (d *InstallData) GetAvailableUpdates() (versionmap.DependencyVersionMap, er
 func (d *InstallData) onModInstalled(dependency *ResolvedModRef, modDef *modconfig.Mod, parent *modconfig.Mod) {
 	parentPath := parent.GetInstallCacheKey()
 	// get the constraint from the parent (it must be there)
-	modVersionConstraint := parent.Require.GetModDependency(dependency.Name).Constraint.Original
+	modVersionConstraint := parent.Require.GetModDependency(dependency.Name).ConstraintString()
 
 	// update lock
-	d.NewLock.InstallCache.Add(dependency.Name, modDef.ShortName, modDef.Version, modVersionConstraint, parentPath)
+	resolved := versionmap.NewResolvedVersionConstraint(dependency.Name, modDef.ShortName, modDef.Version, modVersionConstraint)
+	resolved.Branch = dependency.Branch
+	resolved.Commit = dependency.Commit
+	resolved.Integrity = dependency.Integrity
+	d.NewLock.InstallCache.AddResolvedVersion(resolved, parentPath)
 }
 
 // addExisting is called when a dependency is satisfied by a mod which is already installed
-func (d *InstallData) addExisting(dependencyName string, existingDep *modconfig.Mod, constraint *versionhelpers.Constraints, parent *modconfig.Mod) {
+func (d *InstallData) addExisting(requiredModVersion *modconfig.ModVersionConstraint, existingDep *modconfig.Mod, parent *modconfig.Mod) {
 	// update lock
 	parentPath := parent.GetInstallCacheKey()
-	d.NewLock.InstallCache.Add(dependencyName, existingDep.ShortName, existingDep.Version, constraint.Original, parentPath)
+	resolved := versionmap.NewResolvedVersionConstraint(requiredModVersion.Name, existingDep.ShortName, existingDep.Version, requiredModVersion.ConstraintString())
+	if locked := d.Lock.GetMod(requiredModVersion.Name, parent); locked != nil {
+		// the resolved commit is only known from the existing lock
+		if requiredModVersion.HasGitRef() {
+			resolved.Branch = locked.Branch
+			resolved.Commit = locked.Commit
+		}
+		resolved.Integrity = locked.Integrity
+	}
+	// locks written by older versions do not have integrity hashes - compute from the installed mod
+	if resolved.Integrity == "" {
+		if integrity, err := versionmap.HashModDirectory(filepath.Join(d.Lock.ModInstallationPath, resolved.DependencyPath())); err == nil {
+			resolved.Integrity = integrity
+		}
+	}
+	d.NewLock.InstallCache.AddResolvedVersion(resolved, parentPath)
 }
 
 // retrieve all available mod versions from our cache, or from Git if not yet cached
@@ -86,11 +116,24 @@ func (d *InstallData) getAvailableModVersions(modName string, includePrerelease
 	if ok {
 		return availableVersions, nil
 	}
+	// if we are installing from a mirror, retrieve from the mirror
+	if d.mirror != nil {
+		availableVersions, err := d.mirror.getAvailableVersions(modName, includePrerelease)
+		if err != nil {
+			return nil, err
+		}
+		d.allAvailable[modName] = availableVersions
+		return availableVersions, nil
+	}
+
 	// so we have not cached this yet - retrieve from Git
-	var err error
-	availableVersions, err = getTagVersionsFromGit(getGitUrl(modName), includePrerelease)
+	remote, err := d.getGitRemote(modName)
+	if err != nil {
+		return nil, err
+	}
+	availableVersions, err = getTagVersionsFromGit(remote, includePrerelease)
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve version data from Git URL '%s'", modName)
+		return nil, fmt.Errorf("could not retrieve version data from Git URL '%s': %s", remote.Url, err.Error())
 	}
 	// update our cache
 	d.allAvailable[modName] = availableVersions
@@ -98,6 +141,14 @@ func (d *InstallData) getAvailableModVersions(modName string, includePrerelease
 	return availableVersions, nil
 }
 
+// getGitRemote returns the git remote for the mod - this is the mirror repository if we are installing from a mirror
+func (d *InstallData) getGitRemote(modName string) (*gitRemote, error) {
+	if d.mirror != nil {
+		return d.mirror.getGitRemote(modName)
+	}
+	return getGitRemote(modName, d.gitHosts)
+}
+
 // update the lock with the NewLock and dtermine if any mods have been uninstalled
 func (d *InstallData) onInstallComplete() {
 	d.Installed = d.NewLock.InstallCache.GetMissingFromOther(d.Lock.InstallCache)
@@ -13,6 +13,8 @@ type InstallOpts struct {
 	ModArgs      []string
 	DryRun       bool
 	Force        bool
+	// if set, dependencies are installed from this mirror (a directory or tarball) rather than from git
+	MirrorPath string
 }
 
 func NewInstallOpts(workspaceMod *modconfig.Mod, modsToInstall ...string) *InstallOpts {
@@ -23,6 +25,7 @@ func NewInstallOpts(workspaceMod *modconfig.Mod, modsToInstall ...string) *Insta
 		Force:        viper.GetBool(constants.ArgForce),
 		ModArgs:      modsToInstall,
 		Command:      cmdName,
+		MirrorPath:   viper.GetString(constants.ArgFrom),
 	}
 	return opts
 }
@@ -0,0 +1,193 @@
+package modinstaller
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/versionmap"
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+// modMirror is a local source of mod dependencies, used instead of git remotes when installing offline
+//
+// the mirror is a directory (or gzipped tarball) which may contain:
+//   - vendored mods, as written by 'steampipe mod vendor' - a 'mods' folder of <mod name>@v<version>
+//     folders, and the workspace lock of the vendoring workspace
+//   - bare git repositories, at <mirror>/<mod name>.git or <mirror>/<mod name>
+type modMirror struct {
+	// the mirror location, as specified by the user
+	location string
+	// the directory containing the mirror contents
+	path string
+	// the workspace lock written with the vendored mods (if any)
+	lock versionmap.DependencyVersionMap
+	// if the mirror is a tarball, the temp directory it is extracted into
+	tempDir string
+}
+
+func newModMirror(location string) (*modMirror, error) {
+	location, err := filehelpers.Tildefy(location)
+	if err != nil {
+		return nil, err
+	}
+	m := &modMirror{location: location, path: location}
+
+	if utils.IsGzippedTarball(location) {
+		if !filehelpers.FileExists(location) {
+			return nil, fmt.Errorf("mod mirror '%s' does not exist", location)
+		}
+		m.tempDir, err = os.MkdirTemp("", "steampipe-mod-mirror")
+		if err != nil {
+			return nil, err
+		}
+		if err := utils.ExtractGzippedTarball(location, m.tempDir); err != nil {
+			m.close()
+			return nil, sperr.WrapWithMessage(err, "failed to extract mod mirror '%s'", location)
+		}
+		m.path = m.tempDir
+	} else if !filehelpers.DirectoryExists(location) {
+		return nil, fmt.Errorf("mod mirror '%s' does not exist", location)
+	}
+
+	if err := m.loadLock(); err != nil {
+		m.close()
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *modMirror) loadLock() error {
+	m.lock = make(versionmap.DependencyVersionMap)
+	lockPath := filepaths.WorkspaceLockPath(m.path)
+	if !filehelpers.FileExists(lockPath) {
+		return nil
+	}
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &m.lock); err != nil {
+		return sperr.WrapWithMessage(err, "failed to parse the workspace lock in mod mirror '%s'", m.location)
+	}
+	return nil
+}
+
+// close removes the extracted tarball contents (if any)
+func (m *modMirror) close() {
+	if m != nil && m.tempDir != "" {
+		_ = os.RemoveAll(m.tempDir)
+	}
+}
+
+// getVendoredModPath returns the path of the vendored mod with the given dependency path,
+// or an empty string if the mirror does not contain it
+func (m *modMirror) getVendoredModPath(dependencyPath string) string {
+	modPath := filepath.Join(m.path, filepaths.WorkspaceModDir, dependencyPath)
+	if !filehelpers.DirectoryExists(modPath) {
+		return ""
+	}
+	return modPath
+}
+
+// getVendoredVersions returns the versions of the mod vendored in the mirror
+func (m *modMirror) getVendoredVersions(modName string) []*semver.Version {
+	modFolders, _ := filepath.Glob(filepath.Join(m.path, filepaths.WorkspaceModDir, modName+"@v*"))
+	var res []*semver.Version
+	for _, modFolder := range modFolders {
+		_, version, err := modconfig.ParseModDependencyPath(filepath.Base(modFolder))
+		if err != nil {
+			continue
+		}
+		res = append(res, version)
+	}
+	return res
+}
+
+// getBareRepoPath returns the path of the bare git repository for the mod,
+// or an empty string if the mirror does not contain one
+func (m *modMirror) getBareRepoPath(modName string) string {
+	for _, repoPath := range []string{
+		filepath.Join(m.path, modName+".git"),
+		filepath.Join(m.path, modName),
+	} {
+		// a bare repository has a HEAD file at the top level
+		if filehelpers.FileExists(filepath.Join(repoPath, "HEAD")) {
+			return repoPath
+		}
+	}
+	return ""
+}
+
+func (m *modMirror) getGitRemote(modName string) (*gitRemote, error) {
+	repoPath := m.getBareRepoPath(modName)
+	if repoPath == "" {
+		return nil, fmt.Errorf("mod mirror '%s' does not contain a git repository for %s", m.location, modName)
+	}
+	return &gitRemote{Url: repoPath, Local: true}, nil
+}
+
+// getAvailableVersions returns the versions of the mod which are vendored in the mirror,
+// or tagged in its bare git repository, sorted in REVERSE order
+func (m *modMirror) getAvailableVersions(modName string, includePrerelease bool) (semver.Collection, error) {
+	var versions semver.Collection
+	for _, v := range m.getVendoredVersions(modName) {
+		// pseudo versions of mods pinned to a git branch or commit are never resolved from a constraint
+		if strings.HasPrefix(v.Prerelease(), "commit-") {
+			continue
+		}
+		if !includePrerelease && (v.Metadata() != "" || v.Prerelease() != "") {
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	if m.getBareRepoPath(modName) != "" {
+		remote, err := m.getGitRemote(modName)
+		if err != nil {
+			return nil, err
+		}
+		tagVersions, err := getTagVersionsFromGit(remote, includePrerelease)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range tagVersions {
+			if !containsVersion(versions, v) {
+				versions = append(versions, v)
+			}
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("mod mirror '%s' does not contain any versions of %s", m.location, modName)
+	}
+	sort.Sort(sort.Reverse(versions))
+	return versions, nil
+}
+
+// getLockedCommit returns the commit recorded in the mirror lock for the mod tracking the given branch
+func (m *modMirror) getLockedCommit(modName, branch string) string {
+	for _, dep := range m.lock.FlatMap() {
+		if dep.Name == modName && dep.Branch == branch && dep.Commit != "" {
+			return dep.Commit
+		}
+	}
+	return ""
+}
+
+func containsVersion(versions semver.Collection, version *semver.Version) bool {
+	for _, v := range versions {
+		if v.Equal(version) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package modinstaller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+type mirrorVersionsTest struct {
+	modName           string
+	includePrerelease bool
+	expected          string
+	expectError       bool
+}
+
+func TestModMirror(t *testing.T) {
+	source := t.TempDir()
+	for _, dependencyPath := range []string{
+		"github.com/turbot/mod1@v1.0.0",
+		"github.com/turbot/mod1@v1.2.0",
+		"github.com/turbot/mod1@v2.0.0-rc.1",
+		"github.com/turbot/mod1@v0.0.0-commit-1a2b3c4d5e6f",
+	} {
+		modPath := filepath.Join(source, "mods", dependencyPath)
+		if err := os.MkdirAll(modPath, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(modPath, "mod.sp"), []byte(`mod "mod1" {}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lock := `{"mod.local": {"github.com/turbot/mod1": {"name": "github.com/turbot/mod1", "version": "0.0.0-commit-1a2b3c4d5e6f", "branch": "main", "commit": "1a2b3c4d5e6f1a2b3c4d5e6f1a2b3c4d5e6f1a2b"}}}`
+	if err := os.WriteFile(filepath.Join(source, ".mod.cache.json"), []byte(lock), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// round trip the mirror through a tarball
+	tarball := filepath.Join(t.TempDir(), "mods.tar.gz")
+	if err := utils.WriteGzippedTarball(source, tarball); err != nil {
+		t.Fatal(err)
+	}
+	mirror, err := newModMirror(tarball)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mirror.close()
+
+	tests := map[string]mirrorVersionsTest{
+		"release versions": {
+			modName:  "github.com/turbot/mod1",
+			expected: "1.2.0, 1.0.0",
+		},
+		"including prerelease": {
+			modName:           "github.com/turbot/mod1",
+			includePrerelease: true,
+			expected:          "2.0.0-rc.1, 1.2.0, 1.0.0",
+		},
+		"missing mod": {
+			modName:     "github.com/turbot/mod2",
+			expectError: true,
+		},
+	}
+	for name, test := range tests {
+		versions, err := mirror.getAvailableVersions(test.modName, test.includePrerelease)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("Test: '%s' FAILED : expected error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test: '%s' FAILED : unexpected error %s", name, err.Error())
+			continue
+		}
+		if actual := versionsString(versions); actual != test.expected {
+			t.Errorf("Test: '%s' FAILED : expected %s, got %s", name, test.expected, actual)
+		}
+	}
+
+	if mirror.getVendoredModPath("github.com/turbot/mod1@v1.2.0") == "" {
+		t.Errorf("expected vendored mod github.com/turbot/mod1@v1.2.0 to be found in the mirror")
+	}
+	if commit := mirror.getLockedCommit("github.com/turbot/mod1", "main"); commit != "1a2b3c4d5e6f1a2b3c4d5e6f1a2b3c4d5e6f1a2b" {
+		t.Errorf("expected the locked commit of branch main, got '%s'", commit)
+	}
+}
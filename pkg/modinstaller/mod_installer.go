@@ -2,14 +2,17 @@ package modinstaller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/otiai10/copy"
 	"github.com/spf13/viper"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
@@ -17,6 +20,7 @@ import (
 	"github.com/turbot/steampipe/pkg/error_helpers"
 	"github.com/turbot/steampipe/pkg/filepaths"
 	"github.com/turbot/steampipe/pkg/plugin"
+	"github.com/turbot/steampipe/pkg/steampipeconfig"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/parse"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/versionmap"
@@ -86,8 +90,20 @@ func NewModInstaller(opts *InstallOpts) (*ModInstaller, error) {
 		return nil, err
 	}
 
+	// git host config (url rewrites and credentials) is defined in the steampipe config
+	var gitHosts map[string]*modconfig.GitHost
+	if steampipeconfig.GlobalConfig != nil {
+		gitHosts = steampipeconfig.GlobalConfig.GitHosts
+	}
+
 	// create install data
-	i.installData = NewInstallData(workspaceLock, i.workspaceMod)
+	i.installData = NewInstallData(workspaceLock, i.workspaceMod, gitHosts)
+	if opts.MirrorPath != "" {
+		i.installData.mirror, err = newModMirror(opts.MirrorPath)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// parse args to get the required mod versions
 	requiredMods, err := i.GetRequiredModVersionsFromArgs(opts.ModArgs)
@@ -175,6 +191,9 @@ func (i *ModInstaller) UninstallWorkspaceDependencies(ctx context.Context) error
 func (i *ModInstaller) InstallWorkspaceDependencies(ctx context.Context) (err error) {
 	workspaceMod := i.workspaceMod
 	defer func() {
+		// remove the extracted mirror contents (if any)
+		i.installData.mirror.close()
+
 		if err != nil && i.force {
 			// suppress the error since this is a forced install
 			log.Println("[TRACE] suppressing error in InstallWorkspaceDependencies because force is enabled", err)
@@ -238,6 +257,10 @@ func (i *ModInstaller) GetModList() string {
 	return i.installData.Lock.GetModList(i.workspaceMod.GetInstallCacheKey())
 }
 
+func (i *ModInstaller) GetModTree() *versionmap.DependencyTreeNode {
+	return i.installData.Lock.GetModTree(i.workspaceMod.GetInstallCacheKey())
+}
+
 // commitShadow recursively copies over the contents of the shadow directory
 // to the mods directory, replacing conflicts as it goes
 // (uses `os.Create(dest)` under the hood - which truncates the target)
@@ -342,19 +365,11 @@ func (i *ModInstaller) installModDependencesRecursively(ctx context.Context, req
 		// short circuit if the execution context has been cancelled
 		return ctx.Err()
 	}
-	// get available versions for this mod
-	includePrerelease := requiredModVersion.Constraint.IsPrerelease()
-	availableVersions, err := i.installData.getAvailableModVersions(requiredModVersion.Name, includePrerelease)
-
-	if err != nil {
-		return err
-	}
-
 	var errors []error
 
 	if dependencyMod == nil {
 		// get a resolved mod ref that satisfies the version constraints
-		resolvedRef, err := i.getModRefSatisfyingConstraints(requiredModVersion, availableVersions)
+		resolvedRef, err := i.getModRefSatisfyingRequirement(requiredModVersion, parent)
 		if err != nil {
 			return err
 		}
@@ -369,8 +384,8 @@ func (i *ModInstaller) installModDependencesRecursively(ctx context.Context, req
 		errors = append(errors, validationErrors...)
 	} else {
 		// update the install data
-		i.installData.addExisting(requiredModVersion.Name, dependencyMod, requiredModVersion.Constraint, parent)
-		log.Printf("[TRACE] not installing %s with version constraint %s as version %s is already installed", requiredModVersion.Name, requiredModVersion.Constraint.Original, dependencyMod.Version)
+		i.installData.addExisting(requiredModVersion, dependencyMod, parent)
+		log.Printf("[TRACE] not installing %s with %s as version %s is already installed", requiredModVersion.Name, requiredModVersion.RequirementString(), dependencyMod.Version)
 	}
 
 	// to get here we have the dependency mod - either we installed it or it was already installed
@@ -411,10 +426,38 @@ func (i *ModInstaller) getCurrentlyInstalledVersionToUse(ctx context.Context, re
 		return nil, nil
 	}
 
+	// if the installed mod has been modified since it was installed, reinstall it
+	modified, err := i.removeIfModified(installedVersion)
+	if err != nil {
+		return nil, err
+	}
+	if modified {
+		return nil, nil
+	}
+
 	// load the existing mod and return
 	return i.loadDependencyMod(ctx, installedVersion)
 }
 
+// removeIfModified verifies the contents of the installed dependency against the integrity hash in the lock,
+// removing the dependency if it has been modified so that it is reinstalled
+func (i *ModInstaller) removeIfModified(installedVersion *versionmap.ResolvedVersionConstraint) (bool, error) {
+	err := versionmap.VerifyInstalledDependency(installedVersion, i.modsPath)
+	if err == nil {
+		return false, nil
+	}
+	var modifiedErr *versionmap.DependencyModifiedError
+	if !errors.As(err, &modifiedErr) {
+		return false, sperr.WrapWithMessage(err, "failed to verify '%s'", installedVersion.DependencyPath())
+	}
+	dependencyDir := filepath.Join(i.modsPath, installedVersion.DependencyPath())
+	error_helpers.ShowWarning(fmt.Sprintf("'%s' has been modified since it was installed - reinstalling", installedVersion.DependencyPath()))
+	if i.dryRun {
+		return true, nil
+	}
+	return true, os.RemoveAll(dependencyDir)
+}
+
 // loadDependencyMod tries to load the mod definition from the shadow directory
 // and falls back to the 'mods' directory of the root mod
 func (i *ModInstaller) loadDependencyMod(ctx context.Context, modVersion *versionmap.ResolvedVersionConstraint) (*modconfig.Mod, error) {
@@ -461,6 +504,9 @@ func (i *ModInstaller) loadDependencyModFromRoot(ctx context.Context, modInstall
 
 // determine if we should update this mod, and if so whether there is an update available
 func (i *ModInstaller) canUpdateMod(installedVersion *versionmap.ResolvedVersionConstraint, requiredModVersion *modconfig.ModVersionConstraint, forceUpdate bool) (bool, error) {
+	if requiredModVersion.HasGitRef() {
+		return i.gitRefUpdateAvailable(installedVersion, requiredModVersion, forceUpdate)
+	}
 	// so should we update?
 	// if forceUpdate is set or if the required version constraint is different to the locked version constraint, update
 	isSatisfied, errs := requiredModVersion.Constraint.Validate(installedVersion.Version)
@@ -481,6 +527,73 @@ func (i *ModInstaller) canUpdateMod(installedVersion *versionmap.ResolvedVersion
 
 }
 
+// determine whether the head of the branch tracked by the dependency has moved from the installed commit
+// (mods pinned to a commit are never updated)
+func (i *ModInstaller) gitRefUpdateAvailable(installedVersion *versionmap.ResolvedVersionConstraint, requiredModVersion *modconfig.ModVersionConstraint, forceUpdate bool) (bool, error) {
+	if !forceUpdate || requiredModVersion.Branch == "" {
+		return false, nil
+	}
+	head, err := i.getBranchHead(requiredModVersion)
+	if err != nil {
+		return false, err
+	}
+	return head != installedVersion.Commit, nil
+}
+
+func (i *ModInstaller) getBranchHead(requiredModVersion *modconfig.ModVersionConstraint) (string, error) {
+	remote, err := i.installData.getGitRemote(requiredModVersion.Name)
+	if err != nil {
+		return "", err
+	}
+	head, err := getBranchHead(remote, requiredModVersion.Branch)
+	if err != nil {
+		return "", fmt.Errorf("could not retrieve branch data from Git URL '%s': %s", remote.Url, err.Error())
+	}
+	return head, nil
+}
+
+// get a resolved mod ref satisfying the version constraint, branch or commit of the requirement
+func (i *ModInstaller) getModRefSatisfyingRequirement(requiredModVersion *modconfig.ModVersionConstraint, parent *modconfig.Mod) (*ResolvedModRef, error) {
+	if requiredModVersion.HasGitRef() {
+		return i.getGitModRef(requiredModVersion, parent)
+	}
+
+	// get available versions for this mod
+	includePrerelease := requiredModVersion.Constraint.IsPrerelease()
+	availableVersions, err := i.installData.getAvailableModVersions(requiredModVersion.Name, includePrerelease)
+	if err != nil {
+		return nil, err
+	}
+	return i.getModRefSatisfyingConstraints(requiredModVersion, availableVersions)
+}
+
+// get a resolved mod ref for a mod pinned to a git branch or commit
+// for a branch, the commit recorded in the lock file is used (if any) unless we are updating,
+// otherwise the current head of the branch is used
+func (i *ModInstaller) getGitModRef(requiredModVersion *modconfig.ModVersionConstraint, parent *modconfig.Mod) (*ResolvedModRef, error) {
+	if requiredModVersion.Commit != "" {
+		return NewGitRefResolvedModRef(requiredModVersion, strings.ToLower(requiredModVersion.Commit)), nil
+	}
+
+	if !i.updating() {
+		if missing := i.installData.Lock.MissingVersions[parent.GetInstallCacheKey()][requiredModVersion.Name]; missing != nil && missing.Satisfies(requiredModVersion) {
+			return NewGitRefResolvedModRef(requiredModVersion, missing.Commit), nil
+		}
+	}
+	// if we are installing from a mirror of vendored mods, use the commit vendored for this branch
+	if mirror := i.installData.mirror; mirror != nil {
+		if commit := mirror.getLockedCommit(requiredModVersion.Name, requiredModVersion.Branch); commit != "" {
+			return NewGitRefResolvedModRef(requiredModVersion, commit), nil
+		}
+	}
+
+	head, err := i.getBranchHead(requiredModVersion)
+	if err != nil {
+		return nil, err
+	}
+	return NewGitRefResolvedModRef(requiredModVersion, head), nil
+}
+
 // determine whether there is a newer mod version avoilable which satisfies the dependency version constraint
 func (i *ModInstaller) updateAvailable(requiredVersion *modconfig.ModVersionConstraint, currentVersion *semver.Version, availableVersions []*semver.Version) (bool, error) {
 	latestVersion, err := i.getModRefSatisfyingConstraints(requiredVersion, availableVersions)
@@ -498,7 +611,10 @@ func (i *ModInstaller) getModRefSatisfyingConstraints(modVersion *modconfig.ModV
 	// find a version which satisfies the version constraint
 	var version = getVersionSatisfyingConstraint(modVersion.Constraint, availableVersions)
 	if version == nil {
-		return nil, fmt.Errorf("no version of %s found satisfying version constraint: %s", modVersion.Name, modVersion.Constraint.Original)
+		if mirror := i.installData.mirror; mirror != nil {
+			return nil, fmt.Errorf("no version of %s satisfying version constraint %s found in mod mirror '%s' (available versions: %s)", modVersion.Name, modVersion.ConstraintString(), mirror.location, versionsString(availableVersions))
+		}
+		return nil, fmt.Errorf("no version of %s found satisfying version constraint: %s", modVersion.Name, modVersion.ConstraintString())
 	}
 
 	return NewResolvedModRef(modVersion, version)
@@ -520,11 +636,17 @@ func (i *ModInstaller) install(ctx context.Context, dependency *ResolvedModRef,
 	// if it does not exist (the usual case), install it
 	if _, err := os.Stat(destPath); os.IsNotExist(err) {
 		log.Println("[TRACE] installing", dependencyPath, "in", destPath)
-		if err := i.installFromGit(dependency, destPath); err != nil {
+		if err := i.installDependency(dependency, destPath); err != nil {
 			return nil, err
 		}
 	}
 
+	// verify the contents match those recorded in the lock when this version was previously installed
+	// (this will fail if the release has been re-tagged)
+	if err := i.setIntegrity(dependency, destPath); err != nil {
+		return nil, err
+	}
+
 	// now load the installed mod and return it
 	modDef, err = parse.LoadModfile(destPath)
 	if err != nil {
@@ -544,22 +666,89 @@ func (i *ModInstaller) install(ctx context.Context, dependency *ResolvedModRef,
 	return modDef, nil
 }
 
+// compute the integrity hash of the installed dependency, and verify it against the lock
+func (i *ModInstaller) setIntegrity(dependency *ResolvedModRef, installPath string) error {
+	integrity, err := versionmap.HashModDirectory(installPath)
+	if err != nil {
+		return err
+	}
+	if locked := i.installData.Lock.GetLockedIntegrity(dependency.Name, dependency.Version); locked != "" && locked != integrity {
+		return fmt.Errorf("integrity check failed for '%s' - the contents do not match the hash recorded in the workspace lock (expected %s, got %s)", dependency.DependencyPath(), locked, integrity)
+	}
+	dependency.Integrity = integrity
+	return nil
+}
+
+// install the dependency from the vendored mods of the mirror (if any), otherwise from git
+func (i *ModInstaller) installDependency(dependency *ResolvedModRef, installPath string) error {
+	if mirror := i.installData.mirror; mirror != nil {
+		if vendoredPath := mirror.getVendoredModPath(dependency.DependencyPath()); vendoredPath != "" {
+			log.Println("[TRACE] copying vendored mod", vendoredPath, "to", installPath)
+			return copy.Copy(vendoredPath, installPath)
+		}
+	}
+	return i.installFromGit(dependency, installPath)
+}
+
 func (i *ModInstaller) installFromGit(dependency *ResolvedModRef, installPath string) error {
 	// get the mod from git
-	gitUrl := getGitUrl(dependency.Name)
-	log.Println("[TRACE] >>> cloning", gitUrl, dependency.GitReference)
-	_, err := git.PlainClone(installPath,
-		false,
-		&git.CloneOptions{
-			URL:           gitUrl,
-			ReferenceName: dependency.GitReference,
-			Depth:         1,
-			SingleBranch:  true,
-		})
+	remote, err := i.installData.getGitRemote(dependency.Name)
+	if err != nil {
+		return err
+	}
+	if dependency.IsGitRef() {
+		return i.installGitRefFromGit(dependency, remote, installPath)
+	}
+	log.Println("[TRACE] >>> cloning", remote.Url, dependency.GitReference)
+	cloneOptions := &git.CloneOptions{
+		URL:           remote.Url,
+		Auth:          remote.Auth,
+		ReferenceName: dependency.GitReference,
+		Depth:         1,
+		SingleBranch:  true,
+	}
+	if remote.Local {
+		cloneOptions.Depth = 0
+	}
+	_, err = git.PlainClone(installPath, false, cloneOptions)
 
 	return err
 }
 
+// clone a mod pinned to a git branch or commit, and check out the resolved commit
+func (i *ModInstaller) installGitRefFromGit(dependency *ResolvedModRef, remote *gitRemote, installPath string) error {
+	log.Println("[TRACE] >>> cloning", remote.Url, dependency.GitReference, "at commit", dependency.Commit)
+	// the commit may not be the head of the branch, so we cannot do a shallow clone
+	cloneOptions := &git.CloneOptions{
+		URL:  remote.Url,
+		Auth: remote.Auth,
+	}
+	if dependency.GitReference != "" {
+		cloneOptions.ReferenceName = dependency.GitReference
+		cloneOptions.SingleBranch = true
+	}
+	repo, err := git.PlainClone(installPath, false, cloneOptions)
+	if err != nil {
+		return err
+	}
+
+	// the commit may be abbreviated - resolve it
+	hash, err := repo.ResolveRevision(plumbing.Revision(dependency.Commit))
+	if err != nil {
+		return sperr.WrapWithMessage(err, "could not find commit %s of %s", dependency.Commit, dependency.Name)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Hash: *hash}); err != nil {
+		return err
+	}
+	// record the full commit sha in the lock file
+	dependency.Commit = hash.String()
+	return nil
+}
+
 // build the path of the temp location to copy this depednency to
 func (i *ModInstaller) getDependencyDestPath(dependencyFullName string) string {
 	return filepath.Join(i.modsPath, dependencyFullName)
@@ -142,7 +142,14 @@ func (i *ModInstaller) calculateChangeSet(oldRequire *modconfig.Require, newRequ
 // creates a new "mod" block which can be written as part of the "require" block in mod.sp
 func (i *ModInstaller) createNewModRequireBlock(modVersion *modconfig.ModVersionConstraint) *hclwrite.Block {
 	modRequireBlock := hclwrite.NewBlock("mod", []string{modVersion.Name})
-	modRequireBlock.Body().SetAttributeValue("version", cty.StringVal(modVersion.VersionString))
+	switch {
+	case modVersion.Branch != "":
+		modRequireBlock.Body().SetAttributeValue("branch", cty.StringVal(modVersion.Branch))
+	case modVersion.Commit != "":
+		modRequireBlock.Body().SetAttributeValue("commit", cty.StringVal(modVersion.Commit))
+	default:
+		modRequireBlock.Body().SetAttributeValue("version", cty.StringVal(modVersion.VersionString))
+	}
 	return modRequireBlock
 }
 
@@ -201,6 +208,21 @@ func (i *ModInstaller) calcChangesForUpdate(oldRequire *modconfig.Require, newRe
 		if modInUpdated == nil {
 			continue
 		}
+		if modInUpdated.Equals(requiredMod) {
+			continue
+		}
+		if requiredMod.HasGitRef() || modInUpdated.HasGitRef() || requiredMod.VersionRange.Empty() {
+			// the requirement has changed to or from a branch or commit - replace the whole mod block
+			f := hclwrite.NewEmptyFile()
+			f.Body().AppendBlock(i.createNewModRequireBlock(modInUpdated))
+			changes = append(changes, &Change{
+				Operation:   Replace,
+				OffsetStart: requiredMod.DefRange.Start.Byte,
+				OffsetEnd:   requiredMod.BodyRange.End.Byte,
+				Content:     bytes.TrimSpace(f.Bytes()),
+			})
+			continue
+		}
 		if modInUpdated.VersionString != requiredMod.VersionString {
 			changes = append(changes, &Change{
 				Operation:   Replace,
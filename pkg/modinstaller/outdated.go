@@ -0,0 +1,118 @@
+package modinstaller
+
+import (
+	"sort"
+
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/versionmap"
+	"github.com/turbot/steampipe/pkg/versionhelpers"
+	"golang.org/x/exp/maps"
+)
+
+// the number of characters of a commit sha to display
+const shortCommitLength = 12
+
+// OutdatedDependency describes the installed, wanted and latest versions of an installed mod dependency
+// for dependencies which track a git branch, the versions are the installed commit and the head of the branch
+type OutdatedDependency struct {
+	Name  string `json:"name"`
+	Alias string `json:"alias,omitempty"`
+	// the install cache key of the mod which requires this dependency
+	Parent     string `json:"parent"`
+	Constraint string `json:"constraint,omitempty"`
+	Branch     string `json:"branch,omitempty"`
+	Commit     string `json:"commit,omitempty"`
+	Current    string `json:"current"`
+	// the most recent version which satisfies the constraint
+	Wanted string `json:"wanted"`
+	// the most recent version available
+	Latest string `json:"latest"`
+}
+
+// IsOutdated returns whether a newer version is available
+func (d *OutdatedDependency) IsOutdated() bool {
+	return d.Current != d.Wanted || d.Current != d.Latest
+}
+
+// GetOutdatedDependencies returns the current, wanted and latest versions of all installed dependencies,
+// ordered by parent then dependency name
+func (i *ModInstaller) GetOutdatedDependencies() ([]*OutdatedDependency, error) {
+	installCache := i.installData.Lock.InstallCache
+	parents := maps.Keys(installCache)
+	sort.Strings(parents)
+
+	var res []*OutdatedDependency
+	for _, parent := range parents {
+		deps := installCache[parent]
+		depNames := maps.Keys(deps)
+		sort.Strings(depNames)
+		for _, name := range depNames {
+			outdated, err := i.getOutdatedDependency(deps[name], parent)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, outdated)
+		}
+	}
+	return res, nil
+}
+
+func (i *ModInstaller) getOutdatedDependency(dep *versionmap.ResolvedVersionConstraint, parent string) (*OutdatedDependency, error) {
+	res := &OutdatedDependency{
+		Name:       dep.Name,
+		Alias:      dep.Alias,
+		Parent:     parent,
+		Constraint: dep.Constraint,
+		Branch:     dep.Branch,
+		Commit:     dep.Commit,
+	}
+
+	// dependencies tracking a branch are compared with the head of the branch
+	// - dependencies pinned to a commit are never updated
+	if dep.IsGitRef() {
+		res.Current = shortCommit(dep.Commit)
+		res.Wanted = res.Current
+		if dep.Branch != "" {
+			head, err := i.getBranchHead(&modconfig.ModVersionConstraint{Name: dep.Name, Branch: dep.Branch})
+			if err != nil {
+				return nil, err
+			}
+			res.Wanted = shortCommit(head)
+		}
+		res.Latest = res.Wanted
+		return res, nil
+	}
+
+	res.Current = dep.Version.String()
+	constraintString := dep.Constraint
+	if constraintString == "" {
+		constraintString = "*"
+	}
+	constraint, err := versionhelpers.NewConstraint(constraintString)
+	if err != nil {
+		return nil, err
+	}
+	includePrerelease := constraint.IsPrerelease() || dep.IsPrerelease()
+	availableVersions, err := i.installData.getAvailableModVersions(dep.Name, includePrerelease)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Wanted = res.Current
+	if wanted := getVersionSatisfyingConstraint(constraint, availableVersions); wanted != nil && wanted.GreaterThan(dep.Version) {
+		res.Wanted = wanted.String()
+	}
+	// available versions are sorted in reverse order
+	res.Latest = res.Wanted
+	if len(availableVersions) > 0 && availableVersions[0].GreaterThan(dep.Version) {
+		res.Latest = availableVersions[0].String()
+	}
+	return res, nil
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > shortCommitLength {
+		return commit[:shortCommitLength]
+	}
+	return commit
+}
@@ -0,0 +1,66 @@
+package modinstaller
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/versionmap"
+)
+
+type outdatedTest struct {
+	current    string
+	constraint string
+	expected   OutdatedDependency
+}
+
+func TestGetOutdatedDependencies(t *testing.T) {
+	available := versionmap.VersionListMap{
+		"github.com/turbot/mod1": {semver.MustParse("2.1.0"), semver.MustParse("1.3.0"), semver.MustParse("1.2.0")},
+	}
+
+	tests := map[string]outdatedTest{
+		"up to date": {
+			current:    "2.1.0",
+			constraint: "*",
+			expected:   OutdatedDependency{Current: "2.1.0", Wanted: "2.1.0", Latest: "2.1.0"},
+		},
+		"wanted and latest": {
+			current:    "1.2.0",
+			constraint: "^1.0",
+			expected:   OutdatedDependency{Current: "1.2.0", Wanted: "1.3.0", Latest: "2.1.0"},
+		},
+		"constraint prevents update": {
+			current:    "1.2.0",
+			constraint: "1.2.0",
+			expected:   OutdatedDependency{Current: "1.2.0", Wanted: "1.2.0", Latest: "2.1.0"},
+		},
+	}
+	for name, test := range tests {
+		installCache := make(versionmap.DependencyVersionMap)
+		installCache.Add("github.com/turbot/mod1", "mod1", semver.MustParse(test.current), test.constraint, "local")
+		installer := &ModInstaller{
+			installData: &InstallData{
+				Lock:         &versionmap.WorkspaceLock{InstallCache: installCache},
+				allAvailable: available,
+			},
+		}
+		res, err := installer.GetOutdatedDependencies()
+		if err != nil {
+			t.Errorf("Test: '%s' FAILED : unexpected error %s", name, err.Error())
+			continue
+		}
+		if len(res) != 1 {
+			t.Errorf("Test: '%s' FAILED : expected 1 dependency, got %d", name, len(res))
+			continue
+		}
+		actual := res[0]
+		if actual.Current != test.expected.Current || actual.Wanted != test.expected.Wanted || actual.Latest != test.expected.Latest {
+			t.Errorf("Test: '%s' FAILED : expected %s/%s/%s, got %s/%s/%s", name,
+				test.expected.Current, test.expected.Wanted, test.expected.Latest,
+				actual.Current, actual.Wanted, actual.Latest)
+		}
+		if actual.IsOutdated() != (test.expected.Current != test.expected.Latest) {
+			t.Errorf("Test: '%s' FAILED : unexpected IsOutdated %v", name, actual.IsOutdated())
+		}
+	}
+}
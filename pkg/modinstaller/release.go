@@ -0,0 +1,193 @@
+package modinstaller
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+)
+
+// the version increments supported by NextReleaseVersion
+const (
+	ReleaseBumpMajor = "major"
+	ReleaseBumpMinor = "minor"
+	ReleaseBumpPatch = "patch"
+)
+
+// ModRelease is a release of the workspace mod - an annotated tag of the HEAD commit of the workspace repository
+type ModRelease struct {
+	// the most recent existing release version (nil if there are no releases)
+	PreviousVersion *semver.Version
+	Version         *semver.Version
+	Tag             string
+	Commit          plumbing.Hash
+
+	repo *git.Repository
+}
+
+// NewModRelease determines the next release of the mod in the given workspace from the existing version tags
+// bump is one of 'major', 'minor' or 'patch' - if empty, the patch version is bumped,
+// unless continuing a prerelease series (see NextReleaseVersion)
+// unless force is set, the worktree must be clean
+func NewModRelease(workspacePath, bump, prerelease string, force bool) (*ModRelease, error) {
+	repo, err := git.PlainOpenWithOptions(workspacePath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "mod location '%s' is not a git repository", workspacePath)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to get the HEAD commit - is there at least one commit?")
+	}
+
+	if !force {
+		worktree, err := repo.Worktree()
+		if err != nil {
+			return nil, err
+		}
+		status, err := worktree.Status()
+		if err != nil {
+			return nil, err
+		}
+		if !status.IsClean() {
+			return nil, fmt.Errorf("the git worktree has uncommitted changes - commit them before releasing, or use --force")
+		}
+	}
+
+	previousVersion, err := getLatestTagVersion(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := NextReleaseVersion(previousVersion, bump, prerelease)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ModRelease{
+		PreviousVersion: previousVersion,
+		Version:         version,
+		Tag:             "v" + version.String(),
+		Commit:          head.Hash(),
+		repo:            repo,
+	}
+	if _, err := repo.Tag(r.Tag); err == nil {
+		return nil, fmt.Errorf("tag %s already exists", r.Tag)
+	}
+	return r, nil
+}
+
+// CreateTag creates the annotated release tag on the HEAD commit
+// the tagger is taken from the git config
+func (r *ModRelease) CreateTag(message string) error {
+	if message == "" {
+		message = fmt.Sprintf("Release %s", r.Tag)
+	}
+	_, err := r.repo.CreateTag(r.Tag, r.Commit, &git.CreateTagOptions{Message: message})
+	if errors.Is(err, git.ErrMissingTagger) {
+		return fmt.Errorf("cannot create tag %s - set user.name and user.email in the git config", r.Tag)
+	}
+	return err
+}
+
+// NextReleaseVersion returns the version following current (which may be nil if there are no releases)
+//
+// bump is one of 'major', 'minor' or 'patch'
+// if current is a prerelease of the bumped version, the bump releases that version, e.g. 1.3.0-rc.2 -> 1.3.0 for a minor bump
+// if prerelease is set, the release is the first prerelease of the incremented version, e.g. 1.2.3 -> 1.3.0-rc.1
+// or 1.3.0-rc.2 -> 1.4.0-rc.1 for a minor bump, unless current is already a prerelease with the same identifier
+// and no bump is specified, in which case the prerelease number is incremented, e.g. 1.3.0-rc.1 -> 1.3.0-rc.2
+func NextReleaseVersion(current *semver.Version, bump, prerelease string) (*semver.Version, error) {
+	if current == nil {
+		current = semver.MustParse("0.0.0")
+	}
+
+	// continue a prerelease series
+	if prerelease != "" && bump == "" {
+		if n, ok := prereleaseNumber(current, prerelease); ok {
+			next, err := current.SetPrerelease(fmt.Sprintf("%s.%d", prerelease, n+1))
+			return &next, err
+		}
+	}
+
+	// if the current version is a prerelease of the bumped version, bumping releases that version
+	if prerelease == "" && isPrereleaseOfBump(current, bump) {
+		return semver.New(current.Major(), current.Minor(), current.Patch(), "", ""), nil
+	}
+
+	var next semver.Version
+	switch bump {
+	case ReleaseBumpMajor:
+		next = current.IncMajor()
+	case ReleaseBumpMinor:
+		next = current.IncMinor()
+	case ReleaseBumpPatch, "":
+		next = current.IncPatch()
+	default:
+		return nil, fmt.Errorf("invalid version bump '%s' - must be one of: %s, %s, %s", bump, ReleaseBumpMajor, ReleaseBumpMinor, ReleaseBumpPatch)
+	}
+
+	if prerelease != "" {
+		var err error
+		next, err = next.SetPrerelease(prerelease + ".1")
+		if err != nil {
+			return nil, sperr.WrapWithMessage(err, "invalid prerelease identifier '%s'", prerelease)
+		}
+	}
+	return &next, nil
+}
+
+// isPrereleaseOfBump returns whether the version is a prerelease of the version it would be bumped to,
+// i.e. a prerelease of a patch, minor or major version for a patch, minor or major bump respectively
+func isPrereleaseOfBump(v *semver.Version, bump string) bool {
+	if v.Prerelease() == "" {
+		return false
+	}
+	switch bump {
+	case ReleaseBumpMajor:
+		return v.Minor() == 0 && v.Patch() == 0
+	case ReleaseBumpMinor:
+		return v.Patch() == 0
+	default:
+		return true
+	}
+}
+
+// prereleaseNumber returns N if the version has the prerelease <identifier>.N
+func prereleaseNumber(version *semver.Version, identifier string) (int, bool) {
+	numberString, ok := strings.CutPrefix(version.Prerelease(), identifier+".")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(numberString)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// getLatestTagVersion returns the highest version of the semver tags of the repository, or nil if there are none
+func getLatestTagVersion(repo *git.Repository) (*semver.Version, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	var latest *semver.Version
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		v, err := semver.NewVersion(ref.Name().Short())
+		if err != nil {
+			// not a version tag
+			return nil
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+		}
+		return nil
+	})
+	return latest, err
+}
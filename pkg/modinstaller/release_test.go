@@ -0,0 +1,103 @@
+package modinstaller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+type nextReleaseVersionTest struct {
+	current     string
+	bump        string
+	prerelease  string
+	expected    string
+	expectError bool
+}
+
+func TestNextReleaseVersion(t *testing.T) {
+	tests := map[string]nextReleaseVersionTest{
+		"first release":                  {current: "", expected: "0.0.1"},
+		"default patch":                  {current: "1.2.3", expected: "1.2.4"},
+		"minor":                          {current: "1.2.3", bump: "minor", expected: "1.3.0"},
+		"major":                          {current: "1.2.3", bump: "major", expected: "2.0.0"},
+		"first prerelease":               {current: "1.2.3", bump: "major", prerelease: "rc", expected: "2.0.0-rc.1"},
+		"continue prerelease":            {current: "2.0.0-rc.1", prerelease: "rc", expected: "2.0.0-rc.2"},
+		"new prerelease identifier":      {current: "2.0.0-beta.3", prerelease: "rc", expected: "2.0.0-rc.1"},
+		"release prerelease":             {current: "2.0.0-rc.2", expected: "2.0.0"},
+		"invalid bump":                   {current: "1.2.3", bump: "huge", expectError: true},
+		"invalid prerelease":             {current: "1.2.3", prerelease: "rc!", expectError: true},
+		"bump with prerelease reset":     {current: "2.0.0-rc.2", bump: "minor", prerelease: "rc", expected: "2.1.0-rc.1"},
+		"minor releases prerelease":      {current: "1.3.0-rc.1", bump: "minor", expected: "1.3.0"},
+		"major releases prerelease":      {current: "2.0.0-rc.1", bump: "major", expected: "2.0.0"},
+		"major of minor prerelease":      {current: "1.3.0-rc.1", bump: "major", expected: "2.0.0"},
+		"minor of patch prerelease":      {current: "1.3.1-rc.1", bump: "minor", expected: "1.4.0"},
+		"minor prerelease of prerelease": {current: "1.3.0-rc.1", bump: "minor", prerelease: "rc", expected: "1.4.0-rc.1"},
+	}
+	for name, test := range tests {
+		var current *semver.Version
+		if test.current != "" {
+			current = semver.MustParse(test.current)
+		}
+		next, err := NextReleaseVersion(current, test.bump, test.prerelease)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("Test: '%s' FAILED : expected error, got %s", name, next)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test: '%s' FAILED : unexpected error %s", name, err.Error())
+			continue
+		}
+		if next.String() != test.expected {
+			t.Errorf("Test: '%s' FAILED : expected %s, got %s", name, test.expected, next.String())
+		}
+	}
+}
+
+func TestModRelease(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mod.sp"), []byte(`mod "m1" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree, _ := repo.Worktree()
+	if _, err := worktree.Add("mod.sp"); err != nil {
+		t.Fatal(err)
+	}
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	commit, err := worktree.Commit("initial", &git.CommitOptions{Author: signature})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateTag("v1.0.0", commit, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	release, err := NewModRelease(dir, ReleaseBumpMinor, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.Tag != "v1.1.0" || release.PreviousVersion.String() != "1.0.0" || release.Commit != commit {
+		t.Errorf("unexpected release %s of %s (previous %s)", release.Tag, release.Commit, release.PreviousVersion)
+	}
+
+	// an uncommitted change prevents the release, unless forced
+	if err := os.WriteFile(filepath.Join(dir, "mod.sp"), []byte(`mod "m2" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewModRelease(dir, ReleaseBumpMinor, "", false); err == nil {
+		t.Errorf("expected an error releasing a modified worktree")
+	}
+	if _, err := NewModRelease(dir, ReleaseBumpMinor, "", true); err != nil {
+		t.Errorf("unexpected error for forced release: %s", err.Error())
+	}
+}
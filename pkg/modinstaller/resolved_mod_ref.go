@@ -1,6 +1,9 @@
 package modinstaller
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
@@ -17,6 +20,11 @@ type ResolvedModRef struct {
 	Constraint *versionhelpers.Constraints
 	// the Git branch/tag
 	GitReference plumbing.ReferenceName
+	// for mods pinned to a git branch or commit, the required branch and the commit to check out
+	Branch string
+	Commit string
+	// the content hash of the installed mod
+	Integrity string
 	// the file path for local mods
 	FilePath string
 }
@@ -36,13 +44,45 @@ func NewResolvedModRef(requiredModVersion *modconfig.ModVersionConstraint, versi
 	return res, nil
 }
 
-func (r *ResolvedModRef) setGitReference() {
-	// TODO handle branches
+// NewGitRefResolvedModRef creates a ResolvedModRef for a mod pinned to a git branch or commit
+// the version is a pseudo version derived from the commit, so each commit is installed to its own folder
+func NewGitRefResolvedModRef(requiredModVersion *modconfig.ModVersionConstraint, commit string) *ResolvedModRef {
+	res := &ResolvedModRef{
+		Name:   requiredModVersion.Name,
+		Branch: requiredModVersion.Branch,
+		Commit: commit,
+	}
+	res.Version = gitRefVersion(commit)
+	res.setGitReference()
+	return res
+}
 
+func (r *ResolvedModRef) setGitReference() {
+	if r.Branch != "" {
+		r.GitReference = plumbing.NewBranchReferenceName(r.Branch)
+		return
+	}
+	if r.Commit != "" {
+		// a commit is checked out after cloning - there is no reference to clone
+		return
+	}
 	// NOTE: use the original version string - this will be the tag name
 	r.GitReference = plumbing.NewTagReferenceName(r.Version.Original())
 }
 
+// IsGitRef returns whether the mod is pinned to a git branch or commit
+func (r *ResolvedModRef) IsGitRef() bool {
+	return r.Commit != ""
+}
+
+// gitRefVersion returns the pseudo version for a git commit, e.g. 0.0.0-commit-1a2b3c4d5e6f
+func gitRefVersion(commit string) *semver.Version {
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	return semver.MustParse(fmt.Sprintf("0.0.0-commit-%s", strings.ToLower(commit)))
+}
+
 // DependencyPath returns name in the format <dependency name>@v<dependencyVersion>
 func (r *ResolvedModRef) DependencyPath() string {
 	return modconfig.BuildModDependencyPath(r.Name, r.Version)
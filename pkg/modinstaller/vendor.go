@@ -0,0 +1,79 @@
+package modinstaller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/otiai10/copy"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/versionmap"
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+// VendorWorkspaceDependencies packages all dependencies in the workspace lock, together with the lock itself,
+// into a mod mirror at dest, which may then be passed to 'steampipe mod install --from'
+// if dest has a .tar.gz or .tgz extension a gzipped tarball is written, otherwise a directory
+// returns the number of mods vendored
+func VendorWorkspaceDependencies(workspacePath, dest string) (int, error) {
+	lock, err := versionmap.LoadWorkspaceLock(workspacePath)
+	if err != nil {
+		return 0, err
+	}
+	if lock.Empty() {
+		return 0, fmt.Errorf("there are no mod dependencies to vendor")
+	}
+	if lock.Incomplete() {
+		return 0, fmt.Errorf("not all dependencies are installed - run 'steampipe mod install'")
+	}
+
+	// write to a temp directory if we are creating a tarball
+	vendorDir := dest
+	if utils.IsGzippedTarball(dest) {
+		vendorDir, err = os.MkdirTemp("", "steampipe-mod-vendor")
+		if err != nil {
+			return 0, err
+		}
+		defer os.RemoveAll(vendorDir)
+	} else if err := os.MkdirAll(vendorDir, 0755); err != nil {
+		return 0, err
+	}
+
+	deps := lock.InstallCache.FlatMap()
+	for dependencyPath := range deps {
+		source := filepath.Join(lock.ModInstallationPath, dependencyPath)
+		target := filepath.Join(vendorDir, filepaths.WorkspaceModDir, dependencyPath)
+		if err := copy.Copy(source, target, copy.Options{Skip: skipInstallMetadata}); err != nil {
+			return 0, sperr.WrapWithMessage(err, "failed to vendor '%s'", dependencyPath)
+		}
+	}
+
+	// write the lock, so that mods pinned to a branch are installed at the vendored commit
+	lockData, err := os.ReadFile(filepaths.WorkspaceLockPath(workspacePath))
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(filepaths.WorkspaceLockPath(vendorDir), lockData, 0644); err != nil {
+		return 0, err
+	}
+
+	if utils.IsGzippedTarball(dest) {
+		if filehelpers.DirectoryExists(dest) {
+			return 0, fmt.Errorf("'%s' is a directory", dest)
+		}
+		if err := utils.WriteGzippedTarball(vendorDir, dest); err != nil {
+			return 0, sperr.WrapWithMessage(err, "failed to write '%s'", dest)
+		}
+	}
+	return len(deps), nil
+}
+
+// skipInstallMetadata excludes the git metadata and integrity cache of installed mods from the vendored copy
+func skipInstallMetadata(info os.FileInfo, src, _ string) (bool, error) {
+	if info.IsDir() {
+		return info.Name() == ".git", nil
+	}
+	return info.Name() == versionmap.IntegrityCacheFileName, nil
+}
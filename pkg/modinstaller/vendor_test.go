@@ -0,0 +1,72 @@
+package modinstaller
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/versionmap"
+)
+
+func TestVendorWorkspaceDependencies(t *testing.T) {
+	workspacePath := t.TempDir()
+	modsPath := filepaths.WorkspaceModPath(workspacePath)
+	modPath := filepath.Join(modsPath, "github.com/turbot/mod1@v1.0.0")
+	if err := os.MkdirAll(modPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		filepath.Join(modPath, "mod.sp"):                           `mod "mod1" {}`,
+		filepath.Join(modsPath, versionmap.IntegrityCacheFileName): `{}`,
+		filepaths.WorkspaceLockPath(workspacePath):                 `{"mod.local": {"github.com/turbot/mod1": {"name": "github.com/turbot/mod1", "version": "1.0.0", "constraint": "*"}}}`,
+		filepath.Join(workspacePath, "mod.sp"):                     `mod "local" {}`,
+		filepath.Join(modPath, ".git", "HEAD"):                     "ref: refs/heads/main",
+		filepath.Join(modPath, versionmap.IntegrityCacheFileName):  `{}`,
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dest := filepath.Join(t.TempDir(), "mirror")
+	count, err := VendorWorkspaceDependencies(workspacePath, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 mod to be vendored, got %d", count)
+	}
+
+	// the vendored tree contains the mod and the lock, but no git metadata or integrity cache
+	var files []string
+	err = filepath.WalkDir(dest, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			relPath, _ := filepath.Rel(dest, path)
+			files = append(files, filepath.ToSlash(relPath))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]bool{
+		"mods/github.com/turbot/mod1@v1.0.0/mod.sp": true,
+		".mod.cache.json": true,
+	}
+	if len(files) != len(expected) {
+		t.Errorf("expected vendored files %v, got %v", expected, files)
+	}
+	for _, f := range files {
+		if !expected[f] {
+			t.Errorf("unexpected vendored file %s", f)
+		}
+	}
+}
@@ -1,7 +1,17 @@
 package ociinstaller
 
+import "github.com/turbot/steampipe/pkg/steampipeconfig/options"
+
 type pluginInstallConfig struct {
 	skipConfigFile bool
+	registries     []*options.PluginRegistry
+	// the signature verification policy and public key
+	signaturePolicy    string
+	signaturePublicKey string
+	// if set, the image with this digest is installed rather than the image the ref tag points to
+	digest string
+	// if set, the installed version must satisfy this semver constraint, which is recorded in the plugin lock file
+	constraint string
 }
 
 type PluginInstallOption = func(config *pluginInstallConfig)
@@ -11,3 +21,35 @@ func WithSkipConfig(skipConfigFile bool) PluginInstallOption {
 		o.skipConfigFile = skipConfigFile
 	}
 }
+
+// WithRegistries sets the credential configuration of private plugin registries
+func WithRegistries(registries []*options.PluginRegistry) PluginInstallOption {
+	return func(o *pluginInstallConfig) {
+		o.registries = registries
+	}
+}
+
+// WithSignatureVerification sets the plugin image signature verification policy ('off', 'warn' or 'enforce')
+// and the path of the public key used to verify signatures
+func WithSignatureVerification(policy, publicKeyPath string) PluginInstallOption {
+	return func(o *pluginInstallConfig) {
+		o.signaturePolicy = policy
+		o.signaturePublicKey = publicKeyPath
+	}
+}
+
+// WithImageDigest installs the image with the given digest from the repository of the plugin image ref
+// this is used to reproduce a locked installation
+func WithImageDigest(digest string) PluginInstallOption {
+	return func(o *pluginInstallConfig) {
+		o.digest = digest
+	}
+}
+
+// WithConstraint requires the installed plugin version to satisfy the semver constraint (e.g. '~0.2')
+// and records the constraint in the plugin lock file, so 'plugin update' respects it
+func WithConstraint(constraint string) PluginInstallOption {
+	return func(o *pluginInstallConfig) {
+		o.constraint = constraint
+	}
+}
@@ -0,0 +1,70 @@
+package ociinstaller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/turbot/steampipe/pkg/utils"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+// IsLocalImageLayout returns whether the given plugin install arg is a path to a local OCI image layout,
+// either a layout directory or a tar archive (optionally gzipped) of one
+func IsLocalImageLayout(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		return fileExists(filepath.Join(path, ocispec.ImageLayoutFile))
+	}
+	return isTarArchive(path) || utils.IsGzippedTarball(path)
+}
+
+func isTarArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar")
+}
+
+// openImageLayout opens the OCI image layout at the given path and returns the store
+// and the tag of the single image it contains
+// gzipped archives are decompressed into workDir
+func openImageLayout(ctx context.Context, path, workDir string) (*oci.ReadOnlyStore, string, error) {
+	var store *oci.ReadOnlyStore
+	var err error
+	switch {
+	case utils.IsGzippedTarball(path):
+		tarPath := filepath.Join(workDir, "image-layout.tar")
+		if err = utils.Gunzip(path, tarPath); err != nil {
+			return nil, "", fmt.Errorf("could not decompress %s: %s", path, err)
+		}
+		store, err = oci.NewFromTar(ctx, tarPath)
+	case isTarArchive(path):
+		store, err = oci.NewFromTar(ctx, path)
+	default:
+		store, err = oci.NewFromFS(ctx, os.DirFS(path))
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("%s is not a valid OCI image layout: %s", path, err)
+	}
+
+	var tags []string
+	if err := store.Tags(ctx, "", func(t []string) error {
+		for _, tag := range t {
+			// the layout may also contain the image signature
+			if !isSignatureTag(tag) {
+				tags = append(tags, tag)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, "", err
+	}
+	if len(tags) != 1 {
+		return nil, "", fmt.Errorf("OCI image layout %s should contain 1 tagged image, found %d", path, len(tags))
+	}
+	return store, tags[0], nil
+}
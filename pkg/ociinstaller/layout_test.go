@@ -0,0 +1,116 @@
+package ociinstaller
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+// writeTestPluginLayout writes an OCI image layout containing a plugin image to dir
+// and returns the layout store and the image manifest descriptor
+func writeTestPluginLayout(t *testing.T, dir string) (*oci.Store, ocispec.Descriptor) {
+	t.Helper()
+	ctx := context.Background()
+	store, err := oci.New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaTypes, err := MediaTypeForPlatform(ImageTypePlugin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary, err := oras.PushBytes(ctx, store, mediaTypes[0], []byte("binary"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary.Annotations = map[string]string{ocispec.AnnotationTitle: "steampipe-plugin-foo.plugin.gz"}
+	config, err := oras.PushBytes(ctx, store, MediaTypePluginConfig, []byte(`{"plugin":{"name":"foo","organization":"acme","version":"1.0.0"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_0, "", oras.PackManifestOptions{
+		ConfigDescriptor: &config,
+		Layers:           []ocispec.Descriptor{binary},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Tag(ctx, manifest, "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	return store, manifest
+}
+
+// writeTestTarGz writes a gzipped tar archive of the contents of dir to path
+func writeTestTarGz(t *testing.T, dir, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	defer gz.Close()
+	tw := tar.NewWriter(gz)
+	defer tw.Close()
+
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: filepath.ToSlash(rel), Mode: 0644, Size: int64(len(data))}); err != nil {
+			return err
+		}
+		_, err = tw.Write(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDownloadPluginFromLayout(t *testing.T) {
+	layoutDir := t.TempDir()
+	writeTestPluginLayout(t, layoutDir)
+	archivePath := filepath.Join(t.TempDir(), "steampipe-plugin-foo.tar.gz")
+	writeTestTarGz(t, layoutDir, archivePath)
+
+	for name, layoutPath := range map[string]string{"directory": layoutDir, "archive": archivePath} {
+		if !IsLocalImageLayout(layoutPath) {
+			t.Errorf("Test: '%s' FAILED : expected %s to be a local image layout", name, layoutPath)
+			continue
+		}
+		destDir := t.TempDir()
+		image, err := NewOciDownloader().DownloadPluginFromLayout(context.Background(), layoutPath, destDir, t.TempDir())
+		if err != nil {
+			t.Errorf("Test: '%s' FAILED : unexpected error %s", name, err.Error())
+			continue
+		}
+		if ref := image.ImageRef.DisplayImageRef(); ref != "hub.steampipe.io/plugins/acme/foo@latest" {
+			t.Errorf("Test: '%s' FAILED : unexpected image ref %s", name, ref)
+		}
+		if image.Config.Plugin.Version != "1.0.0" {
+			t.Errorf("Test: '%s' FAILED : unexpected version %s", name, image.Config.Plugin.Version)
+		}
+		if !fileExists(filepath.Join(destDir, image.Plugin.BinaryFile)) {
+			t.Errorf("Test: '%s' FAILED : plugin binary %s was not copied", name, image.Plugin.BinaryFile)
+		}
+	}
+
+	if IsLocalImageLayout(filepath.Join(layoutDir, "does-not-exist.tar.gz")) || IsLocalImageLayout("aws") {
+		t.Errorf("expected plugin names and missing files not to be local image layouts")
+	}
+}
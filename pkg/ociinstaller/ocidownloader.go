@@ -9,8 +9,8 @@ import (
 	"github.com/containerd/containerd/remotes"
 	"github.com/containerd/containerd/remotes/docker"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
-	credentials "github.com/oras-project/oras-credentials-go"
 	"github.com/sirupsen/logrus"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/options"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/file"
@@ -21,8 +21,13 @@ import (
 )
 
 type ociDownloader struct {
-	resolver remotes.Resolver
-	Images   []*SteampipeImage
+	resolver   remotes.Resolver
+	Images     []*SteampipeImage
+	registries []*options.PluginRegistry
+	// if set, the signatures of plugin images are verified
+	signatureVerifier *signatureVerifier
+	// if set, Download pulls the image with this digest rather than the ref tag
+	digest string
 }
 
 // NewOciDownloader creates and returns a ociDownloader instance
@@ -43,45 +48,60 @@ Returns
 	imageDescription, configDescription, config, imageLayers, error
 */
 func (o *ociDownloader) Pull(ctx context.Context, ref string, mediaTypes []string, destDir string) (*ocispec.Descriptor, *ocispec.Descriptor, []byte, []ocispec.Descriptor, error) {
-	split := strings.Split(ref, ":")
-	tag := split[len(split)-1]
-	log.Println("[TRACE] ociDownloader.Pull:", "preparing to pull ref", ref, "tag", tag, "destDir", destDir)
+	log.Println("[TRACE] ociDownloader.Pull:", "preparing to pull ref", ref, "destDir", destDir)
 
-	// Create the target file store
-	memoryStore := memory.New()
-	fileStore, err := file.NewWithFallbackStorage(destDir, memoryStore)
+	repo, tag, err := o.newRemoteRepository(ref)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
-	defer fileStore.Close()
+
+	return o.pullFrom(ctx, repo, tag, tag, destDir)
+}
+
+// newRemoteRepository connects to the remote repository of the given ref and returns the repository and the ref tag
+func (o *ociDownloader) newRemoteRepository(ref string) (*remote.Repository, string, error) {
+	split := strings.Split(ref, ":")
+	tag := split[len(split)-1]
 
 	// Connect to the remote repository
 	repo, err := remote.NewRepository(ref)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return nil, "", err
 	}
 
-	// Get credentials from the docker credentials store
-	storeOpts := credentials.StoreOptions{}
-	credStore, err := credentials.NewStoreFromDocker(storeOpts)
+	// Get credentials from the configured registry credentials, falling back to the docker credentials store
+	credential, err := registryCredential(o.registries)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return nil, "", err
 	}
 
 	// Prepare the auth client for the registry and credential store
 	repo.Client = &auth.Client{
 		Client:     retry.DefaultClient,
 		Cache:      auth.DefaultCache,
-		Credential: credentials.Credential(credStore), // Use the credential store
+		Credential: credential,
 	}
+	return repo, tag, nil
+}
+
+// pullFrom copies the image with the given ref from the source target (a remote repository or a local OCI layout)
+// to the supplied `destDir`, tagging it with destRef
+func (o *ociDownloader) pullFrom(ctx context.Context, source oras.ReadOnlyTarget, sourceRef, destRef string, destDir string) (*ocispec.Descriptor, *ocispec.Descriptor, []byte, []ocispec.Descriptor, error) {
+	// Create the target file store
+	memoryStore := memory.New()
+	fileStore, err := file.NewWithFallbackStorage(destDir, memoryStore)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	defer fileStore.Close()
 
-	// Copy from the remote repository to the file store
+	// Copy from the source to the file store
 	log.Println("[TRACE] ociDownloader.Pull:", "pulling...")
 
 	copyOpt := oras.DefaultCopyOptions
-	manifestDescriptor, err := oras.Copy(ctx, repo, tag, fileStore, tag, copyOpt)
+	manifestDescriptor, err := oras.Copy(ctx, source, sourceRef, fileStore, destRef, copyOpt)
 	if err != nil {
-		log.Println("[TRACE] ociDownloader.Pull:", "failed to pull", ref, err)
+		log.Println("[TRACE] ociDownloader.Pull:", "failed to pull", sourceRef, err)
 		return nil, nil, nil, nil, err
 	}
 	log.Println("[TRACE] ociDownloader.Pull:", "manifest", manifestDescriptor.Digest, manifestDescriptor.MediaType)
@@ -22,7 +22,8 @@ import (
 var versionFileUpdateLock = &sync.Mutex{}
 
 // InstallPlugin installs a plugin from an OCI Image
-func InstallPlugin(ctx context.Context, imageRef string, sub chan struct{}, opts ...PluginInstallOption) (*SteampipeImage, error) {
+// imageRef is either a registry image ref or the path of a local OCI image layout (see IsLocalImageLayout)
+func InstallPlugin(ctx context.Context, imageRef string, sub chan struct{}, opts ...PluginInstallOption) (_ *SteampipeImage, err error) {
 	config := &pluginInstallConfig{}
 	for _, opt := range opts {
 		opt(config)
@@ -36,14 +37,50 @@ func InstallPlugin(ctx context.Context, imageRef string, sub chan struct{}, opts
 		}
 	}()
 
-	ref := NewSteampipeImageRef(imageRef)
 	imageDownloader := NewOciDownloader()
+	imageDownloader.registries = config.registries
+	imageDownloader.digest = config.digest
+	signatureVerifier, err := newSignatureVerifier(config.signaturePolicy, config.signaturePublicKey)
+	if err != nil {
+		return nil, err
+	}
+	imageDownloader.signatureVerifier = signatureVerifier
 
 	sub <- struct{}{}
-	image, err := imageDownloader.Download(ctx, ref, ImageTypePlugin, tempDir.Path)
+	var image *SteampipeImage
+	if IsLocalImageLayout(imageRef) {
+		workDir := NewTempDir(filepaths.EnsurePluginDir())
+		defer workDir.Delete()
+		image, err = imageDownloader.DownloadPluginFromLayout(ctx, imageRef, tempDir.Path, workDir.Path)
+	} else {
+		image, err = imageDownloader.Download(ctx, NewSteampipeImageRef(imageRef), ImageTypePlugin, tempDir.Path)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if config.constraint != "" {
+		allowed, err := versionfile.ConstraintAllows(config.constraint, image.Config.Plugin.Version)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, fmt.Errorf("plugin version %s does not satisfy the constraint '%s'", image.Config.Plugin.Version, config.constraint)
+		}
+	}
+
+	// keep the currently installed version so it can be restored using 'plugin rollback'
+	backedUp, err := backupInstalledPlugin(image)
+	if err != nil {
+		return nil, fmt.Errorf("plugin installation failed: %s", err)
+	}
+	defer func() {
+		// if the installation failed, restore the previous version
+		if err != nil && backedUp {
+			if restoreErr := restoreInstalledPlugin(image.ImageRef); restoreErr != nil {
+				log.Printf("[WARN] failed to restore previous installation of %s: %s", image.ImageRef.DisplayImageRef(), restoreErr)
+			}
+		}
+	}()
 
 	sub <- struct{}{}
 	if err = installPluginBinary(image, tempDir.Path); err != nil {
@@ -59,15 +96,21 @@ func InstallPlugin(ctx context.Context, imageRef string, sub chan struct{}, opts
 		}
 	}
 	sub <- struct{}{}
-	if err := updatePluginVersionFiles(image); err != nil {
+	if err = updatePluginVersionFiles(image, config.constraint); err != nil {
 		return nil, err
 	}
+	// the installation succeeded - the version it replaced is now the one restored by 'plugin rollback'
+	if backedUp {
+		if commitErr := commitPluginBackup(image.ImageRef); commitErr != nil {
+			log.Printf("[WARN] failed to keep previous installation of %s: %s", image.ImageRef.DisplayImageRef(), commitErr)
+		}
+	}
 	return image, nil
 }
 
 // updatePluginVersionFiles updates the global versions.json to add installation of the plugin
 // also adds a version file in the plugin installation directory with the information
-func updatePluginVersionFiles(image *SteampipeImage) error {
+func updatePluginVersionFiles(image *SteampipeImage, constraint string) error {
 	versionFileUpdateLock.Lock()
 	defer versionFileUpdateLock.Unlock()
 
@@ -90,8 +133,14 @@ func updatePluginVersionFiles(image *SteampipeImage) error {
 	installedVersion.BinaryDigest = image.Plugin.BinaryDigest
 	installedVersion.BinaryArchitecture = image.Plugin.BinaryArchitecture
 	installedVersion.InstalledFrom = image.ImageRef.ActualImageRef()
+	if image.LayoutPath != "" {
+		installedVersion.InstalledFrom = image.LayoutPath
+	}
 	installedVersion.LastCheckedDate = timeNow
 	installedVersion.InstallDate = timeNow
+	installedVersion.SignatureStatus = string(image.SignatureStatus)
+	installedVersion.RolledBackFrom = ""
+	installedVersion.RollbackDate = ""
 
 	v.Plugins[pluginFullName] = installedVersion
 
@@ -104,7 +153,17 @@ func updatePluginVersionFiles(image *SteampipeImage) error {
 		return err
 	}
 
-	return v.Save()
+	if err := v.Save(); err != nil {
+		return err
+	}
+
+	// record the installed image in the plugin lock file
+	lockFile, err := versionfile.LoadPluginLockFile()
+	if err != nil {
+		return err
+	}
+	lockFile.Lock(installedVersion, constraint)
+	return lockFile.Save()
 }
 
 func installPluginBinary(image *SteampipeImage, tempdir string) error {
@@ -0,0 +1,160 @@
+package ociinstaller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/filepaths"
+	versionfile "github.com/turbot/steampipe/pkg/ociinstaller/versionfile"
+)
+
+var (
+	// ErrPluginNotInstalled is returned by RollbackPlugin if the plugin is not installed
+	ErrPluginNotInstalled = errors.New("plugin is not installed")
+	// ErrNoRollbackVersion is returned by RollbackPlugin if there is no previous installation of the plugin
+	ErrNoRollbackVersion = errors.New("there is no previous version of the plugin to roll back to")
+)
+
+// RollbackPlugin restores the previous installation of a plugin, which is kept when the plugin is updated
+// The current installation is kept in its place, so the rollback may itself be reverted by rolling back again.
+// Returns the installation data of the restored version
+func RollbackPlugin(imageRef string) (*versionfile.InstalledVersion, error) {
+	ref := NewSteampipeImageRef(imageRef)
+	pluginName := ref.DisplayImageRef()
+	installDir := filepath.Join(filepaths.EnsurePluginDir(), filepath.FromSlash(pluginName))
+	rollbackDir := pluginRollbackDir(ref)
+
+	if !fileExists(installDir) {
+		return nil, ErrPluginNotInstalled
+	}
+	previous, err := versionfile.LoadInstalledVersion(rollbackDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoRollbackVersion
+		}
+		return nil, err
+	}
+
+	versionFileUpdateLock.Lock()
+	defer versionFileUpdateLock.Unlock()
+
+	v, err := versionfile.LoadPluginVersionFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := swapDirectories(installDir, rollbackDir); err != nil {
+		return nil, fmt.Errorf("plugin rollback failed: %s", err)
+	}
+
+	// record the rollback in the version file
+	previous.Name = pluginName
+	previous.RollbackDate = versionfile.FormatTime(time.Now())
+	previous.RolledBackFrom = ""
+	if current, ok := v.Plugins[pluginName]; ok {
+		previous.RolledBackFrom = current.Version
+	}
+	v.Plugins[pluginName] = previous
+	if err := v.EnsurePluginVersionFile(previous); err != nil {
+		return nil, err
+	}
+	if err := v.Save(); err != nil {
+		return nil, err
+	}
+
+	// the restored image is now the locked image
+	lockFile, err := versionfile.LoadPluginLockFile()
+	if err != nil {
+		return nil, err
+	}
+	lockFile.Lock(previous, "")
+	if err := lockFile.Save(); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// pluginRollbackDir returns the directory used to keep the previous installation of the plugin
+func pluginRollbackDir(ref *SteampipeImageRef) string {
+	return filepath.Join(filepaths.EnsurePluginRollbackDir(), filepath.FromSlash(ref.DisplayImageRef()))
+}
+
+// pendingPluginRollbackDir returns the directory used to keep the current installation of the plugin while it is updated
+// - this only replaces the previous backup once the update has succeeded (see commitPluginBackup)
+func pendingPluginRollbackDir(ref *SteampipeImageRef) string {
+	return pluginRollbackDir(ref) + ".pending"
+}
+
+// backupInstalledPlugin moves the current installation of the plugin being installed into the pending rollback directory
+// Returns false if there is nothing to back up, i.e. the plugin is not installed or the image is already installed
+func backupInstalledPlugin(image *SteampipeImage) (bool, error) {
+	pluginName := image.ImageRef.DisplayImageRef()
+	installDir := filepath.Join(filepaths.EnsurePluginDir(), filepath.FromSlash(pluginName))
+
+	// only installations with a version file can be restored
+	installed, err := versionfile.LoadInstalledVersion(installDir)
+	if err != nil {
+		log.Printf("[TRACE] not keeping previous installation of %s: %s", pluginName, err)
+		return false, nil
+	}
+	if installed.ImageDigest == string(image.OCIDescriptor.Digest) {
+		// reinstalling the same image - keep the existing backup
+		return false, nil
+	}
+
+	pendingDir := pendingPluginRollbackDir(image.ImageRef)
+	if err := os.RemoveAll(pendingDir); err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(pendingDir), 0755); err != nil {
+		return false, err
+	}
+	if err := os.Rename(installDir, pendingDir); err != nil {
+		return false, err
+	}
+	log.Printf("[TRACE] moved previous installation of %s (%s) to %s", pluginName, installed.Version, pendingDir)
+	return true, nil
+}
+
+// commitPluginBackup replaces any previous backup of the plugin with the backup made by backupInstalledPlugin
+// this is called once the installation has succeeded
+func commitPluginBackup(ref *SteampipeImageRef) error {
+	rollbackDir := pluginRollbackDir(ref)
+	if err := os.RemoveAll(rollbackDir); err != nil {
+		return err
+	}
+	return os.Rename(pendingPluginRollbackDir(ref), rollbackDir)
+}
+
+// restoreInstalledPlugin replaces the installation of the plugin with the backup made by backupInstalledPlugin
+// this is called if the installation fails - the previous backup is left in place
+func restoreInstalledPlugin(ref *SteampipeImageRef) error {
+	installDir := filepath.Join(filepaths.EnsurePluginDir(), filepath.FromSlash(ref.DisplayImageRef()))
+	if err := os.RemoveAll(installDir); err != nil {
+		return err
+	}
+	return os.Rename(pendingPluginRollbackDir(ref), installDir)
+}
+
+// swapDirectories swaps two directories using renames, so neither is ever partially written
+func swapDirectories(a, b string) error {
+	tmp := b + ".swap"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(a, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(b, a); err != nil {
+		// put a back
+		if restoreErr := os.Rename(tmp, a); restoreErr != nil {
+			log.Printf("[WARN] failed to restore %s: %s", a, restoreErr)
+		}
+		return err
+	}
+	return os.Rename(tmp, b)
+}
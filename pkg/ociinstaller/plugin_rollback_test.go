@@ -0,0 +1,109 @@
+package ociinstaller
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/turbot/steampipe/pkg/filepaths"
+	versionfile "github.com/turbot/steampipe/pkg/ociinstaller/versionfile"
+)
+
+// writeTestInstallation writes a plugin installation directory containing only a version file
+func writeTestInstallation(t *testing.T, ref *SteampipeImageRef, version, imageDigest string) {
+	installDir := filepath.Join(filepaths.EnsurePluginDir(), filepath.FromSlash(ref.DisplayImageRef()))
+	if err := os.MkdirAll(installDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	installation := versionfile.EmptyInstalledVersion()
+	installation.Name = ref.DisplayImageRef()
+	installation.Version = version
+	installation.ImageDigest = imageDigest
+	installation.BinaryDigest = imageDigest
+	data, err := json.Marshal(installation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(installDir, "version.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPluginRollback(t *testing.T) {
+	prevSteampipeDir := filepaths.SteampipeDir
+	filepaths.SteampipeDir = t.TempDir()
+	defer func() { filepaths.SteampipeDir = prevSteampipeDir }()
+
+	ref := NewSteampipeImageRef("aws")
+
+	if _, err := RollbackPlugin("aws"); !errors.Is(err, ErrPluginNotInstalled) {
+		t.Errorf("Test: '%s' FAILED : expected ErrPluginNotInstalled rolling back a plugin which is not installed, got %v", "not installed", err)
+	}
+
+	// install v0.1.0, then 'update' to v0.2.0
+	writeTestInstallation(t, ref, "0.1.0", "sha256:aaaa")
+	if _, err := RollbackPlugin("aws"); !errors.Is(err, ErrNoRollbackVersion) {
+		t.Errorf("Test: '%s' FAILED : expected ErrNoRollbackVersion rolling back a plugin with no previous version, got %v", "no previous version", err)
+	}
+
+	sameImage := &SteampipeImage{ImageRef: ref, OCIDescriptor: &ocispec.Descriptor{Digest: digest.Digest("sha256:aaaa")}}
+	if backedUp, err := backupInstalledPlugin(sameImage); err != nil || backedUp {
+		t.Errorf("Test: '%s' FAILED : expected no backup when reinstalling the same image, got %v, %v", "same image", backedUp, err)
+	}
+
+	newImage := &SteampipeImage{ImageRef: ref, OCIDescriptor: &ocispec.Descriptor{Digest: digest.Digest("sha256:bbbb")}}
+	backedUp, err := backupInstalledPlugin(newImage)
+	if err != nil || !backedUp {
+		t.Fatalf("Test: '%s' FAILED : expected backup of previous installation, got %v, %v", "backup", backedUp, err)
+	}
+	writeTestInstallation(t, ref, "0.2.0", "sha256:bbbb")
+	if err := commitPluginBackup(ref); err != nil {
+		t.Fatalf("Test: '%s' FAILED : %s", "commit backup", err)
+	}
+
+	// a failed installation of v0.3.0 restores v0.2.0 and keeps the backup of v0.1.0
+	failedImage := &SteampipeImage{ImageRef: ref, OCIDescriptor: &ocispec.Descriptor{Digest: digest.Digest("sha256:cccc")}}
+	if backedUp, err := backupInstalledPlugin(failedImage); err != nil || !backedUp {
+		t.Fatalf("Test: '%s' FAILED : expected backup of previous installation, got %v, %v", "failed install", backedUp, err)
+	}
+	if err := restoreInstalledPlugin(ref); err != nil {
+		t.Fatalf("Test: '%s' FAILED : %s", "failed install", err)
+	}
+	installDir := filepath.Join(filepaths.EnsurePluginDir(), filepath.FromSlash(ref.DisplayImageRef()))
+	if installed, err := versionfile.LoadInstalledVersion(installDir); err != nil || installed.Version != "0.2.0" {
+		t.Errorf("Test: '%s' FAILED : expected 0.2.0 to be restored, got %v, %v", "failed install", installed, err)
+	}
+	if backup, err := versionfile.LoadInstalledVersion(pluginRollbackDir(ref)); err != nil || backup.Version != "0.1.0" {
+		t.Errorf("Test: '%s' FAILED : expected the backup of 0.1.0 to be kept, got %v, %v", "failed install", backup, err)
+	}
+
+	// roll back, then roll back again to restore the update
+	tests := []struct {
+		name               string
+		expectedVersion    string
+		expectedRolledBack string
+	}{
+		{"rollback", "0.1.0", "0.2.0"},
+		{"rollback of rollback", "0.2.0", "0.1.0"},
+	}
+	for _, test := range tests {
+		restored, err := RollbackPlugin("aws")
+		if err != nil {
+			t.Fatalf("Test: '%s' FAILED : %s", test.name, err)
+		}
+		if restored.Version != test.expectedVersion || restored.RolledBackFrom != test.expectedRolledBack {
+			t.Errorf("Test: '%s' FAILED : expected %s rolled back from %s, got %s rolled back from %s", test.name, test.expectedVersion, test.expectedRolledBack, restored.Version, restored.RolledBackFrom)
+		}
+		v, err := versionfile.LoadPluginVersionFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if recorded := v.Plugins[ref.DisplayImageRef()]; recorded == nil || recorded.Version != test.expectedVersion || recorded.RollbackDate == "" {
+			t.Errorf("Test: '%s' FAILED : rollback to %s not recorded in version file", test.name, test.expectedVersion)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package ociinstaller
+
+import (
+	"context"
+
+	credentials "github.com/oras-project/oras-credentials-go"
+	"github.com/turbot/go-kit/files"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/options"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+type credentialFunc = func(context.Context, string) (auth.Credential, error)
+
+// registryCredential returns the credential function used to authenticate with a registry
+// registries configured in the plugin options use their configured credential source,
+// any other registry uses the default docker credentials store (including any credential helpers configured there)
+func registryCredential(registries []*options.PluginRegistry) (credentialFunc, error) {
+	defaultStore, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defaultCredential := credentials.Credential(defaultStore)
+
+	registryCredentials := make(map[string]credentialFunc, len(registries))
+	for _, registry := range registries {
+		credential, err := credentialForRegistry(registry)
+		if err != nil {
+			return nil, err
+		}
+		registryCredentials[registry.Host] = credential
+	}
+
+	return func(ctx context.Context, hostport string) (auth.Credential, error) {
+		if credential, ok := registryCredentials[hostport]; ok {
+			return credential(ctx, hostport)
+		}
+		return defaultCredential(ctx, hostport)
+	}, nil
+}
+
+func credentialForRegistry(registry *options.PluginRegistry) (credentialFunc, error) {
+	if err := registry.Validate(); err != nil {
+		return nil, err
+	}
+	switch {
+	case registry.Username != nil:
+		return auth.StaticCredential(registry.Host, auth.Credential{
+			Username: *registry.Username,
+			Password: *registry.Password,
+		}), nil
+	case registry.DockerConfig != nil:
+		configPath, err := files.Tildefy(*registry.DockerConfig)
+		if err != nil {
+			return nil, err
+		}
+		store, err := credentials.NewStore(configPath, credentials.StoreOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return credentials.Credential(store), nil
+	case registry.CredentialHelper != nil:
+		return credentials.Credential(credentials.NewNativeStore(*registry.CredentialHelper)), nil
+	default:
+		store, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return credentials.Credential(store), nil
+	}
+}
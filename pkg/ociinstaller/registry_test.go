@@ -0,0 +1,32 @@
+package ociinstaller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/steampipeconfig/options"
+)
+
+func TestRegistryCredential(t *testing.T) {
+	username, password, helper := "user", "secret", "pass"
+	registries := []*options.PluginRegistry{
+		{Host: "registry.example.com", Username: &username, Password: &password},
+	}
+	credential, err := registryCredential(registries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred, err := credential(context.Background(), "registry.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred.Username != username || cred.Password != password {
+		t.Errorf("expected the configured credentials for registry.example.com, got %s", cred.Username)
+	}
+
+	// only one credential source may be configured per registry
+	registries[0].CredentialHelper = &helper
+	if _, err := registryCredential(registries); err == nil {
+		t.Errorf("expected an error for a registry with multiple credential sources")
+	}
+}
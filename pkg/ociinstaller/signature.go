@@ -0,0 +1,182 @@
+package ociinstaller
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/turbot/go-kit/files"
+	"github.com/turbot/steampipe/pkg/constants"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// the annotation of a cosign signature layer which contains the base64 encoded signature of the layer content
+const cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// SignatureStatus is the result of verifying the signature of a plugin image
+type SignatureStatus string
+
+const (
+	// the image was signed by the configured key
+	SignatureStatusVerified SignatureStatus = "verified"
+	// the image has no signature
+	SignatureStatusUnsigned SignatureStatus = "unsigned"
+	// the image has signatures, but none were made by the configured key for this image
+	SignatureStatusInvalid SignatureStatus = "invalid"
+)
+
+// signatureVerifier verifies cosign-style signatures of plugin images
+//
+// a cosign signature of an image is stored in the same repository as the image, as an image tagged
+// 'sha256-<image digest>.sig' whose layers are signed payloads referencing the image digest
+type signatureVerifier struct {
+	policy    string
+	publicKey crypto.PublicKey
+}
+
+func newSignatureVerifier(policy, publicKeyPath string) (*signatureVerifier, error) {
+	switch policy {
+	case "", constants.PluginSignaturePolicyOff:
+		return nil, nil
+	case constants.PluginSignaturePolicyWarn, constants.PluginSignaturePolicyEnforce:
+	default:
+		return nil, fmt.Errorf("invalid plugin signature policy '%s' - must be one of: %s, %s, %s", policy, constants.PluginSignaturePolicyOff, constants.PluginSignaturePolicyWarn, constants.PluginSignaturePolicyEnforce)
+	}
+	if publicKeyPath == "" {
+		return nil, fmt.Errorf("plugin signature policy '%s' requires a signature public key", policy)
+	}
+	publicKey, err := loadPublicKey(publicKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	return &signatureVerifier{policy: policy, publicKey: publicKey}, nil
+}
+
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	path, err := files.Tildefy(path)
+	if err != nil {
+		return nil, err
+	}
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read signature public key: %s", err)
+	}
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("signature public key %s is not PEM encoded", path)
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse signature public key %s: %s", path, err)
+	}
+	return publicKey, nil
+}
+
+// verify verifies the signature of the image with the given descriptor, which was pulled from source
+// if the signature cannot be verified, an error is returned if the policy is 'enforce'
+func (v *signatureVerifier) verify(ctx context.Context, source oras.ReadOnlyTarget, imageDesc *ocispec.Descriptor) (SignatureStatus, error) {
+	status, err := v.getSignatureStatus(ctx, source, imageDesc.Digest)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("[TRACE] signatureVerifier.verify: image %s signature status: %s", imageDesc.Digest, status)
+	if status != SignatureStatusVerified && v.policy == constants.PluginSignaturePolicyEnforce {
+		return status, fmt.Errorf("plugin image signature could not be verified (%s)", status)
+	}
+	return status, nil
+}
+
+func (v *signatureVerifier) getSignatureStatus(ctx context.Context, source oras.ReadOnlyTarget, imageDigest digest.Digest) (SignatureStatus, error) {
+	signatureDesc, err := source.Resolve(ctx, signatureTag(imageDigest))
+	if err != nil {
+		// treat any failure to resolve the signature as unsigned - the policy determines whether this is fatal
+		if !errors.Is(err, errdef.ErrNotFound) {
+			log.Printf("[TRACE] failed to resolve signature of %s: %s", imageDigest, err)
+		}
+		return SignatureStatusUnsigned, nil
+	}
+
+	manifestBytes, err := content.FetchAll(ctx, source, signatureDesc)
+	if err != nil {
+		return "", err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return "", err
+	}
+
+	for _, layer := range manifest.Layers {
+		signature, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		payload, err := content.FetchAll(ctx, source, layer)
+		if err != nil {
+			return "", err
+		}
+		if v.verifyPayload(payload, signature, imageDigest) {
+			return SignatureStatusVerified, nil
+		}
+	}
+	return SignatureStatusInvalid, nil
+}
+
+// verifyPayload returns whether the signature was made by the public key and the payload references the image digest
+func (v *signatureVerifier) verifyPayload(payload []byte, encodedSignature string, imageDigest digest.Digest) bool {
+	signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return false
+	}
+	if !verifySignature(v.publicKey, payload, signature) {
+		return false
+	}
+	var simpleSigning struct {
+		Critical struct {
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(payload, &simpleSigning); err != nil {
+		return false
+	}
+	return simpleSigning.Critical.Image.DockerManifestDigest == imageDigest.String()
+}
+
+func verifySignature(publicKey crypto.PublicKey, payload, signature []byte) bool {
+	hash := sha256.Sum256(payload)
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, hash[:], signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, payload, signature)
+	}
+	return false
+}
+
+// signatureTag returns the tag of the cosign signature of the image with the given digest
+func signatureTag(imageDigest digest.Digest) string {
+	return strings.Replace(imageDigest.String(), ":", "-", 1) + ".sig"
+}
+
+func isSignatureTag(tag string) bool {
+	return strings.HasPrefix(tag, "sha256-") && strings.HasSuffix(tag, ".sig")
+}
@@ -0,0 +1,87 @@
+package ociinstaller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/turbot/steampipe/pkg/constants"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+// signTestImage adds a cosign signature of the image, made with the given key, to the layout store
+func signTestImage(t *testing.T, store *oci.Store, image ocispec.Descriptor, key *ecdsa.PrivateKey) {
+	t.Helper()
+	ctx := context.Background()
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"acme/foo"},"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},"optional":null}`, image.Digest))
+	hash := sha256.Sum256(payload)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer, err := oras.PushBytes(ctx, store, "application/vnd.dev.cosign.simplesigning.v1+json", payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer.Annotations = map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature)}
+	manifest, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_0, "", oras.PackManifestOptions{
+		Layers: []ocispec.Descriptor{layer},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Tag(ctx, manifest, signatureTag(image.Digest)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSignatureVerification(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	tests := map[string]struct {
+		signingKey *ecdsa.PrivateKey
+		policy     string
+		expected   SignatureStatus
+		expectErr  bool
+	}{
+		"verified":         {signingKey: key, policy: constants.PluginSignaturePolicyEnforce, expected: SignatureStatusVerified},
+		"unsigned warn":    {policy: constants.PluginSignaturePolicyWarn, expected: SignatureStatusUnsigned},
+		"unsigned enforce": {policy: constants.PluginSignaturePolicyEnforce, expected: SignatureStatusUnsigned, expectErr: true},
+		"invalid warn":     {signingKey: otherKey, policy: constants.PluginSignaturePolicyWarn, expected: SignatureStatusInvalid},
+		"invalid enforce":  {signingKey: otherKey, policy: constants.PluginSignaturePolicyEnforce, expected: SignatureStatusInvalid, expectErr: true},
+	}
+	for name, test := range tests {
+		store, image := writeTestPluginLayout(t, t.TempDir())
+		if test.signingKey != nil {
+			signTestImage(t, store, image, test.signingKey)
+		}
+		verifier := &signatureVerifier{policy: test.policy, publicKey: &key.PublicKey}
+		status, err := verifier.verify(context.Background(), store, &image)
+		if status != test.expected {
+			t.Errorf("Test: '%s' FAILED : expected status %s, got %s", name, test.expected, status)
+		}
+		if (err != nil) != test.expectErr {
+			t.Errorf("Test: '%s' FAILED : expected error %v, got %v", name, test.expectErr, err)
+		}
+	}
+}
+
+func TestNewSignatureVerifier(t *testing.T) {
+	if v, err := newSignatureVerifier(constants.PluginSignaturePolicyOff, ""); v != nil || err != nil {
+		t.Errorf("expected no verifier for policy 'off'")
+	}
+	if _, err := newSignatureVerifier(constants.PluginSignaturePolicyEnforce, ""); err == nil {
+		t.Errorf("expected an error for policy 'enforce' without a public key")
+	}
+	if _, err := newSignatureVerifier("strict", "key.pem"); err == nil {
+		t.Errorf("expected an error for an invalid policy")
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"path/filepath"
 	"strings"
 
 	"github.com/containerd/containerd/remotes"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/turbot/steampipe/pkg/constants"
+	"oras.land/oras-go/v2"
 )
 
 type SteampipeImage struct {
@@ -20,7 +22,11 @@ type SteampipeImage struct {
 	Database      *DbImage
 	Fdw           *HubImage
 	Assets        *AssetsImage
-	resolver      *remotes.Resolver
+	// the local OCI image layout the image was installed from (empty if it was pulled from a registry)
+	LayoutPath string
+	// the result of verifying the image signature (empty if the signature was not verified)
+	SignatureStatus SignatureStatus
+	resolver        *remotes.Resolver
 }
 
 type PluginImage struct {
@@ -66,26 +72,93 @@ const (
 )
 
 func (o *ociDownloader) Download(ctx context.Context, ref *SteampipeImageRef, imageType ImageType, destDir string) (*SteampipeImage, error) {
-	var mediaTypes []string
-	Image := o.newSteampipeImage()
-	Image.ImageRef = ref
-	mediaType, err := MediaTypeForPlatform(imageType)
+	log.Println("[TRACE] ociDownloader.Download:", "downloading", ref.ActualImageRef())
+
+	repo, tag, err := o.newRemoteRepository(ref.ActualImageRef())
 	if err != nil {
 		return nil, err
 	}
 
-	mediaTypes = append(mediaTypes, mediaType...)
-	mediaTypes = append(mediaTypes, SharedMediaTypes(imageType)...)
-	mediaTypes = append(mediaTypes, ConfigMediaTypes()...)
-
-	log.Println("[TRACE] ociDownloader.Download:", "downloading", ref.ActualImageRef())
+	sourceRef := tag
+	if o.digest != "" {
+		sourceRef = o.digest
+	}
 
 	// Download the files
-	imageDesc, _, configBytes, layers, err := o.Pull(ctx, ref.ActualImageRef(), mediaTypes, destDir)
+	imageDesc, _, configBytes, layers, err := o.pullFrom(ctx, repo, sourceRef, tag, destDir)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := o.newImageFromManifest(ref, imageType, imageDesc, configBytes, layers)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.verifySignature(ctx, repo, image); err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+// DownloadPluginFromLayout copies a plugin image from a local OCI image layout (see IsLocalImageLayout) to destDir
+// as the layout has no registry ref, the image ref is built from the plugin organization and name
+// in the image config and the 'latest' stream
+// gzipped layout archives are decompressed into workDir
+func (o *ociDownloader) DownloadPluginFromLayout(ctx context.Context, layoutPath string, destDir, workDir string) (*SteampipeImage, error) {
+	log.Println("[TRACE] ociDownloader.DownloadPluginFromLayout:", "copying", layoutPath)
+
+	store, tag, err := openImageLayout(ctx, layoutPath, workDir)
+	if err != nil {
+		return nil, err
+	}
+	imageDesc, _, configBytes, layers, err := o.pullFrom(ctx, store, tag, tag, destDir)
 	if err != nil {
 		return nil, err
 	}
 
+	imageConfig, err := newSteampipeImageConfig(configBytes)
+	if err != nil {
+		return nil, errors.New("invalid image - missing $config")
+	}
+	if imageConfig.Plugin.Name == "" {
+		return nil, fmt.Errorf("invalid image - the image config of %s does not contain the plugin name", layoutPath)
+	}
+	org := imageConfig.Plugin.Organization
+	if org == "" {
+		org = DefaultImageOrg
+	}
+	ref := NewSteampipeImageRef(fmt.Sprintf("%s/%s", org, imageConfig.Plugin.Name))
+
+	image, err := o.newImageFromManifest(ref, ImageTypePlugin, imageDesc, configBytes, layers)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.verifySignature(ctx, store, image); err != nil {
+		return nil, err
+	}
+	image.LayoutPath, err = filepath.Abs(layoutPath)
+	if err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+// verifySignature verifies the signature of a plugin image, if a signature verifier is configured,
+// and sets the SignatureStatus of the image
+// this is done after the image is downloaded to the temp dir, and before anything is installed
+func (o *ociDownloader) verifySignature(ctx context.Context, source oras.ReadOnlyTarget, image *SteampipeImage) error {
+	if o.signatureVerifier == nil || image.Plugin == nil {
+		return nil
+	}
+	status, err := o.signatureVerifier.verify(ctx, source, image.OCIDescriptor)
+	image.SignatureStatus = status
+	return err
+}
+
+func (o *ociDownloader) newImageFromManifest(ref *SteampipeImageRef, imageType ImageType, imageDesc *ocispec.Descriptor, configBytes []byte, layers []ocispec.Descriptor) (*SteampipeImage, error) {
+	var err error
+	Image := o.newSteampipeImage()
+	Image.ImageRef = ref
 	Image.OCIDescriptor = imageDesc
 	Image.Config, err = newSteampipeImageConfig(configBytes)
 	if err != nil {
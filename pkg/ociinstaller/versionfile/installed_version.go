@@ -11,7 +11,12 @@ type InstalledVersion struct {
 	InstalledFrom      string `json:"installed_from,omitempty"`
 	LastCheckedDate    string `json:"last_checked_date,omitempty"`
 	InstallDate        string `json:"install_date,omitempty"`
-	StructVersion      int64  `json:"struct_version"`
+	// the result of verifying the image signature when installed - empty if the signature was not verified
+	SignatureStatus string `json:"signature_status,omitempty"`
+	// set when this version was restored by 'plugin rollback' - the version which was rolled back, and when
+	RolledBackFrom string `json:"rolled_back_from,omitempty"`
+	RollbackDate   string `json:"rollback_date,omitempty"`
+	StructVersion  int64  `json:"struct_version"`
 }
 
 func EmptyInstalledVersion() *InstalledVersion {
@@ -0,0 +1,111 @@
+package versionfile
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/Masterminds/semver/v3"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"github.com/turbot/steampipe/pkg/filepaths"
+)
+
+const PluginLockStructVersion = 20261018
+
+// PluginLockFile records the exact image of each installed plugin, so that an installation can be reproduced
+// with 'plugin install --locked'
+type PluginLockFile struct {
+	// keyed by the plugin name, e.g. hub.steampipe.io/plugins/turbot/aws@latest
+	Plugins       map[string]*LockedPlugin `json:"plugins"`
+	StructVersion int64                    `json:"struct_version"`
+}
+
+// LockedPlugin is the lock file entry of an installed plugin
+type LockedPlugin struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// the image ref the plugin was installed from, e.g. us-docker.pkg.dev/steampipe/plugins/turbot/aws:latest
+	ImageRef    string `json:"image_ref"`
+	ImageDigest string `json:"image_digest"`
+	// an optional semver constraint (e.g. '~0.2') restricting the versions 'plugin update' may update the plugin to
+	// this is set by 'plugin install --constraint', and is preserved when the plugin is updated
+	Constraint string `json:"constraint,omitempty"`
+}
+
+// Allows returns whether the lock constraint (if any) allows the plugin to be updated to the given version
+func (p *LockedPlugin) Allows(version string) (bool, error) {
+	if p.Constraint == "" {
+		return true, nil
+	}
+	allowed, err := ConstraintAllows(p.Constraint, version)
+	if err != nil {
+		return false, sperr.WrapWithMessage(err, "invalid lock entry for plugin %s", p.Name)
+	}
+	return allowed, nil
+}
+
+// ConstraintAllows returns whether the semver constraint allows the given plugin version
+func ConstraintAllows(constraintString, version string) (bool, error) {
+	constraint, err := semver.NewConstraint(constraintString)
+	if err != nil {
+		return false, sperr.WrapWithMessage(err, "invalid constraint '%s'", constraintString)
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false, sperr.WrapWithMessage(err, "invalid version '%s'", version)
+	}
+	return constraint.Check(v), nil
+}
+
+func newPluginLockFile() *PluginLockFile {
+	return &PluginLockFile{
+		Plugins:       map[string]*LockedPlugin{},
+		StructVersion: PluginLockStructVersion,
+	}
+}
+
+// LoadPluginLockFile loads the plugin lock file - if there is no lock file, an empty lock file is returned
+func LoadPluginLockFile() (*PluginLockFile, error) {
+	lockFilePath := filepaths.PluginLockFilePath()
+	if !filehelpers.FileExists(lockFilePath) {
+		return newPluginLockFile(), nil
+	}
+	data, err := os.ReadFile(lockFilePath)
+	if err != nil {
+		return nil, err
+	}
+	lockFile := newPluginLockFile()
+	if err := json.Unmarshal(data, lockFile); err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to parse plugin lock file %s", lockFilePath)
+	}
+	if lockFile.Plugins == nil {
+		lockFile.Plugins = map[string]*LockedPlugin{}
+	}
+	return lockFile, nil
+}
+
+// Lock records the installed image of a plugin with the given constraint
+// if the constraint is empty, any existing constraint is preserved
+func (f *PluginLockFile) Lock(installation *InstalledVersion, constraint string) {
+	locked := &LockedPlugin{
+		Name:        installation.Name,
+		Version:     installation.Version,
+		ImageRef:    installation.InstalledFrom,
+		ImageDigest: installation.ImageDigest,
+		Constraint:  constraint,
+	}
+	if existing, ok := f.Plugins[installation.Name]; ok && constraint == "" {
+		locked.Constraint = existing.Constraint
+	}
+	f.Plugins[installation.Name] = locked
+}
+
+// Save writes the lock file to disk
+func (f *PluginLockFile) Save() error {
+	f.StructVersion = PluginLockStructVersion
+	lockFileJSON, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepaths.PluginLockFilePath(), lockFileJSON, 0644)
+}
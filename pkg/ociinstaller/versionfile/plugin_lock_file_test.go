@@ -0,0 +1,58 @@
+package versionfile
+
+import "testing"
+
+type lockedPluginAllowsTest struct {
+	constraint  string
+	version     string
+	expected    bool
+	expectError bool
+}
+
+func TestLockedPluginAllows(t *testing.T) {
+	tests := map[string]lockedPluginAllowsTest{
+		"no constraint":        {version: "1.0.0", expected: true},
+		"satisfied":            {constraint: "~0.2", version: "0.2.5", expected: true},
+		"not satisfied":        {constraint: "~0.2", version: "0.3.0", expected: false},
+		"exact version":        {constraint: "0.2.1", version: "0.2.1", expected: true},
+		"invalid constraint":   {constraint: "latest!", version: "0.2.1", expectError: true},
+		"invalid version":      {constraint: "~0.2", version: "foo", expectError: true},
+		"major version pinned": {constraint: "^1", version: "2.0.0", expected: false},
+	}
+	for name, test := range tests {
+		locked := &LockedPlugin{Name: "hub.steampipe.io/plugins/turbot/aws@latest", Constraint: test.constraint}
+		allowed, err := locked.Allows(test.version)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("Test: '%s' FAILED : expected error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test: '%s' FAILED : unexpected error %s", name, err.Error())
+			continue
+		}
+		if allowed != test.expected {
+			t.Errorf("Test: '%s' FAILED : expected %v, got %v", name, test.expected, allowed)
+		}
+	}
+}
+
+func TestLockPreservesConstraint(t *testing.T) {
+	lockFile := newPluginLockFile()
+	name := "hub.steampipe.io/plugins/turbot/aws@latest"
+	lockFile.Plugins[name] = &LockedPlugin{Name: name, Version: "0.1.0", ImageDigest: "sha256:old", Constraint: "~0.1"}
+
+	lockFile.Lock(&InstalledVersion{Name: name, Version: "0.1.1", ImageDigest: "sha256:new", InstalledFrom: "us-docker.pkg.dev/steampipe/plugins/turbot/aws:latest"}, "")
+
+	locked := lockFile.Plugins[name]
+	if locked.Version != "0.1.1" || locked.ImageDigest != "sha256:new" || locked.Constraint != "~0.1" {
+		t.Errorf("unexpected lock entry %+v", locked)
+	}
+
+	// a constraint given at install replaces the existing constraint
+	lockFile.Lock(&InstalledVersion{Name: name, Version: "0.2.0", ImageDigest: "sha256:newer", InstalledFrom: "us-docker.pkg.dev/steampipe/plugins/turbot/aws:latest"}, "^0.2")
+	if locked := lockFile.Plugins[name]; locked.Constraint != "^0.2" {
+		t.Errorf("expected the install constraint to be recorded, got %+v", locked)
+	}
+}
@@ -183,6 +183,11 @@ func recomposePluginVersionFile() *PluginVersionFile {
 	return pvf
 }
 
+// LoadInstalledVersion reads the version file in the given plugin installation directory
+func LoadInstalledVersion(pluginDir string) (*InstalledVersion, error) {
+	return readPluginVersionFile(filepath.Join(pluginDir, pluginVersionFileName))
+}
+
 func readPluginVersionFile(versionFile string) (*InstalledVersion, error) {
 	data, err := os.ReadFile(versionFile)
 	if err != nil {
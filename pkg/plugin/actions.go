@@ -51,7 +51,17 @@ func Remove(ctx context.Context, image string, pluginConnections map[string][]*m
 		return nil, err
 	}
 	delete(v.Plugins, fullPluginName)
-	err = v.Save()
+	if err = v.Save(); err != nil {
+		return nil, err
+	}
+
+	// remove the plugin from the lock file
+	lockFile, err := versionfile.LoadPluginLockFile()
+	if err != nil {
+		return nil, err
+	}
+	delete(lockFile.Plugins, fullPluginName)
+	err = lockFile.Save()
 
 	return &steampipeconfig.PluginRemoveReport{Connections: conns, Image: imageRef}, err
 }
@@ -76,11 +86,19 @@ func Install(ctx context.Context, plugin string, sub chan struct{}, opts ...ocii
 	return image, err
 }
 
+// Rollback restores the previously installed version of a plugin
+func Rollback(ctx context.Context, plugin string) (*versionfile.InstalledVersion, error) {
+	statushooks.SetStatus(ctx, fmt.Sprintf("Rolling back plugin %s", plugin))
+	return ociinstaller.RollbackPlugin(plugin)
+}
+
 // PluginListItem is a struct representing an item in the list of plugins
 type PluginListItem struct {
 	Name        string
 	Version     *modconfig.PluginVersionString
 	Connections []string
+	// the result of verifying the image signature when the plugin was installed
+	SignatureStatus string
 }
 
 // List returns all installed plugins
@@ -123,6 +141,7 @@ func List(pluginConnectionMap map[string][]*modconfig.Connection) ([]PluginListI
 				if err != nil {
 					return nil, sperr.WrapWithMessage(err, "could not evaluate plugin version %s", installation.Version)
 				}
+				item.SignatureStatus = installation.SignatureStatus
 			}
 
 			if pluginConnectionMap != nil {
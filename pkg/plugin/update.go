@@ -5,6 +5,7 @@ import (
 
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/ociinstaller"
+	"github.com/turbot/steampipe/pkg/ociinstaller/versionfile"
 )
 
 // SkipUpdate determines if the latest version in a "stream"
@@ -25,6 +26,23 @@ func SkipUpdate(report VersionCheckReport) (bool, string) {
 	return true, constants.InstallMessagePluginLatestAlreadyInstalled
 }
 
+// SkipUpdateForConstraint determines if the constraint of the plugin in the lock file
+// prevents updating to the latest version in the "stream"
+func SkipUpdateForConstraint(report VersionCheckReport, lockFile *versionfile.PluginLockFile) (bool, string, error) {
+	locked, ok := lockFile.Plugins[report.Plugin.Name]
+	if !ok {
+		return false, "", nil
+	}
+	allowed, err := locked.Allows(report.CheckResponse.Version)
+	if err != nil {
+		return false, "", err
+	}
+	if !allowed {
+		return true, constants.InstallMessagePluginConstraintPrevents, nil
+	}
+	return false, "", nil
+}
+
 // check to see if steampipe is running as a Mac/M1 build
 // Mac/M1 can run 'amd64' builds, but that is not a
 // problem, since they will be running under 'rosetta'
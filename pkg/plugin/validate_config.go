@@ -0,0 +1,84 @@
+package plugin
+
+import (
+	"crypto/md5"
+	"fmt"
+	"os/exec"
+
+	"github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
+	sdkgrpc "github.com/turbot/steampipe-plugin-sdk/v5/grpc"
+	sdkproto "github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	sdkshared "github.com/turbot/steampipe-plugin-sdk/v5/grpc/shared"
+	"github.com/turbot/steampipe/pkg/error_helpers"
+	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/pluginmanager_service/grpc"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+// ValidateConnectionConfigs starts a plugin and passes it the config of the given connections,
+// which the plugin parses and validates against its connection config schema
+//
+// returns a map of connection name to failure for connections which the plugin reports as invalid
+// NOTE: the plugin may instead return an error describing all failures - this is returned as err
+//
+// the plugin is started directly rather than via the plugin manager, so the service need not be running
+// - the plugin process is killed before returning
+// all connections must use the given plugin instance
+func ValidateConnectionConfigs(pluginConfig *modconfig.Plugin, connections []*modconfig.Connection) (map[string]string, error) {
+	pluginPath, err := filepaths.GetPluginPath(pluginConfig.Plugin, pluginConfig.Alias)
+	if err != nil {
+		return nil, err
+	}
+	pluginChecksum, err := helpers.FileMD5Hash(pluginPath)
+	if err != nil {
+		return nil, err
+	}
+
+	client := goplugin.NewClient(&goplugin.ClientConfig{
+		HandshakeConfig:  sdkshared.Handshake,
+		Plugins:          map[string]goplugin.Plugin{pluginConfig.Plugin: &sdkshared.WrapperPlugin{}},
+		Cmd:              exec.Command(pluginPath),
+		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolGRPC},
+		SecureConfig: &goplugin.SecureConfig{
+			Checksum: pluginChecksum,
+			Hash:     md5.New(),
+		},
+		Logger: hclog.NewNullLogger(),
+	})
+	defer client.Kill()
+
+	if _, err := client.Start(); err != nil {
+		return nil, grpc.HandleStartFailure(err)
+	}
+	pluginClient, err := sdkgrpc.NewPluginClient(client, pluginConfig.Plugin)
+	if err != nil {
+		return nil, err
+	}
+	if supportedOperations, _ := pluginClient.GetSupportedOperations(); supportedOperations == nil || !supportedOperations.MultipleConnections {
+		return nil, fmt.Errorf(error_helpers.PluginSdkCompatibilityError)
+	}
+
+	configs := make([]*sdkproto.ConnectionConfig, len(connections))
+	for i, c := range connections {
+		configs[i] = &sdkproto.ConnectionConfig{
+			Connection:       c.Name,
+			Plugin:           c.Plugin,
+			PluginShortName:  c.PluginAlias,
+			Config:           c.Config,
+			ChildConnections: c.GetResolveConnectionNames(),
+			PluginInstance:   typehelpers.SafeString(c.PluginInstance),
+		}
+	}
+	// NOTE: set MaxCacheSizeMb to -1 so the plugin does not create a query cache
+	res, err := pluginClient.SetAllConnectionConfigs(&sdkproto.SetAllConnectionConfigsRequest{
+		Configs:        configs,
+		MaxCacheSizeMb: -1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.FailedConnections, nil
+}
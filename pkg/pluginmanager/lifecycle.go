@@ -101,6 +101,25 @@ func Stop() error {
 	return stop(state)
 }
 
+// RefreshConnectionsIfRunning loads the plugin manager state and if a running instance is found,
+// asks it to refresh connections - this also restarts any plugins whose binary has changed
+func RefreshConnectionsIfRunning() error {
+	state, err := LoadState()
+	if err != nil {
+		return err
+	}
+	if state == nil || !state.Running {
+		// nothing to do
+		return nil
+	}
+	pluginManager, err := NewPluginManagerClient(state)
+	if err != nil {
+		return err
+	}
+	_, err = pluginManager.RefreshConnections(&pb.RefreshConnectionsRequest{})
+	return err
+}
+
 // stop the running plugin manager instance
 func stop(state *State) error {
 	log.Println("[DEBUG] pluginmanager.stop start")
@@ -209,6 +209,10 @@ func (m *PluginManager) RefreshConnections(*pb.RefreshConnectionsRequest) (*pb.R
 }
 
 func (m *PluginManager) doRefresh() {
+	// if any plugin binaries have changed (i.e. a plugin has been updated or rolled back),
+	// kill the running instances so the new binary is started when the plugin is next requested
+	m.killUpdatedPlugins()
+
 	refreshResult := connection.RefreshConnections(context.Background(), m)
 	if refreshResult.Error != nil {
 		// NOTE: the RefreshConnectionState will already have sent a notification to the CLI
@@ -398,7 +402,7 @@ func (m *PluginManager) startPlugin(pluginInstance string, connectionConfigs []*
 
 	log.Printf("[INFO] start plugin (%p)", req)
 	// now start the process
-	client, err := m.startPluginProcess(pluginInstance, connectionConfigs)
+	client, err := m.startPluginProcess(startingPlugin, connectionConfigs)
 	if err != nil {
 		// do not retry - no reason to think this will fix itself
 		return nil, err
@@ -460,7 +464,8 @@ func (m *PluginManager) addRunningPlugin(pluginInstance string) (*runningPlugin,
 	return startingPlugin, nil
 }
 
-func (m *PluginManager) startPluginProcess(pluginInstance string, connectionConfigs []*sdkproto.ConnectionConfig) (*plugin.Client, error) {
+func (m *PluginManager) startPluginProcess(startingPlugin *runningPlugin, connectionConfigs []*sdkproto.ConnectionConfig) (*plugin.Client, error) {
+	pluginInstance := startingPlugin.pluginInstance
 	// retrieve the plugin config
 	pluginConfig := m.plugins[pluginInstance]
 	// must be there (if no explicit config was specified, we create a default)
@@ -485,11 +490,20 @@ func (m *PluginManager) startPluginProcess(pluginInstance string, connectionConf
 	}
 
 	utils.LogTime("getting plugin exec hash")
+	pluginInfo, err := os.Stat(pluginPath)
+	if err != nil {
+		return nil, err
+	}
 	pluginChecksum, err := helpers.FileMD5Hash(pluginPath)
 	if err != nil {
 		return nil, err
 	}
 	utils.LogTime("got plugin exec hash")
+	// store the binary details so we can detect if the plugin is updated or rolled back while running
+	startingPlugin.binaryPath = pluginPath
+	startingPlugin.binarySize = pluginInfo.Size()
+	startingPlugin.binaryModTime = pluginInfo.ModTime()
+	startingPlugin.binaryChecksum = pluginChecksum
 	cmd := exec.Command(pluginPath)
 
 	m.setPluginMaxMemory(pluginConfig, cmd)
@@ -2,6 +2,8 @@ package pluginmanager_service
 
 import (
 	"context"
+	"log"
+
 	"github.com/turbot/steampipe/pkg/connection"
 	"github.com/turbot/steampipe/pkg/db/db_local"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
@@ -35,3 +37,41 @@ func (m *PluginManager) handlePluginInstanceChanges(ctx context.Context, newPlug
 	return db_local.PopulatePluginTable(ctx, conn.Conn())
 
 }
+
+// killUpdatedPlugins kills any running plugins whose binary has changed since they were started
+// (e.g. by 'plugin update' or 'plugin rollback') and removes them from the running plugin map
+// - they will be restarted using the new binary the next time they are requested
+func (m *PluginManager) killUpdatedPlugins() {
+	// checking the binaries may involve hashing them, so do not hold the lock while doing so
+	m.mut.RLock()
+	runningPlugins := maps.Clone(m.runningPluginMap)
+	m.mut.RUnlock()
+
+	updatedPlugins := make(map[string]*runningPlugin)
+	for pluginInstance, p := range runningPlugins {
+		// only consider plugins which have finished starting up
+		select {
+		case <-p.initialized:
+		default:
+			continue
+		}
+		if p.binaryChanged() {
+			updatedPlugins[pluginInstance] = p
+		}
+	}
+	if len(updatedPlugins) == 0 {
+		return
+	}
+
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	for pluginInstance, p := range updatedPlugins {
+		// the plugin may have been restarted while we were checking
+		if m.runningPluginMap[pluginInstance] != p {
+			continue
+		}
+		log.Printf("[INFO] binary for plugin instance %s has changed - killing plugin so it is restarted", pluginInstance)
+		m.killPlugin(p)
+		delete(m.runningPluginMap, pluginInstance)
+	}
+}
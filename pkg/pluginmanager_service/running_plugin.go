@@ -1,7 +1,13 @@
 package pluginmanager_service
 
 import (
+	"bytes"
+	"log"
+	"os"
+	"time"
+
 	"github.com/hashicorp/go-plugin"
+	"github.com/turbot/go-kit/helpers"
 	pb "github.com/turbot/steampipe/pkg/pluginmanager_service/grpc/proto"
 )
 
@@ -13,4 +19,35 @@ type runningPlugin struct {
 	initialized    chan struct{}
 	failed         chan struct{}
 	error          error
+	// the path, size, modification time and MD5 checksum of the plugin binary this instance was started from
+	binaryPath     string
+	binarySize     int64
+	binaryModTime  time.Time
+	binaryChecksum []byte
+}
+
+// binaryChanged returns whether the plugin binary has been replaced since this plugin was started
+// the binary is only hashed if its modification time has changed but its size has not
+func (p *runningPlugin) binaryChanged() bool {
+	if p.binaryPath == "" {
+		// plugin has not been started yet
+		return false
+	}
+	info, err := os.Stat(p.binaryPath)
+	if err != nil {
+		log.Printf("[WARN] could not stat plugin binary %s: %s", p.binaryPath, err.Error())
+		return false
+	}
+	if info.Size() != p.binarySize {
+		return true
+	}
+	if info.ModTime().Equal(p.binaryModTime) {
+		return false
+	}
+	checksum, err := helpers.FileMD5Hash(p.binaryPath)
+	if err != nil {
+		log.Printf("[WARN] could not get checksum of plugin binary %s: %s", p.binaryPath, err.Error())
+		return false
+	}
+	return !bytes.Equal(checksum, p.binaryChecksum)
 }
@@ -0,0 +1,51 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ExecProvider resolves secrets by running a credential helper command
+//
+// The secret name is passed as the final argument of the command, and the secret value is read from stdout.
+// If the helper exits with code 3 the secret is treated as not found, allowing lookup to fall through to
+// the next provider. Any other non-zero exit code is an error.
+type ExecProvider struct {
+	command string
+}
+
+const execProviderNotFoundExitCode = 3
+
+func NewExecProvider(command string) *ExecProvider {
+	return &ExecProvider{command: command}
+}
+
+func (p *ExecProvider) Name() string {
+	return fmt.Sprintf("credential helper '%s'", p.command)
+}
+
+func (p *ExecProvider) Lookup(name string) (string, bool, error) {
+	args := strings.Fields(p.command)
+	if len(args) == 0 {
+		return "", false, fmt.Errorf("credential helper command is empty")
+	}
+	cmd := exec.Command(args[0], append(args[1:], name)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == execProviderNotFoundExitCode {
+			return "", false, nil
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", false, fmt.Errorf("%s: %s", err.Error(), msg)
+		}
+		return "", false, err
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), true, nil
+}
@@ -0,0 +1,174 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	secretsFileStructVersion = 20261018
+	secretsKeyLength         = 32
+)
+
+// secretsFile is the on-disk form of the encrypted secrets file
+// the secrets are stored as a json map of name to value, encrypted using AES-256-GCM
+type secretsFile struct {
+	Nonce         string `json:"nonce"`
+	Data          string `json:"data"`
+	StructVersion int64  `json:"struct_version"`
+}
+
+// FileProvider resolves secrets from a local encrypted secrets file
+type FileProvider struct {
+	path string
+	aead cipher.AEAD
+}
+
+// NewFileProvider creates a FileProvider for the secrets file at path
+// key is the base64 encoded 32 byte encryption key (see GenerateKey)
+func NewFileProvider(path string, key string) (*FileProvider, error) {
+	keyBytes, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(keyBytes) != secretsKeyLength {
+		return nil, fmt.Errorf("invalid secrets key - expected a base64 encoded %d byte key", secretsKeyLength)
+	}
+	block, err := aes.NewCipher(keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &FileProvider{path: path, aead: aead}, nil
+}
+
+// GenerateKey returns a new random base64 encoded key for the secrets file
+func GenerateKey() (string, error) {
+	key := make([]byte, secretsKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func (p *FileProvider) Name() string {
+	return fmt.Sprintf("secrets file '%s'", p.path)
+}
+
+func (p *FileProvider) Lookup(name string) (string, bool, error) {
+	secrets, err := p.load()
+	if err != nil {
+		return "", false, err
+	}
+	value, ok := secrets[name]
+	return value, ok, nil
+}
+
+// Names returns the sorted names of the secrets in the file
+func (p *FileProvider) Names() ([]string, error) {
+	secrets, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Set adds or updates a secret, creating the secrets file if needed
+func (p *FileProvider) Set(name, value string) error {
+	secrets, err := p.load()
+	if err != nil {
+		return err
+	}
+	secrets[name] = value
+	return p.save(secrets)
+}
+
+// Remove deletes a secret - returns false if the secret does not exist
+func (p *FileProvider) Remove(name string) (bool, error) {
+	secrets, err := p.load()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := secrets[name]; !ok {
+		return false, nil
+	}
+	delete(secrets, name)
+	return true, p.save(secrets)
+}
+
+// load reads and decrypts the secrets file - if the file does not exist, an empty map is returned
+func (p *FileProvider) load() (map[string]string, error) {
+	secrets := make(map[string]string)
+	fileBytes, err := os.ReadFile(p.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return secrets, nil
+		}
+		return nil, err
+	}
+	var f secretsFile
+	if err := json.Unmarshal(fileBytes, &f); err != nil {
+		return nil, fmt.Errorf("could not parse secrets file: %s", err.Error())
+	}
+	nonce, err := base64.StdEncoding.DecodeString(f.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse secrets file: %s", err.Error())
+	}
+	data, err := base64.StdEncoding.DecodeString(f.Data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse secrets file: %s", err.Error())
+	}
+	if len(nonce) != p.aead.NonceSize() {
+		return nil, fmt.Errorf("could not parse secrets file: invalid nonce")
+	}
+	plaintext, err := p.aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		// NOTE: do not wrap the error - this is either a wrong key or a corrupt file
+		return nil, fmt.Errorf("could not decrypt secrets file - check the secrets key")
+	}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("could not parse decrypted secrets")
+	}
+	return secrets, nil
+}
+
+func (p *FileProvider) save(secrets map[string]string) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, p.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	f := secretsFile{
+		Nonce:         base64.StdEncoding.EncodeToString(nonce),
+		Data:          base64.StdEncoding.EncodeToString(p.aead.Seal(nil, nonce, plaintext, nil)),
+		StructVersion: secretsFileStructVersion,
+	}
+	fileBytes, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+		return err
+	}
+	// write to a temp file and rename, so a failed write never leaves a corrupt secrets file
+	tmpPath := p.path + ".tmp"
+	if err := os.WriteFile(tmpPath, fileBytes, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, p.path)
+}
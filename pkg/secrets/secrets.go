@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/filepaths"
+)
+
+// Provider is a source of secret values which may be referenced from connection config using the 'secret' function
+type Provider interface {
+	// Name returns the name of the provider, used in error messages
+	Name() string
+	// Lookup returns the value of the named secret, and whether the provider has the secret
+	Lookup(name string) (string, bool, error)
+}
+
+// Providers returns the configured secret providers, in lookup order:
+//   - the credential helper command given by STEAMPIPE_SECRETS_HELPER
+//   - the encrypted secrets file (STEAMPIPE_SECRETS_FILE, defaulting to the internal secrets file) if STEAMPIPE_SECRETS_KEY is set
+func Providers() ([]Provider, error) {
+	var providers []Provider
+	if helper, ok := os.LookupEnv(constants.EnvSecretsHelper); ok && helper != "" {
+		providers = append(providers, NewExecProvider(helper))
+	}
+	if key, ok := os.LookupEnv(constants.EnvSecretsKey); ok && key != "" {
+		fileProvider, err := NewFileProvider(SecretsFilePath(), key)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, fileProvider)
+	}
+	return providers, nil
+}
+
+// SecretsFilePath returns the path of the encrypted secrets file
+func SecretsFilePath() string {
+	if path, ok := os.LookupEnv(constants.EnvSecretsFile); ok && path != "" {
+		return path
+	}
+	return filepaths.SecretsFilePath()
+}
+
+// Lookup returns the value of the named secret from the first configured provider which has it
+// NOTE: errors must never include the secret value
+func Lookup(name string) (string, error) {
+	providers, err := Providers()
+	if err != nil {
+		return "", err
+	}
+	if len(providers) == 0 {
+		return "", fmt.Errorf("cannot resolve secret '%s' - no secret provider is configured (set %s or %s)", name, constants.EnvSecretsHelper, constants.EnvSecretsKey)
+	}
+	for _, p := range providers {
+		value, ok, err := p.Lookup(name)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve secret '%s' using %s: %s", name, p.Name(), err.Error())
+		}
+		if ok {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("secret '%s' not found", name)
+}
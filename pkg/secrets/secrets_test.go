@@ -0,0 +1,105 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/constants"
+)
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, err := NewFileProvider(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := provider.Lookup("missing"); ok || err != nil {
+		t.Errorf("Test: '%s' FAILED : expected not found with no error, got %v, %v", "missing file", ok, err)
+	}
+	if err := provider.Set("aws_key", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the value must not be stored in plain text
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(fileBytes), "s3cr3t") {
+		t.Errorf("Test: '%s' FAILED : secret value written in plain text", "encrypted")
+	}
+
+	if value, ok, err := provider.Lookup("aws_key"); !ok || err != nil || value != "s3cr3t" {
+		t.Errorf("Test: '%s' FAILED : expected 's3cr3t', got '%s', %v, %v", "lookup", value, ok, err)
+	}
+
+	otherKey, _ := GenerateKey()
+	wrongKeyProvider, _ := NewFileProvider(path, otherKey)
+	if _, _, err := wrongKeyProvider.Lookup("aws_key"); err == nil {
+		t.Errorf("Test: '%s' FAILED : expected an error decrypting with the wrong key", "wrong key")
+	}
+
+	if removed, err := provider.Remove("aws_key"); !removed || err != nil {
+		t.Errorf("Test: '%s' FAILED : expected secret to be removed, got %v, %v", "remove", removed, err)
+	}
+	if _, ok, _ := provider.Lookup("aws_key"); ok {
+		t.Errorf("Test: '%s' FAILED : secret still present after remove", "remove")
+	}
+
+	if _, err := NewFileProvider(path, "not a key"); err == nil {
+		t.Errorf("Test: '%s' FAILED : expected an error for an invalid key", "invalid key")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper test requires a shell")
+	}
+	dir := t.TempDir()
+	// a credential helper which knows only the secret 'from_helper'
+	helper := filepath.Join(dir, "helper.sh")
+	script := "#!/bin/sh\nif [ \"$1\" = \"from_helper\" ]; then echo helper_value; exit 0; fi\nexit 3\n"
+	if err := os.WriteFile(helper, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	key, _ := GenerateKey()
+	secretsFile := filepath.Join(dir, "secrets.enc")
+	fileProvider, _ := NewFileProvider(secretsFile, key)
+	if err := fileProvider.Set("from_file", "file_value"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(constants.EnvSecretsHelper, helper)
+	t.Setenv(constants.EnvSecretsKey, key)
+	t.Setenv(constants.EnvSecretsFile, secretsFile)
+
+	tests := map[string]struct {
+		name     string
+		expected string
+		err      bool
+	}{
+		"helper":    {name: "from_helper", expected: "helper_value"},
+		"file":      {name: "from_file", expected: "file_value"},
+		"not found": {name: "missing", err: true},
+	}
+	for name, test := range tests {
+		value, err := Lookup(test.name)
+		if test.err {
+			if err == nil {
+				t.Errorf("Test: '%s' FAILED : expected an error", name)
+			}
+			continue
+		}
+		if err != nil || value != test.expected {
+			t.Errorf("Test: '%s' FAILED : expected '%s', got '%s', %v", name, test.expected, value, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package servicehealth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/turbot/steampipe/pkg/pluginmanager"
+	"github.com/turbot/steampipe/pkg/steampipeconfig"
+)
+
+const (
+	HealthPath    = "/healthz"
+	ReadinessPath = "/readyz"
+
+	// the maximum time we allow for the health checks to run
+	checkTimeout = 5 * time.Second
+)
+
+// HealthServer is an http server exposing liveness and readiness endpoints for the steampipe service
+//
+// /healthz reports whether the database is reachable and the plugin manager is running
+// /readyz additionally reports whether all connections have reached the 'ready' state
+type HealthServer struct {
+	pool   *pgxpool.Pool
+	server *http.Server
+}
+
+func NewHealthServer(pool *pgxpool.Pool, listen string, port int) *HealthServer {
+	h := &HealthServer{pool: pool}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(HealthPath, h.handleHealth)
+	mux.HandleFunc(ReadinessPath, h.handleReadiness)
+
+	h.server = &http.Server{
+		Addr:              net.JoinHostPort(listen, fmt.Sprintf("%d", port)),
+		Handler:           mux,
+		ReadHeaderTimeout: checkTimeout,
+	}
+	return h
+}
+
+// Start binds the listen address and serves requests asynchronously
+func (h *HealthServer) Start() error {
+	listener, err := net.Listen("tcp", h.server.Addr)
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] health server listening on %s", h.server.Addr)
+	go func() {
+		if err := h.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("[WARN] health server stopped: %s", err.Error())
+		}
+	}()
+	return nil
+}
+
+func (h *HealthServer) Shutdown(ctx context.Context) error {
+	return h.server.Shutdown(ctx)
+}
+
+func (h *HealthServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	status := h.getStatus(r.Context(), false)
+	writeStatus(w, status, status.Live())
+}
+
+func (h *HealthServer) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	status := h.getStatus(r.Context(), true)
+	writeStatus(w, status, status.Ready())
+}
+
+// getStatus checks database reachability and plugin manager liveness
+// if includeConnections is set, the connection state table is also read
+func (h *HealthServer) getStatus(ctx context.Context, includeConnections bool) *HealthStatus {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	status := &HealthStatus{
+		Database:      newComponentHealth(h.pool.Ping(ctx)),
+		PluginManager: newComponentHealth(checkPluginManager()),
+	}
+
+	if includeConnections && status.Database.ok() {
+		connectionStateMap, err := h.loadConnectionState(ctx)
+		if err != nil {
+			// if we cannot read the connection state, the database is not usable
+			status.Database = newComponentHealth(err)
+		} else {
+			status.setConnections(connectionStateMap)
+		}
+	}
+
+	status.Status = StatusOk
+	if (includeConnections && !status.Ready()) || !status.Live() {
+		status.Status = StatusUnavailable
+	}
+	return status
+}
+
+func (h *HealthServer) loadConnectionState(ctx context.Context) (steampipeconfig.ConnectionStateMap, error) {
+	conn, err := h.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	return steampipeconfig.LoadConnectionState(ctx, conn.Conn())
+}
+
+func checkPluginManager() error {
+	state, err := pluginmanager.LoadState()
+	if err != nil {
+		return err
+	}
+	if !state.Running {
+		return fmt.Errorf("plugin manager is not running")
+	}
+	return nil
+}
+
+func writeStatus(w http.ResponseWriter, status *HealthStatus, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Printf("[WARN] failed to write health status: %s", err.Error())
+	}
+}
@@ -0,0 +1,94 @@
+package servicehealth
+
+import (
+	"sort"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/steampipeconfig"
+)
+
+const (
+	StatusOk          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// ComponentHealth is the health of a single service component (the database or the plugin manager)
+type ComponentHealth struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func newComponentHealth(err error) ComponentHealth {
+	if err != nil {
+		return ComponentHealth{Status: StatusUnavailable, Error: err.Error()}
+	}
+	return ComponentHealth{Status: StatusOk}
+}
+
+func (c ComponentHealth) ok() bool {
+	return c.Status == StatusOk
+}
+
+// ConnectionHealth is the state of a single connection, as read from the connection state table
+type ConnectionHealth struct {
+	Name   string  `json:"name"`
+	Plugin string  `json:"plugin"`
+	Type   string  `json:"type,omitempty"`
+	State  string  `json:"state"`
+	Ready  bool    `json:"ready"`
+	Error  *string `json:"error,omitempty"`
+}
+
+// HealthStatus is the payload returned by the health and readiness endpoints
+type HealthStatus struct {
+	Status        string             `json:"status"`
+	Database      ComponentHealth    `json:"database"`
+	PluginManager ComponentHealth    `json:"plugin_manager"`
+	Connections   []ConnectionHealth `json:"connections,omitempty"`
+	// the number of connections which are not ready
+	PendingConnections int `json:"pending_connections"`
+}
+
+// Live returns whether the database and plugin manager are both reachable
+func (h *HealthStatus) Live() bool {
+	return h.Database.ok() && h.PluginManager.ok()
+}
+
+// Ready returns whether the service is live and all connections have finished loading
+func (h *HealthStatus) Ready() bool {
+	return h.Live() && h.PendingConnections == 0
+}
+
+// setConnections populates the per-connection detail from the connection state map
+// and counts the number of connections which are not yet ready
+func (h *HealthStatus) setConnections(connectionStateMap steampipeconfig.ConnectionStateMap) {
+	h.Connections = make([]ConnectionHealth, 0, len(connectionStateMap))
+	h.PendingConnections = 0
+	for _, c := range connectionStateMap {
+		// connections being deleted are not relevant
+		if c.State == constants.ConnectionStateDeleting {
+			continue
+		}
+		connectionHealth := ConnectionHealth{
+			Name:   c.ConnectionName,
+			Plugin: c.Plugin,
+			Type:   c.GetType(),
+			State:  c.State,
+			Ready:  connectionStateIsReady(c.State),
+			Error:  c.ConnectionError,
+		}
+		if !connectionHealth.Ready {
+			h.PendingConnections++
+		}
+		h.Connections = append(h.Connections, connectionHealth)
+	}
+	sort.Slice(h.Connections, func(i, j int) bool {
+		return h.Connections[i].Name < h.Connections[j].Name
+	})
+}
+
+// a connection is ready if its schema is available, if it has been deliberately disabled,
+// or if it has lazy schema import and has not been referenced yet (consistent with ConnectionState.Loaded)
+func connectionStateIsReady(state string) bool {
+	return state == constants.ConnectionStateReady || state == constants.ConnectionStateDisabled || state == constants.ConnectionStateLazy
+}
@@ -0,0 +1,81 @@
+package servicehealth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/steampipeconfig"
+)
+
+type healthStatusTest struct {
+	states        map[string]string
+	databaseErr   error
+	expectedLive  bool
+	expectedReady bool
+	expectedCount int
+}
+
+var testCasesHealthStatus = map[string]healthStatusTest{
+	"all ready": {
+		states:        map[string]string{"aws": constants.ConnectionStateReady, "gcp": constants.ConnectionStateReady},
+		expectedLive:  true,
+		expectedReady: true,
+		expectedCount: 2,
+	},
+	"disabled and deleting ignored": {
+		states:        map[string]string{"aws": constants.ConnectionStateReady, "gcp": constants.ConnectionStateDisabled, "azure": constants.ConnectionStateDeleting},
+		expectedLive:  true,
+		expectedReady: true,
+		expectedCount: 2,
+	},
+	"lazy": {
+		states:        map[string]string{"aws": constants.ConnectionStateReady, "aws_archive": constants.ConnectionStateLazy},
+		expectedLive:  true,
+		expectedReady: true,
+		expectedCount: 2,
+	},
+	"updating": {
+		states:        map[string]string{"aws": constants.ConnectionStateReady, "gcp": constants.ConnectionStateUpdating},
+		expectedLive:  true,
+		expectedReady: false,
+		expectedCount: 2,
+	},
+	"error": {
+		states:        map[string]string{"aws": constants.ConnectionStateError},
+		expectedLive:  true,
+		expectedReady: false,
+		expectedCount: 1,
+	},
+	"database unavailable": {
+		states:        map[string]string{"aws": constants.ConnectionStateReady},
+		databaseErr:   errors.New("connection refused"),
+		expectedLive:  false,
+		expectedReady: false,
+		expectedCount: 1,
+	},
+}
+
+func TestHealthStatus(t *testing.T) {
+	for name, test := range testCasesHealthStatus {
+		connectionStateMap := steampipeconfig.ConnectionStateMap{}
+		for connectionName, state := range test.states {
+			connectionStateMap[connectionName] = &steampipeconfig.ConnectionState{ConnectionName: connectionName, State: state}
+		}
+		status := &HealthStatus{
+			Database:      newComponentHealth(test.databaseErr),
+			PluginManager: newComponentHealth(nil),
+		}
+		status.setConnections(connectionStateMap)
+
+		if status.Live() != test.expectedLive {
+			t.Errorf("Test: '%s' FAILED: expected live %v, got %v", name, test.expectedLive, status.Live())
+		}
+		if status.Ready() != test.expectedReady {
+			t.Errorf("Test: '%s' FAILED: expected ready %v, got %v", name, test.expectedReady, status.Ready())
+		}
+		if len(status.Connections) != test.expectedCount {
+			t.Errorf("Test: '%s' FAILED: expected %d connections, got %d", name, test.expectedCount, len(status.Connections))
+		}
+	}
+}
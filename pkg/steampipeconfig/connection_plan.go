@@ -0,0 +1,252 @@
+package steampipeconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/utils"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	ConnectionPlanActionCreate         = "create"
+	ConnectionPlanActionUpdate         = "update"
+	ConnectionPlanActionDelete         = "delete"
+	ConnectionPlanActionError          = "error"
+	ConnectionPlanActionUpdateComments = "update comments"
+)
+
+// ConnectionPlanChange is a change which a connection refresh would make to a connection
+type ConnectionPlanChange struct {
+	Connection string   `json:"connection"`
+	Plugin     string   `json:"plugin"`
+	Action     string   `json:"action"`
+	Reasons    []string `json:"reasons,omitempty"`
+}
+
+// ConnectionPlan describes the changes a connection refresh would make to sync the database with the connection config
+type ConnectionPlan struct {
+	Changes []*ConnectionPlanChange `json:"changes"`
+	// plugin instances which would be started to load connection schemas
+	PluginStarts []string `json:"plugin_starts"`
+	// plugin instances which would be restarted as their binary has been updated
+	PluginRestarts []string `json:"plugin_restarts"`
+	// plugin instances which are no longer used by any connection
+	PluginStops []string `json:"plugin_stops"`
+}
+
+func (p *ConnectionPlan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// CountByAction returns the number of changes with the given action
+func (p *ConnectionPlan) CountByAction(action string) int {
+	count := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// NewConnectionUpdatesFromState determines the updates required to sync the given connection state with the connection config
+//
+// unlike NewConnectionUpdates, this does not access the database or start any plugins, so:
+// - changes to the schema of plugins with dynamic schema are not detected
+// - connection config which the plugin would reject is not detected
+// - foreign schemas which are not in the connection state are not identified for deletion
+func NewConnectionUpdatesFromState(currentConnectionStateMap ConnectionStateMap) (*ConnectionUpdates, error) {
+	requiredConnectionStateMap, missingPlugins, res := GetRequiredConnectionStateMap(GlobalConfig.Connections, currentConnectionStateMap)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	disabled := make(map[string]struct{})
+	for _, c := range requiredConnectionStateMap {
+		if c.Disabled() {
+			disabled[c.ConnectionName] = struct{}{}
+		}
+	}
+
+	updates := &ConnectionUpdates{
+		Delete:                   make(map[string]struct{}),
+		Error:                    make(map[string]struct{}),
+		Disabled:                 disabled,
+		Update:                   ConnectionStateMap{},
+		MissingComments:          ConnectionStateMap{},
+		MissingPlugins:           missingPlugins,
+		FinalConnectionState:     requiredConnectionStateMap,
+		CurrentConnectionState:   currentConnectionStateMap,
+		InvalidConnections:       make(map[string]*ValidationFailure),
+		PluginsWithUpdatedBinary: make(map[string]string),
+	}
+	updates.identifyUpdatesAndDeletions(time.Now())
+	updates.IdentifyMissingComments()
+	// no plugins are loaded, so this just copies the schema properties from the current state
+	updates.updateRequiredStateWithSchemaProperties(nil)
+	updates.populateAggregators()
+
+	return updates, nil
+}
+
+// Plan returns a ConnectionPlan describing the updates
+func (u *ConnectionUpdates) Plan() *ConnectionPlan {
+	plan := &ConnectionPlan{
+		Changes:        []*ConnectionPlanChange{},
+		PluginStarts:   []string{},
+		PluginRestarts: []string{},
+		PluginStops:    []string{},
+	}
+
+	for _, name := range utils.SortedMapKeys(u.Update) {
+		requiredState := u.Update[name]
+		change := &ConnectionPlanChange{
+			Connection: name,
+			Plugin:     requiredState.Plugin,
+			Action:     ConnectionPlanActionCreate,
+		}
+		if currentState, ok := u.CurrentConnectionState[name]; ok {
+			change.Action = ConnectionPlanActionUpdate
+			change.Reasons = connectionUpdateReasons(currentState, requiredState)
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	for _, name := range utils.SortedMapKeys(u.Delete) {
+		change := &ConnectionPlanChange{
+			Connection: name,
+			Action:     ConnectionPlanActionDelete,
+			Reasons:    []string{"connection has been removed from config"},
+		}
+		if currentState, ok := u.CurrentConnectionState[name]; ok {
+			change.Plugin = currentState.Plugin
+		}
+		if _, disabled := u.Disabled[name]; disabled {
+			change.Reasons = []string{"import_schema has been disabled"}
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	// connections which will be set to error - include new connections which are in error
+	errorConnections := maps.Clone(u.Error)
+	for name, requiredState := range u.FinalConnectionState {
+		if _, existsInCurrentState := u.CurrentConnectionState[name]; !existsInCurrentState && requiredState.State == constants.ConnectionStateError {
+			errorConnections[name] = struct{}{}
+		}
+	}
+	for _, name := range utils.SortedMapKeys(errorConnections) {
+		requiredState := u.FinalConnectionState[name]
+		plan.Changes = append(plan.Changes, &ConnectionPlanChange{
+			Connection: name,
+			Plugin:     requiredState.Plugin,
+			Action:     ConnectionPlanActionError,
+			Reasons:    []string{requiredState.Error()},
+		})
+	}
+	for _, name := range utils.SortedMapKeys(u.MissingComments) {
+		if _, updating := u.Update[name]; updating {
+			continue
+		}
+		plan.Changes = append(plan.Changes, &ConnectionPlanChange{
+			Connection: name,
+			Plugin:     u.MissingComments[name].Plugin,
+			Action:     ConnectionPlanActionUpdateComments,
+			Reasons:    []string{"comments have not been set"},
+		})
+	}
+
+	plan.PluginStarts, plan.PluginRestarts, plan.PluginStops = u.pluginInstanceChanges()
+	return plan
+}
+
+// pluginInstanceChanges returns the (sorted) plugin instances which would be started, restarted and no longer used
+func (u *ConnectionUpdates) pluginInstanceChanges() (starts, restarts, stops []string) {
+	startLookup := make(map[string]struct{})
+	for _, states := range []ConnectionStateMap{u.Update, u.MissingComments} {
+		for _, state := range states {
+			startLookup[statePluginInstance(state)] = struct{}{}
+		}
+	}
+
+	restartLookup := make(map[string]struct{})
+	for _, connectionName := range u.PluginsWithUpdatedBinary {
+		pluginInstance := statePluginInstance(u.FinalConnectionState[connectionName])
+		restartLookup[pluginInstance] = struct{}{}
+		delete(startLookup, pluginInstance)
+	}
+
+	requiredLookup := make(map[string]struct{})
+	for _, state := range u.FinalConnectionState {
+		if state.usesPlugin() {
+			requiredLookup[statePluginInstance(state)] = struct{}{}
+		}
+	}
+	stopLookup := make(map[string]struct{})
+	for _, state := range u.CurrentConnectionState {
+		pluginInstance := statePluginInstance(state)
+		if _, required := requiredLookup[pluginInstance]; !required && state.usesPlugin() {
+			stopLookup[pluginInstance] = struct{}{}
+		}
+	}
+
+	return utils.SortedMapKeys(startLookup), utils.SortedMapKeys(restartLookup), utils.SortedMapKeys(stopLookup)
+}
+
+// usesPlugin returns whether the plugin of the connection is required, i.e. the connection is not disabled, lazy or in error
+func (d *ConnectionState) usesPlugin() bool {
+	return d.State != constants.ConnectionStateError && !d.Disabled() && !d.Lazy()
+}
+
+// statePluginInstance returns the plugin instance of the connection state, falling back to the plugin
+// (the plugin instance will not be set in connection state files saved by older versions)
+func statePluginInstance(state *ConnectionState) string {
+	if pluginInstance := typehelpers.SafeString(state.PluginInstance); pluginInstance != "" {
+		return pluginInstance
+	}
+	return state.Plugin
+}
+
+// connectionUpdateReasons returns a description of why the connection requires an update
+// this mirrors the checks made by connectionRequiresUpdate
+func connectionUpdateReasons(currentState, requiredState *ConnectionState) []string {
+	if currentState.pluginModTimeChanged(requiredState) {
+		return []string{"plugin binary has been updated"}
+	}
+	if currentState.Disabled() {
+		return []string{"import_schema has been enabled"}
+	}
+	if currentState.State == constants.ConnectionStatePendingIncomplete {
+		return []string{"previous update did not complete"}
+	}
+
+	var reasons []string
+	changed := func(property string, isChanged bool) {
+		if isChanged {
+			reasons = append(reasons, fmt.Sprintf("'%s' has changed", property))
+		}
+	}
+	changed("plugin", currentState.Plugin != requiredState.Plugin)
+	changed("type", currentState.GetType() != requiredState.GetType())
+	changed("import_schema", currentState.ImportSchema != requiredState.ImportSchema)
+	changed("connections", !stringSetsEqual(currentState.Connections, requiredState.Connections) ||
+		!maps.Equal(currentState.ConnectionSelector, requiredState.ConnectionSelector))
+	changed("tags", !maps.Equal(currentState.Tags, requiredState.Tags))
+	changed("tables_include", strings.Join(currentState.TablesInclude, ",") != strings.Join(requiredState.TablesInclude, ","))
+	changed("tables_exclude", strings.Join(currentState.TablesExclude, ",") != strings.Join(requiredState.TablesExclude, ","))
+	changed("default_quals", !maps.Equal(currentState.DefaultQuals, requiredState.DefaultQuals))
+	if currentState.Error() != requiredState.Error() {
+		reasons = append(reasons, "connection error has been resolved")
+	}
+
+	if len(reasons) == 0 && requiredState.GetType() == modconfig.ConnectionTypeAggregator {
+		reasons = append(reasons, "connections of the aggregator plugin have changed")
+	}
+	return reasons
+}
+
+func stringSetsEqual(a, b []string) bool {
+	return maps.Equal(utils.SliceToLookup(a), utils.SliceToLookup(b))
+}
@@ -0,0 +1,84 @@
+package steampipeconfig
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+var testPlanModTime = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type connectionUpdateReasonsTest struct {
+	current  *ConnectionState
+	required *ConnectionState
+	expected []string
+}
+
+var testCasesConnectionUpdateReasons = map[string]connectionUpdateReasonsTest{
+	"plugin updated": {
+		current:  &ConnectionState{Plugin: "aws", PluginModTime: testPlanModTime},
+		required: &ConnectionState{Plugin: "aws", PluginModTime: testPlanModTime.Add(time.Hour)},
+		expected: []string{"plugin binary has been updated"},
+	},
+	"enabled": {
+		current:  &ConnectionState{Plugin: "aws", PluginModTime: testPlanModTime, State: constants.ConnectionStateDisabled},
+		required: &ConnectionState{Plugin: "aws", PluginModTime: testPlanModTime},
+		expected: []string{"import_schema has been enabled"},
+	},
+	"tags and tables changed": {
+		current:  &ConnectionState{Plugin: "aws", PluginModTime: testPlanModTime, Tags: map[string]string{"env": "dev"}},
+		required: &ConnectionState{Plugin: "aws", PluginModTime: testPlanModTime, Tags: map[string]string{"env": "prod"}, TablesInclude: []string{"aws_s3_*"}},
+		expected: []string{"'tags' has changed", "'tables_include' has changed"},
+	},
+	"aggregator children reordered": {
+		current:  &ConnectionState{Plugin: "aws", PluginModTime: testPlanModTime, Connections: []string{"a", "b"}},
+		required: &ConnectionState{Plugin: "aws", PluginModTime: testPlanModTime, Connections: []string{"b", "a"}},
+	},
+}
+
+func TestConnectionUpdateReasons(t *testing.T) {
+	for name, test := range testCasesConnectionUpdateReasons {
+		reasons := connectionUpdateReasons(test.current, test.required)
+		if strings.Join(reasons, "\n") != strings.Join(test.expected, "\n") {
+			t.Errorf("Test: '%s' FAILED : expected %v, got %v", name, test.expected, reasons)
+		}
+	}
+}
+
+func TestConnectionPlan(t *testing.T) {
+	updates := &ConnectionUpdates{
+		Update: ConnectionStateMap{
+			"aws_new": {ConnectionName: "aws_new", Plugin: "aws"},
+		},
+		Delete: map[string]struct{}{"gcp_old": {}},
+		Error:  map[string]struct{}{},
+		FinalConnectionState: ConnectionStateMap{
+			"aws_new": {ConnectionName: "aws_new", Plugin: "aws"},
+			"bad":     {ConnectionName: "bad", Plugin: "nope", State: constants.ConnectionStateError, ConnectionError: utils.ToStringPointer("plugin not installed")},
+		},
+		CurrentConnectionState: ConnectionStateMap{
+			"gcp_old": {ConnectionName: "gcp_old", Plugin: "gcp", State: constants.ConnectionStateReady},
+		},
+		MissingComments:          ConnectionStateMap{},
+		PluginsWithUpdatedBinary: map[string]string{},
+	}
+	plan := updates.Plan()
+
+	var actions []string
+	for _, c := range plan.Changes {
+		actions = append(actions, c.Action+" "+c.Connection)
+	}
+	expected := "create aws_new,delete gcp_old,error bad"
+	if strings.Join(actions, ",") != expected {
+		t.Errorf("Test: '%s' FAILED : expected changes %s, got %s", "changes", expected, strings.Join(actions, ","))
+	}
+	if strings.Join(plan.PluginStarts, ",") != "aws" {
+		t.Errorf("Test: '%s' FAILED : expected plugin starts [aws], got %v", "plugin starts", plan.PluginStarts)
+	}
+	if strings.Join(plan.PluginStops, ",") != "gcp" {
+		t.Errorf("Test: '%s' FAILED : expected plugin stops [gcp], got %v", "plugin stops", plan.PluginStops)
+	}
+}
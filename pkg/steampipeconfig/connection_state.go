@@ -1,6 +1,7 @@
 package steampipeconfig
 
 import (
+	"path"
 	"sort"
 	"strings"
 	"time"
@@ -9,23 +10,27 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"golang.org/x/exp/maps"
 )
 
 // ConnectionState is a struct containing all details for a connection
 // - the plugin name and checksum, the connection config and options
 // json tags needed as this is stored in the connection state file
+// NOTE: the plugin specific connection config is deliberately not stored, as it may contain resolved secrets
 type ConnectionState struct {
 	// the connection name
 	ConnectionName string `json:"connection"  db:"name"`
 	// connection type (expected value: "aggregator")
 	Type *string `json:"type,omitempty"  db:"type"`
-	// should we create a postgres schema for the connection (expected values: "enable", "disable")
+	// should we create a postgres schema for the connection (expected values: "enable", "disable", "lazy")
 	ImportSchema string `json:"import_schema"  db:"import_schema"`
+	// the priority of the connection - connections with a higher priority have their schemas imported first
+	Priority int `json:"priority,omitempty" db:"priority"`
 	// the fully qualified name of the plugin
 	Plugin string `json:"plugin"  db:"plugin"`
 	// the plugin instance
 	PluginInstance *string `json:"plugin_instance" db:"plugin_instance"`
-	// the connection state (pending, updating, deleting, error, ready)
+	// the connection state (pending, updating, deleting, error, ready, disabled, lazy)
 	State string `json:"state"  db:"state"`
 	// error (if there is one - make a pointer to support null)
 	ConnectionError *string `json:"error,omitempty" db:"error"`
@@ -40,22 +45,37 @@ type ConnectionState struct {
 	// the update time of the connection
 	ConnectionModTime time.Time `json:"connection_mod_time" db:"connection_mod_time"`
 	// the matching patterns of child connections (for aggregators)
-	Connections     []string `json:"connections" db:"connections"`
-	FileName        string   `json:"file_name" db:"file_name"`
-	StartLineNumber int      `json:"start_line_number" db:"start_line_number"`
-	EndLineNumber   int      `json:"end_line_number" db:"end_line_number"`
+	Connections []string `json:"connections" db:"connections"`
+	// the tag selector used to match child connections (for aggregators)
+	ConnectionSelector map[string]string `json:"connection_selector,omitempty" db:"connection_selector"`
+	// the connection tags
+	Tags map[string]string `json:"tags,omitempty" db:"tags"`
+	// the table patterns used to filter the tables imported into the connection schema
+	TablesInclude []string `json:"tables_include,omitempty" db:"tables_include"`
+	TablesExclude []string `json:"tables_exclude,omitempty" db:"tables_exclude"`
+	// map of table name to the sql condition applied to every query of the table
+	DefaultQuals    map[string]string `json:"default_quals,omitempty" db:"default_quals"`
+	FileName        string            `json:"file_name" db:"file_name"`
+	StartLineNumber int               `json:"start_line_number" db:"start_line_number"`
+	EndLineNumber   int               `json:"end_line_number" db:"end_line_number"`
 }
 
 func NewConnectionState(connection *modconfig.Connection, creationTime time.Time) *ConnectionState {
 	state := &ConnectionState{
-		Plugin:         connection.Plugin,
-		PluginInstance: connection.PluginInstance,
-		ConnectionName: connection.Name,
-		PluginModTime:  creationTime,
-		State:          constants.ConnectionStateReady,
-		Type:           &connection.Type,
-		ImportSchema:   connection.ImportSchema,
-		Connections:    connection.ConnectionNames,
+		Plugin:             connection.Plugin,
+		PluginInstance:     connection.PluginInstance,
+		ConnectionName:     connection.Name,
+		PluginModTime:      creationTime,
+		State:              constants.ConnectionStateReady,
+		Type:               &connection.Type,
+		ImportSchema:       connection.ImportSchema,
+		Priority:           connection.Priority,
+		Connections:        connection.ConnectionNames,
+		ConnectionSelector: connection.ConnectionTagSelector,
+		Tags:               connection.Tags,
+		TablesInclude:      connection.TablesInclude,
+		TablesExclude:      connection.TablesExclude,
+		DefaultQuals:       connection.DefaultQuals,
 	}
 	state.setFilename(connection)
 	if connection.Error != nil {
@@ -92,10 +112,24 @@ func (d *ConnectionState) Equals(other *ConnectionState) bool {
 		return false
 	}
 
+	if !maps.Equal(d.ConnectionSelector, other.ConnectionSelector) || !maps.Equal(d.Tags, other.Tags) {
+		return false
+	}
+
+	if strings.Join(d.TablesInclude, ",") != strings.Join(other.TablesInclude, ",") ||
+		strings.Join(d.TablesExclude, ",") != strings.Join(other.TablesExclude, ",") {
+		return false
+	}
+
+	if !maps.Equal(d.DefaultQuals, other.DefaultQuals) {
+		return false
+	}
+
 	if d.pluginModTimeChanged(other) {
 		return false
 	}
 	// do not look at connection mod time as the mod time for the desired state is not relevant
+	// do not look at priority as this does not affect the schema (the connection state table is always updated with it)
 
 	return true
 }
@@ -108,9 +142,36 @@ func (d *ConnectionState) pluginModTimeChanged(other *ConnectionState) bool {
 	return false
 }
 
+// CanCloneSchema returns whether the schema of this connection may be cloned for other connections using the plugin
+// (a connection with a table filter or default quals does not have the unmodified plugin schema so cannot be cloned)
 func (d *ConnectionState) CanCloneSchema() bool {
 	return d.SchemaMode != plugin.SchemaModeDynamic &&
-		d.GetType() != modconfig.ConnectionTypeAggregator
+		d.GetType() != modconfig.ConnectionTypeAggregator &&
+		!d.HasTableFilter() &&
+		len(d.DefaultQuals) == 0
+}
+
+// HasTableFilter returns whether the tables imported into the connection schema are filtered
+func (d *ConnectionState) HasTableFilter() bool {
+	return len(d.TablesInclude) > 0 || len(d.TablesExclude) > 0
+}
+
+// IncludesTable returns whether the table passes the table filter of the connection, i.e. it matches
+// one of the tables_include patterns (if any) and none of the tables_exclude patterns
+func (d *ConnectionState) IncludesTable(table string) bool {
+	if len(d.TablesInclude) > 0 && !matchesAnyPattern(table, d.TablesInclude) {
+		return false
+	}
+	return !matchesAnyPattern(table, d.TablesExclude)
+}
+
+func matchesAnyPattern(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if match, _ := path.Match(pattern, name); match {
+			return true
+		}
+	}
+	return false
 }
 
 func (d *ConnectionState) Error() string {
@@ -123,15 +184,26 @@ func (d *ConnectionState) SetError(err string) {
 }
 
 // Loaded returns true if the connection state is 'ready' or 'error'
-// Disabled connections are considered as 'loaded'
+// Disabled connections and lazy connections whose schema has not been requested are considered as 'loaded'
 func (d *ConnectionState) Loaded() bool {
-	return d.Disabled() || d.State == constants.ConnectionStateReady || d.State == constants.ConnectionStateError
+	return d.Disabled() || d.Lazy() || d.State == constants.ConnectionStateReady || d.State == constants.ConnectionStateError
 }
 
 func (d *ConnectionState) Disabled() bool {
 	return d.State == constants.ConnectionStateDisabled
 }
 
+// Lazy returns whether the connection has lazy schema import and its schema has not been imported
+func (d *ConnectionState) Lazy() bool {
+	return d.State == constants.ConnectionStateLazy
+}
+
+// schemaImported returns whether the connection schema has been (or is being) imported,
+// i.e. the connection is not lazy, disabled or in error
+func (d *ConnectionState) schemaImported() bool {
+	return !d.Lazy() && !d.Disabled() && d.State != constants.ConnectionStateError
+}
+
 func (d *ConnectionState) GetType() string {
 	return typehelpers.SafeString(d.Type)
 }
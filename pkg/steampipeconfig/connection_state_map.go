@@ -8,6 +8,7 @@ import (
 	"time"
 
 	sdkplugin "github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/filepaths"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
@@ -82,6 +83,13 @@ func GetRequiredConnectionStateMap(connectionMap map[string]*modconfig.Connectio
 		if connection.ImportSchema == modconfig.ImportSchemaDisabled {
 			requiredState[name].State = constants.ConnectionStateDisabled
 		}
+		// if schema import is lazy, the schema is not imported until the connection is referenced
+		// - unless it has already been imported, set desired state as lazy
+		if connection.ImportLazy() {
+			if currentState, ok := currentConnectionState[name]; !ok || !currentState.schemaImported() {
+				requiredState[name].State = constants.ConnectionStateLazy
+			}
+		}
 		// NOTE: if the connection exists in the current state, copy the connection mod time
 		// (this will be updated to 'now' later if we are updating the connection)
 		if currentState, ok := currentConnectionState[name]; ok {
@@ -112,7 +120,7 @@ func (m ConnectionStateMap) Pending() bool {
 	return m.ConnectionsInState(constants.ConnectionStatePending, constants.ConnectionStatePendingIncomplete)
 }
 
-// Loaded returns whether loading is complete, i.e.  all connections are either ready or error
+// Loaded returns whether loading is complete, i.e.  all connections are either ready or error (or disabled or lazy)
 // (optionally, a list of connections may be passed, in which case just these connections are checked)
 func (m ConnectionStateMap) Loaded(connections ...string) bool {
 	// if no connections were passed, check them all
@@ -134,6 +142,18 @@ func (m ConnectionStateMap) Loaded(connections ...string) bool {
 	return true
 }
 
+// LazyConnections returns the connections of the given names which have lazy schema import
+// and whose schema has not been imported
+func (m ConnectionStateMap) LazyConnections(connectionNames ...string) []string {
+	var res []string
+	for _, connectionName := range connectionNames {
+		if connectionState, ok := m[connectionName]; ok && connectionState.Lazy() {
+			res = append(res, connectionName)
+		}
+	}
+	return res
+}
+
 // ConnectionsInState returns whether there are any connections one of the given states
 func (m ConnectionStateMap) ConnectionsInState(states ...string) bool {
 	for _, c := range m {
@@ -156,6 +176,26 @@ func (m ConnectionStateMap) Save() error {
 	return os.WriteFile(connFilePath, connFileJSON, 0644)
 }
 
+// LoadConnectionStateFile loads the connection state saved by the last connection refresh
+// if there is no state file, an empty map is returned
+func LoadConnectionStateFile() (ConnectionStateMap, error) {
+	connFileJSON, err := os.ReadFile(filepaths.ConnectionStatePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ConnectionStateMap{}, nil
+		}
+		return nil, err
+	}
+	var res ConnectionStateMap
+	if err := json.Unmarshal(connFileJSON, &res); err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to parse connection state file")
+	}
+	if res == nil {
+		res = ConnectionStateMap{}
+	}
+	return res, nil
+}
+
 func (m ConnectionStateMap) Equals(other ConnectionStateMap) bool {
 	if m != nil && other == nil {
 		return false
@@ -217,8 +257,8 @@ func (m ConnectionStateMap) getFirstSearchPathConnectionMapForPlugins(searchPath
 		if !ok {
 			continue
 		}
-		// if this connection is disabled, skip it
-		if connectionState.Disabled() {
+		// if this connection is disabled or has not been imported yet, skip it
+		if connectionState.Disabled() || connectionState.Lazy() {
 			continue
 		}
 
@@ -237,7 +277,7 @@ func (m ConnectionStateMap) SetConnectionsToPendingOrIncomplete() {
 		if state.State == constants.ConnectionStateReady {
 			state.State = constants.ConnectionStatePending
 			state.ConnectionModTime = time.Now()
-		} else if state.State != constants.ConnectionStateDisabled {
+		} else if state.State != constants.ConnectionStateDisabled && state.State != constants.ConnectionStateLazy {
 			state.State = constants.ConnectionStatePendingIncomplete
 			state.ConnectionModTime = time.Now()
 		}
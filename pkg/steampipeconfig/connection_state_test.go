@@ -0,0 +1,140 @@
+package steampipeconfig
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+type includesTableTest struct {
+	include  []string
+	exclude  []string
+	table    string
+	expected bool
+}
+
+var testCasesIncludesTable = map[string]includesTableTest{
+	"no filter": {
+		table:    "aws_s3_bucket",
+		expected: true,
+	},
+	"included": {
+		include:  []string{"aws_ec2_*", "aws_s3_bucket"},
+		table:    "aws_s3_bucket",
+		expected: true,
+	},
+	"included by wildcard": {
+		include:  []string{"aws_ec2_*"},
+		table:    "aws_ec2_instance",
+		expected: true,
+	},
+	"not included": {
+		include:  []string{"aws_ec2_*"},
+		table:    "aws_s3_bucket",
+		expected: false,
+	},
+	"excluded": {
+		exclude:  []string{"aws_ec2_ami*"},
+		table:    "aws_ec2_ami_shared",
+		expected: false,
+	},
+	"included and excluded": {
+		include:  []string{"aws_ec2_*"},
+		exclude:  []string{"aws_ec2_ami*"},
+		table:    "aws_ec2_ami",
+		expected: false,
+	},
+}
+
+func TestConnectionStateIncludesTable(t *testing.T) {
+	for name, test := range testCasesIncludesTable {
+		state := &ConnectionState{TablesInclude: test.include, TablesExclude: test.exclude}
+		if res := state.IncludesTable(test.table); res != test.expected {
+			t.Errorf("Test: '%s' FAILED : expected %v, got %v", name, test.expected, res)
+		}
+	}
+}
+
+type lazyConnectionRequiresUpdateTest struct {
+	currentState  string
+	requiredState string
+	expected      bool
+}
+
+var testCasesLazyConnectionRequiresUpdate = map[string]lazyConnectionRequiresUpdateTest{
+	"new lazy connection": {
+		requiredState: constants.ConnectionStateLazy,
+		expected:      false,
+	},
+	"lazy connection not referenced": {
+		currentState:  constants.ConnectionStateLazy,
+		requiredState: constants.ConnectionStateLazy,
+		expected:      false,
+	},
+	"lazy connection referenced": {
+		currentState:  constants.ConnectionStateLazy,
+		requiredState: constants.ConnectionStateReady,
+		expected:      true,
+	},
+	"lazy connection already imported": {
+		currentState:  constants.ConnectionStateReady,
+		requiredState: constants.ConnectionStateReady,
+		expected:      false,
+	},
+}
+
+func TestLazyConnectionRequiresUpdate(t *testing.T) {
+	for name, test := range testCasesLazyConnectionRequiresUpdate {
+		currentStateMap := ConnectionStateMap{}
+		if test.currentState != "" {
+			currentStateMap["aws_archive"] = &ConnectionState{ConnectionName: "aws_archive", Plugin: "aws", ImportSchema: modconfig.ImportSchemaLazy, State: test.currentState}
+		}
+		requiredState := &ConnectionState{ConnectionName: "aws_archive", Plugin: "aws", ImportSchema: modconfig.ImportSchemaLazy, State: test.requiredState}
+
+		res := connectionRequiresUpdate(nil, "aws_archive", currentStateMap, requiredState)
+		if res.requiresUpdate != test.expected {
+			t.Errorf("Test: '%s' FAILED : expected %v, got %v", name, test.expected, res.requiresUpdate)
+		}
+	}
+}
+
+func TestLazyConnections(t *testing.T) {
+	stateMap := ConnectionStateMap{
+		"aws_dev":     {ConnectionName: "aws_dev", State: constants.ConnectionStateReady},
+		"aws_archive": {ConnectionName: "aws_archive", State: constants.ConnectionStateLazy},
+		"aws_old":     {ConnectionName: "aws_old", State: constants.ConnectionStateDisabled},
+	}
+	res := stateMap.LazyConnections("public", "aws_dev", "aws_archive", "aws_old")
+	if len(res) != 1 || res[0] != "aws_archive" {
+		t.Errorf("Test: '%s' FAILED : expected [aws_archive], got %v", "lazy connections", res)
+	}
+	if !stateMap.Loaded() {
+		t.Errorf("Test: '%s' FAILED : expected lazy connections to be considered loaded", "loaded")
+	}
+}
+
+func TestLazyLoadRequests(t *testing.T) {
+	prevSteampipeDir := filepaths.SteampipeDir
+	filepaths.SteampipeDir = t.TempDir()
+	defer func() { filepaths.SteampipeDir = prevSteampipeDir }()
+
+	for _, request := range [][]string{{"aws_archive"}, {"aws_archive", "gcp_archive"}} {
+		if err := SaveLazyLoadRequest(request); err != nil {
+			t.Fatalf("Test: '%s' FAILED : failed to save request: %s", "save", err.Error())
+		}
+	}
+
+	res := TakeLazyLoadRequests()
+	sort.Strings(res)
+	if strings.Join(res, ",") != "aws_archive,gcp_archive" {
+		t.Errorf("Test: '%s' FAILED : expected [aws_archive gcp_archive], got %v", "take", res)
+	}
+	// the requests are deleted once taken
+	if res := TakeLazyLoadRequests(); len(res) != 0 {
+		t.Errorf("Test: '%s' FAILED : expected no requests, got %v", "take again", res)
+	}
+}
@@ -95,6 +95,14 @@ func populateConnectionUpdates(ctx context.Context, pool *pgxpool.Pool, pluginMa
 	}
 	log.Printf("[INFO] built required connection state")
 
+	// any lazy connections which have been referenced must now have their schema imported
+	for _, name := range config.LazyLoadConnectionNames {
+		if state, ok := requiredConnectionStateMap[name]; ok && state.Lazy() {
+			log.Printf("[INFO] lazy connection %s has been referenced - importing schema", name)
+			state.State = constants.ConnectionStateReady
+		}
+	}
+
 	// build lookup of disabled connections
 	disabled := make(map[string]struct{})
 	for _, c := range requiredConnectionStateMap {
@@ -137,48 +145,8 @@ func populateConnectionUpdates(ctx context.Context, pool *pgxpool.Pool, pluginMa
 	}
 	log.Printf("[INFO] identify connections to update")
 
-	modTime := time.Now()
-
-	// connections to create/update
-	for name, requiredConnectionState := range requiredConnectionStateMap {
-		// if the connection requires update, add to list
-		res := connectionRequiresUpdate(config.ForceUpdateConnectionNames, name, currentConnectionStateMap, requiredConnectionState)
-		if res.requiresUpdate {
-			log.Printf("[INFO] connection %s is out of date or missing. updates: %v", name, maps.Keys(updates.Update))
-			updates.Update[name] = requiredConnectionState
-
-			// set the connection mod time of required connection data to now
-			requiredConnectionState.ConnectionModTime = modTime
-
-			// if the plugin mod time has changed, add this to the map of connections
-			// we need to refetch the rate limiters for this plugin
-			if res.pluginBinaryChanged {
-				// store map item of plugin name to connection name (so we only have one entry per plugin)
-				pluginShortName := GlobalConfig.Connections[requiredConnectionState.ConnectionName].PluginAlias
-				updates.PluginsWithUpdatedBinary[pluginShortName] = requiredConnectionState.ConnectionName
-			}
-		}
-	}
-
-	// TODO KAI TIDY INTO FUNCTION
-
-	log.Printf("[INFO] Identify connections to delete")
-	// connections to delete - any connection which is in connection state but NOT required connections
-	for name, currentState := range currentConnectionStateMap {
-		if _, connectionRequired := requiredConnectionStateMap[name]; !connectionRequired {
-			log.Printf("[TRACE] connection %s in current state but not in required state - marking for deletion\n", name)
-			updates.Delete[name] = struct{}{}
-		} else if updates.FinalConnectionState[name].Disabled() && !currentState.Disabled() {
-			// if required connection state is disabled and it is not currently disabled, mark for deletion
-			log.Printf("[TRACE] connection %s is disabled - marking for deletion\n", name)
-			updates.Delete[name] = struct{}{}
-		} else if updates.FinalConnectionState[name].State == constants.ConnectionStateError && currentState.State != constants.ConnectionStateError {
-			// if required connection state is disabled and it is not currently disabled, add to error map
-			// the schema will be deleted by the connection will remain in the table
-			log.Printf("[TRACE] connection %s is in error - marking for deletion\n", name)
-			updates.Error[name] = struct{}{}
-		}
-	}
+	// identify connections to create/update/delete
+	updates.identifyUpdatesAndDeletions(time.Now())
 
 	// if there are any foreign schemas which do not exist in currentConnectionState OR requiredConnectionState,
 	// add them into deletions
@@ -234,6 +202,50 @@ func populateConnectionUpdates(ctx context.Context, pool *pgxpool.Pool, pluginMa
 	return updates, res
 }
 
+// identifyUpdatesAndDeletions compares the current connection state with the required state (FinalConnectionState)
+// and populates Update, Delete and Error
+// modTime is set as the connection mod time of all updated connections
+func (u *ConnectionUpdates) identifyUpdatesAndDeletions(modTime time.Time) {
+	// connections to create/update
+	for name, requiredConnectionState := range u.FinalConnectionState {
+		// if the connection requires update, add to list
+		res := connectionRequiresUpdate(u.forceUpdateConnectionNames, name, u.CurrentConnectionState, requiredConnectionState)
+		if res.requiresUpdate {
+			log.Printf("[INFO] connection %s is out of date or missing. updates: %v", name, maps.Keys(u.Update))
+			u.Update[name] = requiredConnectionState
+
+			// set the connection mod time of required connection data to now
+			requiredConnectionState.ConnectionModTime = modTime
+
+			// if the plugin mod time has changed, add this to the map of connections
+			// we need to refetch the rate limiters for this plugin
+			if res.pluginBinaryChanged {
+				// store map item of plugin name to connection name (so we only have one entry per plugin)
+				pluginShortName := GlobalConfig.Connections[requiredConnectionState.ConnectionName].PluginAlias
+				u.PluginsWithUpdatedBinary[pluginShortName] = requiredConnectionState.ConnectionName
+			}
+		}
+	}
+
+	log.Printf("[INFO] Identify connections to delete")
+	// connections to delete - any connection which is in connection state but NOT required connections
+	for name, currentState := range u.CurrentConnectionState {
+		if _, connectionRequired := u.FinalConnectionState[name]; !connectionRequired {
+			log.Printf("[TRACE] connection %s in current state but not in required state - marking for deletion\n", name)
+			u.Delete[name] = struct{}{}
+		} else if u.FinalConnectionState[name].Disabled() && !currentState.Disabled() {
+			// if required connection state is disabled and it is not currently disabled, mark for deletion
+			log.Printf("[TRACE] connection %s is disabled - marking for deletion\n", name)
+			u.Delete[name] = struct{}{}
+		} else if u.FinalConnectionState[name].State == constants.ConnectionStateError && currentState.State != constants.ConnectionStateError {
+			// if required connection state is disabled and it is not currently disabled, add to error map
+			// the schema will be deleted by the connection will remain in the table
+			log.Printf("[TRACE] connection %s is in error - marking for deletion\n", name)
+			u.Error[name] = struct{}{}
+		}
+	}
+}
+
 type connectionRequiresUpdateResult struct {
 	requiresUpdate      bool
 	pluginBinaryChanged bool
@@ -247,8 +259,8 @@ func connectionRequiresUpdate(forceUpdateConnectionNames []string, name string,
 	}
 	// check whether this connection exists in the state
 	currentConnectionState, schemaExistsInState := currentConnectionStateMap[name]
-	// if the connection has been disabled, return false
-	if requiredConnectionState.Disabled() {
+	// if the connection has been disabled, or has lazy schema import and has not been referenced, return false
+	if requiredConnectionState.Disabled() || requiredConnectionState.Lazy() {
 		return res
 	}
 	// is this is a new connection
@@ -264,8 +276,8 @@ func connectionRequiresUpdate(forceUpdateConnectionNames []string, name string,
 		return res
 	}
 
-	// if the connection has been enabled (i.e. if it was previously DISABLED) , return true
-	if currentConnectionState.Disabled() {
+	// if the connection has been enabled (i.e. if it was previously DISABLED or LAZY) , return true
+	if currentConnectionState.Disabled() || currentConnectionState.Lazy() {
 		res.requiresUpdate = true
 		return res
 	}
@@ -414,8 +426,8 @@ func (u *ConnectionUpdates) setError(connectionName string, error string) {
 // NOTE: this mutates FinalConnectionState to set comment_set (if needed)
 func (u *ConnectionUpdates) IdentifyMissingComments() {
 	for name, state := range u.FinalConnectionState {
-		// if the state is in error, skip
-		if state.State == constants.ConnectionStateError {
+		// if the state is in error, or the schema has not been imported, skip
+		if state.State == constants.ConnectionStateError || state.Lazy() {
 			continue
 		}
 		if currentState, existsInCurrentState := u.CurrentConnectionState[name]; existsInCurrentState {
@@ -447,7 +459,8 @@ func (u *ConnectionUpdates) populateAggregators() {
 	pluginAggregatorMap := make(map[string][]string)
 
 	for connectionName, state := range u.FinalConnectionState {
-		if state.GetType() == modconfig.ConnectionTypeAggregator {
+		// (lazy aggregators are not updated until they are referenced)
+		if state.GetType() == modconfig.ConnectionTypeAggregator && !state.Lazy() {
 			pluginAggregatorMap[state.Plugin] = append(pluginAggregatorMap[state.Plugin], connectionName)
 		}
 	}
@@ -2,6 +2,7 @@ package steampipeconfig
 
 type connectionUpdatesConfig struct {
 	ForceUpdateConnectionNames []string
+	LazyLoadConnectionNames    []string
 }
 
 type ConnectionUpdatesOption func(opt *connectionUpdatesConfig)
@@ -11,3 +12,11 @@ func WithForceUpdate(connections []string) ConnectionUpdatesOption {
 		opt.ForceUpdateConnectionNames = connections
 	}
 }
+
+// WithLazyLoad specifies connections with lazy schema import which have been referenced,
+// so their schema must be imported
+func WithLazyLoad(connections []string) ConnectionUpdatesOption {
+	return func(opt *connectionUpdatesConfig) {
+		opt.LazyLoadConnectionNames = connections
+	}
+}
@@ -0,0 +1,66 @@
+package steampipeconfig
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/turbot/steampipe/pkg/filepaths"
+	"golang.org/x/exp/maps"
+)
+
+// SaveLazyLoadRequest saves a request to import the schemas of the given connections, which have lazy schema import
+// the request is taken by the next connection refresh executed by the plugin manager
+//
+// each request is written to its own file, which is renamed into place once written,
+// so concurrent requests are never lost and a partially written request is never read
+func SaveLazyLoadRequest(connectionNames []string) error {
+	data, err := json.Marshal(connectionNames)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepaths.EnsureLazyLoadRequestsDir(), "request-*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), strings.TrimSuffix(f.Name(), ".tmp")+".json")
+}
+
+// TakeLazyLoadRequests returns the connections of all saved lazy load requests, and deletes the requests
+func TakeLazyLoadRequests() []string {
+	requestFiles, err := filepath.Glob(filepath.Join(filepaths.EnsureLazyLoadRequestsDir(), "*.json"))
+	if err != nil {
+		log.Printf("[WARN] failed to list lazy load requests: %s", err.Error())
+		return nil
+	}
+
+	connectionNames := make(map[string]struct{})
+	for _, requestFile := range requestFiles {
+		data, err := os.ReadFile(requestFile)
+		if err != nil {
+			log.Printf("[WARN] failed to read lazy load request %s: %s", requestFile, err.Error())
+			continue
+		}
+		os.Remove(requestFile)
+
+		var request []string
+		if err := json.Unmarshal(data, &request); err != nil {
+			log.Printf("[WARN] failed to parse lazy load request %s: %s", requestFile, err.Error())
+			continue
+		}
+		for _, name := range request {
+			connectionNames[name] = struct{}{}
+		}
+	}
+	return maps.Keys(connectionNames)
+}
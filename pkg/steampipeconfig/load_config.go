@@ -211,11 +211,7 @@ type loadConfigOptions struct {
 func loadConfig(configFolder string, steampipeConfig *SteampipeConfig, opts *loadConfigOptions) *error_helpers.ErrorAndWarnings {
 	log.Printf("[INFO] loadConfig is loading connection config")
 	// get all the config files in the directory
-	configPaths, err := filehelpers.ListFiles(configFolder, &filehelpers.ListOptions{
-		Flags:   filehelpers.FilesFlat,
-		Include: opts.include,
-	})
-
+	configPaths, err := getConfigPaths(configFolder, opts)
 	if err != nil {
 		log.Printf("[WARN] loadConfig: failed to get config file paths: %v\n", err)
 		return error_helpers.NewErrorsAndWarning(err)
@@ -224,22 +220,48 @@ func loadConfig(configFolder string, steampipeConfig *SteampipeConfig, opts *loa
 		return nil
 	}
 
+	diags := decodeConfig(configFolder, configPaths, steampipeConfig, opts)
+	if diags.HasErrors() {
+		return error_helpers.DiagsToErrorsAndWarnings("Failed to load config", diags)
+	}
+
+	res := error_helpers.DiagsToErrorsAndWarnings("", diags)
+
+	log.Printf("[INFO] loadConfig calling initializePlugins")
+
+	// resolve the plugins for each connection and create default plugin config
+	// for all plugins mentioned in connection config which have no explicit config
+	steampipeConfig.initializePlugins()
+
+	return res
+}
+
+func getConfigPaths(configFolder string, opts *loadConfigOptions) ([]string, error) {
+	return filehelpers.ListFiles(configFolder, &filehelpers.ListOptions{
+		Flags:   filehelpers.FilesFlat,
+		Include: opts.include,
+	})
+}
+
+// decodeConfig parses the given config files and decodes the blocks into steampipeConfig
+// all blocks are decoded, even if there are errors, so that all problems are returned in the diagnostics
+func decodeConfig(configFolder string, configPaths []string, steampipeConfig *SteampipeConfig, opts *loadConfigOptions) hcl.Diagnostics {
 	fileData, diags := parse.LoadFileData(configPaths...)
 	if diags.HasErrors() {
-		log.Printf("[WARN] loadConfig: failed to load all config files: %v\n", err)
-		return error_helpers.DiagsToErrorsAndWarnings("Failed to load all config files", diags)
+		log.Printf("[WARN] loadConfig: failed to load all config files: %s\n", diags.Error())
+		return diags
 	}
 
 	body, diags := parse.ParseHclFiles(fileData)
 	if diags.HasErrors() {
-		return error_helpers.DiagsToErrorsAndWarnings("Failed to load all config files", diags)
+		return diags
 	}
 
 	// do a partial decode
 	content, moreDiags := body.Content(parse.ConfigBlockSchema)
 	if moreDiags.HasErrors() {
 		diags = append(diags, moreDiags...)
-		return error_helpers.DiagsToErrorsAndWarnings("Failed to load config", diags)
+		return diags
 	}
 
 	// store block types which we have found in this folder - each is only allowed once
@@ -259,28 +281,66 @@ func loadConfig(configFolder string, steampipeConfig *SteampipeConfig, opts *loa
 			// add plugin to steampipeConfig
 			// NOTE: this errors if there is a plugin block with a duplicate label
 			if err := steampipeConfig.addPlugin(plugin, block); err != nil {
-				return error_helpers.NewErrorsAndWarning(err)
+				diags = append(diags, blockErrorDiag(err, block))
 			}
 
 		case modconfig.BlockTypeConnection:
-			connection, moreDiags := parse.DecodeConnection(block)
+			// a connection block with no plugin which defines tables is a cross-plugin aggregator
+			if parse.IsCrossPluginAggregator(block) {
+				aggregator, moreDiags := parse.DecodeCrossPluginAggregator(block, configFolder)
+				diags = append(diags, moreDiags...)
+				if moreDiags.HasErrors() {
+					continue
+				}
+				if existing, alreadyThere := steampipeConfig.connectionDeclRange(aggregator.Name); alreadyThere {
+					diags = append(diags, blockErrorDiag(getDuplicateConnectionNameError(aggregator.Name, existing, aggregator.DeclRange), block))
+					continue
+				}
+				if ok, errorMessage := db_common.IsSchemaNameValid(aggregator.Name); !ok {
+					diags = append(diags, blockErrorDiag(sperr.New("invalid connection name: '%s' in '%s'. %s ", aggregator.Name, block.TypeRange.Filename, errorMessage), block))
+					continue
+				}
+				steampipeConfig.CrossPluginAggregators[aggregator.Name] = aggregator
+				continue
+			}
+
+			// NOTE: a connection block with a for_each attribute is a template which may produce many connections
+			connections, moreDiags := parse.DecodeConnections(block, configFolder)
 			diags = append(diags, moreDiags...)
 			if moreDiags.HasErrors() {
 				continue
 			}
-			if existingConnection, alreadyThere := steampipeConfig.Connections[connection.Name]; alreadyThere {
-				err := getDuplicateConnectionError(existingConnection, connection)
-				return error_helpers.NewErrorsAndWarning(err)
+			for _, connection := range connections {
+				if existing, alreadyThere := steampipeConfig.connectionDeclRange(connection.Name); alreadyThere {
+					diags = append(diags, blockErrorDiag(getDuplicateConnectionNameError(connection.Name, existing, connection.DeclRange), block))
+					continue
+				}
+				if ok, errorMessage := db_common.IsSchemaNameValid(connection.Name); !ok {
+					diags = append(diags, blockErrorDiag(sperr.New("invalid connection name: '%s' in '%s'. %s ", connection.Name, block.TypeRange.Filename, errorMessage), block))
+					continue
+				}
+				steampipeConfig.Connections[connection.Name] = connection
+			}
+
+		case modconfig.BlockTypeGitHost:
+			gitHost, moreDiags := parse.DecodeGitHost(block)
+			diags = append(diags, moreDiags...)
+			if moreDiags.HasErrors() {
+				continue
 			}
-			if ok, errorMessage := db_common.IsSchemaNameValid(connection.Name); !ok {
-				return error_helpers.NewErrorsAndWarning(sperr.New("invalid connection name: '%s' in '%s'. %s ", connection.Name, block.TypeRange.Filename, errorMessage))
+			if existing, alreadyThere := steampipeConfig.GitHosts[gitHost.Host]; alreadyThere {
+				diags = append(diags, blockErrorDiag(sperr.New("duplicate git_host: '%s'\n\t(%s:%d)\n\t(%s:%d)",
+					gitHost.Host, existing.DeclRange.Filename, existing.DeclRange.Start.Line,
+					gitHost.DeclRange.Filename, gitHost.DeclRange.Start.Line), block))
+				continue
 			}
-			steampipeConfig.Connections[connection.Name] = connection
+			steampipeConfig.GitHosts[gitHost.Host] = gitHost
 
 		case modconfig.BlockTypeOptions:
 			// check this options type is permitted based on the options passed in
 			if err := optionsBlockPermitted(block, optionBlockMap, opts); err != nil {
-				return error_helpers.NewErrorsAndWarning(err)
+				diags = append(diags, blockErrorDiag(err, block))
+				continue
 			}
 			opts, moreDiags := parse.DecodeOptions(block)
 			if moreDiags.HasErrors() {
@@ -295,7 +355,8 @@ func loadConfig(configFolder string, steampipeConfig *SteampipeConfig, opts *loa
 				// we should never get an error here, since SetOptions
 				// only sets warnings
 				// putting this here only for good-practice
-				return e
+				diags = append(diags, blockErrorDiag(e.GetError(), block))
+				continue
 			}
 			if len(e.Warnings) > 0 {
 				for _, warning := range e.Warnings {
@@ -309,25 +370,21 @@ func loadConfig(configFolder string, steampipeConfig *SteampipeConfig, opts *loa
 		}
 	}
 
-	if diags.HasErrors() {
-		return error_helpers.DiagsToErrorsAndWarnings("Failed to load config", diags)
-	}
-
-	res := error_helpers.DiagsToErrorsAndWarnings("", diags)
-
-	log.Printf("[INFO] loadConfig calling initializePlugins")
-
-	// resolve the plugins for each connection and create default plugin config
-	// for all plugins mentioned in connection config which have no explicit config
-	steampipeConfig.initializePlugins()
+	return diags
+}
 
-	return res
+func blockErrorDiag(err error, block *hcl.Block) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  err.Error(),
+		Subject:  hcl_helpers.BlockRangePointer(block),
+	}
 }
 
-func getDuplicateConnectionError(existingConnection, newConnection *modconfig.Connection) error {
+func getDuplicateConnectionNameError(name string, existingRange, newRange modconfig.Range) error {
 	return sperr.New("duplicate connection name: '%s'\n\t(%s:%d)\n\t(%s:%d)",
-		existingConnection.Name, existingConnection.DeclRange.Filename, existingConnection.DeclRange.Start.Line,
-		newConnection.DeclRange.Filename, newConnection.DeclRange.Start.Line)
+		name, existingRange.Filename, existingRange.Start.Line,
+		newRange.Filename, newRange.Start.Line)
 }
 
 func optionsBlockPermitted(block *hcl.Block, blockMap map[string]bool, opts *loadConfigOptions) error {
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -35,7 +36,7 @@ func LoadConnectionState(ctx context.Context, conn *pgx.Conn, opts ...LoadConnec
 	// TODO this time can be reduced once all; plugins are using v5.4.1 of the sdk
 	maxDuration := 1 * time.Minute
 	retryInterval := 250 * time.Millisecond
-	if config.WaitMode == WaitForReady || config.WaitMode == WaitForSearchPath {
+	if config.WaitMode == WaitForReady || config.WaitMode == WaitForSearchPath || config.WaitMode == WaitForLazyLoad {
 		// is we are waiting for all connections to be ready, wait up to 10 minutes
 		maxDuration = 10 * time.Minute
 	}
@@ -83,7 +84,14 @@ func LoadConnectionState(ctx context.Context, conn *pgx.Conn, opts ...LoadConnec
 				return fmt.Errorf("all connections in search path are in error")
 			}
 			return nil
-
+		case WaitForLazyLoad:
+			// wait for the plugin manager to start importing the schemas of the lazy connections
+			if lazyConnections := connectionStateMap.LazyConnections(config.Connections...); len(lazyConnections) > 0 {
+				statushooks.SetStatus(ctx, fmt.Sprintf("Importing schema for %s", GetLazyConnectionsDescription(lazyConnections)))
+				return retry.RetryableError(fmt.Errorf("timed out waiting for schema import of %s", GetLazyConnectionsDescription(lazyConnections)))
+			}
+			// now wait for the import to complete
+			return checkConnectionsAreReady(ctx, connectionStateMap, config)
 		}
 		return nil
 
@@ -187,6 +195,11 @@ func GetLoadingConnectionStatusMessage(connectionStateMap ConnectionStateMap, re
 	return loadedMessage
 }
 
+// GetLazyConnectionsDescription returns a description of the given lazy connections, for use in messages
+func GetLazyConnectionsDescription(connectionNames []string) string {
+	return fmt.Sprintf("%s '%s'", utils.Pluralize("connection", len(connectionNames)), strings.Join(connectionNames, "', '"))
+}
+
 func SaveConnectionStateFile(res *RefreshConnectionResult, connectionUpdates *ConnectionUpdates) {
 	// now serialise the connection state
 	connectionState := make(ConnectionStateMap, len(connectionUpdates.FinalConnectionState))
@@ -7,6 +7,7 @@ const (
 	WaitForLoading
 	WaitForReady
 	WaitForSearchPath
+	WaitForLazyLoad
 )
 
 type LoadConnectionStateConfiguration struct {
@@ -38,3 +39,11 @@ var WithWaitUntilReady = func(connections ...string) func(config *LoadConnection
 		config.WaitMode = WaitForReady
 	}
 }
+
+// WithWaitForLazyLoad waits until the schemas of the given connections, which have lazy schema import, have been imported
+var WithWaitForLazyLoad = func(connections ...string) func(config *LoadConnectionStateConfiguration) {
+	return func(config *LoadConnectionStateConfiguration) {
+		config.Connections = connections
+		config.WaitMode = WaitForLazyLoad
+	}
+}
@@ -128,6 +128,10 @@ func loadModDependency(ctx context.Context, modDependency *versionmap.ResolvedVe
 	if err != nil {
 		return err
 	}
+	// verify the dependency has not been modified since it was installed
+	if err := versionmap.VerifyInstalledDependency(modDependency, parseCtx.WorkspaceLock.ModInstallationPath); err != nil {
+		return err
+	}
 
 	// we need to modify the ListOptions to ensure we include hidden files - these are excluded by default
 	prevExclusions := parseCtx.ListOptions.Exclude
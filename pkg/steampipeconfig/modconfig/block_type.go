@@ -38,6 +38,7 @@ const (
 	BlockTypeConnection       = "connection"
 	BlockTypeOptions          = "options"
 	BlockTypeWorkspaceProfile = "workspace"
+	BlockTypeGitHost          = "git_host"
 
 	ResourceTypeSnapshot = "snapshot"
 	AttributeArgs        = "args"
@@ -21,9 +21,11 @@ const (
 	ConnectionTypeAggregator = "aggregator"
 	ImportSchemaEnabled      = "enabled"
 	ImportSchemaDisabled     = "disabled"
+	// ImportSchemaLazy defers the schema import until the connection is first referenced by a query or search path
+	ImportSchemaLazy = "lazy"
 )
 
-var ValidImportSchemaValues = []string{ImportSchemaEnabled, ImportSchemaDisabled}
+var ValidImportSchemaValues = []string{ImportSchemaEnabled, ImportSchemaDisabled, ImportSchemaLazy}
 
 // Connection is a struct representing the partially parsed connection
 //
@@ -45,11 +47,24 @@ type Connection struct {
 	PluginPath *string
 	// connection type - supported values: "aggregator"
 	Type string `json:"type,omitempty"`
-	// should a schema be created for this connection - supported values: "enabled", "disabled"
+	// should a schema be created for this connection - supported values: "enabled", "disabled", "lazy"
 	ImportSchema string `json:"import_schema"`
+	// connections with a higher priority have their schemas imported first
+	Priority int `json:"priority,omitempty"`
 	// list of names or wildcards which are resolved to connections
 	// (only valid for "aggregator" type)
 	ConnectionNames []string `json:"connections,omitempty"`
+	// map of tag names to values (or wildcards) - connections with matching tags are resolved as children
+	// (only valid for "aggregator" type)
+	ConnectionTagSelector map[string]string `json:"connection_selector,omitempty"`
+	// tags which may be used to select this connection as a child of an aggregator
+	Tags map[string]string `json:"tags,omitempty"`
+	// list of table names or wildcards - if set, only matching tables are imported into the connection schema
+	TablesInclude []string `json:"tables_include,omitempty"`
+	// list of table names or wildcards - matching tables are not imported into the connection schema
+	TablesExclude []string `json:"tables_exclude,omitempty"`
+	// map of table name to a sql condition which is applied to every query of the table
+	DefaultQuals map[string]string `json:"default_quals,omitempty"`
 	// a map of the resolved child connections
 	// (only valid for "aggregator" type)
 	Connections map[string]*Connection `json:"-"`
@@ -131,6 +146,11 @@ func (c *Connection) ImportDisabled() bool {
 	return c.ImportSchema == constants.ConnectionStateDisabled
 }
 
+// ImportLazy returns whether the schema is only imported when the connection is first referenced
+func (c *Connection) ImportLazy() bool {
+	return c.ImportSchema == ImportSchemaLazy
+}
+
 func (c *Connection) Equals(other *Connection) bool {
 	connectionOptionsEqual := (c.Options == nil) == (other.Options == nil)
 	if c.Options != nil {
@@ -140,9 +160,15 @@ func (c *Connection) Equals(other *Connection) bool {
 		c.Plugin == other.Plugin &&
 		c.Type == other.Type &&
 		strings.Join(c.ConnectionNames, ",") == strings.Join(other.ConnectionNames, ",") &&
+		maps.Equal(c.ConnectionTagSelector, other.ConnectionTagSelector) &&
+		maps.Equal(c.Tags, other.Tags) &&
+		strings.Join(c.TablesInclude, ",") == strings.Join(other.TablesInclude, ",") &&
+		strings.Join(c.TablesExclude, ",") == strings.Join(other.TablesExclude, ",") &&
+		maps.Equal(c.DefaultQuals, other.DefaultQuals) &&
 		connectionOptionsEqual &&
 		c.Config == other.Config &&
-		c.ImportSchema == other.ImportSchema
+		c.ImportSchema == other.ImportSchema &&
+		c.Priority == other.Priority
 
 }
 
@@ -175,6 +201,12 @@ func (c *Connection) Validate(map[string]*Connection) (warnings []string, errors
 	if !helpers.StringSliceContains(validConnectionTypes, c.Type) {
 		return nil, []string{fmt.Sprintf("connection '%s' has invalid connection type '%s'", c.Name, c.Type)}
 	}
+	if errors := c.validateTableFilter(); len(errors) > 0 {
+		return nil, errors
+	}
+	if errors := c.validateDefaultQuals(); len(errors) > 0 {
+		return nil, errors
+	}
 
 	if c.Type == ConnectionTypeAggregator {
 		return c.ValidateAggregatorConnection()
@@ -186,6 +218,9 @@ func (c *Connection) Validate(map[string]*Connection) (warnings []string, errors
 	if len(c.ConnectionNames) != 0 {
 		validationErrors = append(validationErrors, fmt.Sprintf("connection '%s' has %d children, but is not of type 'aggregator'", c.Name, len(c.ConnectionNames)))
 	}
+	if len(c.ConnectionTagSelector) != 0 {
+		validationErrors = append(validationErrors, fmt.Sprintf("connection '%s' has a connection tag selector, but is not of type 'aggregator'", c.Name))
+	}
 	validImportSchemaValues := utils.SliceToLookup(ValidImportSchemaValues)
 	if _, isValid := validImportSchemaValues[c.ImportSchema]; !isValid {
 		validationErrors = append(validationErrors, fmt.Sprintf("invalid value '%s'for import_schema, must be one of ['%s']", c.ImportSchema, strings.Join(ValidImportSchemaValues, "','")))
@@ -195,6 +230,104 @@ func (c *Connection) Validate(map[string]*Connection) (warnings []string, errors
 
 }
 
+// validateTableFilter verifies the tables_include and tables_exclude patterns are valid wildcards
+func (c *Connection) validateTableFilter() []string {
+	var validationErrors []string
+	for _, patterns := range [][]string{c.TablesInclude, c.TablesExclude} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				validationErrors = append(validationErrors, fmt.Sprintf("connection '%s' has invalid table pattern '%s'", c.Name, pattern))
+			}
+		}
+	}
+	return validationErrors
+}
+
+// validateDefaultQuals verifies each default qual specifies a valid table name and a condition
+func (c *Connection) validateDefaultQuals() []string {
+	var validationErrors []string
+	for _, table := range utils.SortedMapKeys(c.DefaultQuals) {
+		if table == "" {
+			validationErrors = append(validationErrors, fmt.Sprintf("connection '%s' has a default qual with no table name", c.Name))
+			continue
+		}
+		if strings.TrimSpace(c.DefaultQuals[table]) == "" {
+			validationErrors = append(validationErrors, fmt.Sprintf("connection '%s' has an empty default qual for table '%s'", c.Name, table))
+		} else if err := validateDefaultQualCondition(c.DefaultQuals[table]); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("connection '%s' has an invalid default qual for table '%s': %s", c.Name, table, err.Error()))
+		}
+		// the foreign table is renamed when the default qual is applied - verify the new name is a valid identifier
+		if len(constants.UnrestrictedTablePrefix+table) > constants.MaxIdentifierLength {
+			validationErrors = append(validationErrors, fmt.Sprintf("connection '%s' cannot have a default qual for table '%s' - the table name is too long", c.Name, table))
+		}
+	}
+	return validationErrors
+}
+
+// validateDefaultQualCondition verifies a default qual condition cannot escape the where clause of the view it is applied to
+// - it must not contain statement separators, comments or dollar quoting, and its parentheses must be balanced
+// (the contents of quoted strings and identifiers are not checked)
+func validateDefaultQualCondition(condition string) error {
+	depth := 0
+	for i := 0; i < len(condition); i++ {
+		switch c := condition[i]; {
+		case c == '\'':
+			// a string with an E prefix may contain backslash escapes
+			backslashEscapes := i > 0 && (condition[i-1] == 'e' || condition[i-1] == 'E') && (i == 1 || !isIdentifierChar(condition[i-2]))
+			end, ok := endOfQuoted(condition, i, '\'', backslashEscapes)
+			if !ok {
+				return fmt.Errorf("unterminated quoted string")
+			}
+			i = end
+		case c == '"':
+			end, ok := endOfQuoted(condition, i, '"', false)
+			if !ok {
+				return fmt.Errorf("unterminated quoted identifier")
+			}
+			i = end
+		case c == ';':
+			return fmt.Errorf("must not contain ';'")
+		case c == '$':
+			return fmt.Errorf("must not contain '$'")
+		case strings.HasPrefix(condition[i:], "--"), strings.HasPrefix(condition[i:], "/*"):
+			return fmt.Errorf("must not contain comments")
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("unbalanced parentheses")
+			}
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("unbalanced parentheses")
+	}
+	return nil
+}
+
+// endOfQuoted returns the index of the quote which closes the quoted string or identifier starting at index start
+// a doubled quote is an escaped quote, as is any character preceded by a backslash if backslashEscapes is set
+func endOfQuoted(s string, start int, quote byte, backslashEscapes bool) (int, bool) {
+	for i := start + 1; i < len(s); i++ {
+		switch {
+		case backslashEscapes && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
 func (c *Connection) ValidateAggregatorConnection() (warnings, errors []string) {
 	if len(c.Connections) == 0 {
 		/// there should be at least one connection - raise as warning
@@ -220,6 +353,11 @@ func (c *Connection) ValidateAggregatorConnection() (warnings, errors []string)
 }
 
 func (c *Connection) GetEmptyAggregatorError() string {
+	if len(c.ConnectionTagSelector) > 0 {
+		return fmt.Sprintf("aggregator '%s' with tag selector %s matches no connections",
+			c.Name,
+			c.connectionTagSelectorString())
+	}
 	patterns := c.ConnectionNames
 	if len(patterns) == 0 {
 		return fmt.Sprintf("aggregator '%s' defines no child connections", c.Name)
@@ -238,6 +376,18 @@ func (c *Connection) PopulateChildren(connectionMap map[string]*Connection) []st
 	log.Printf("[TRACE] Connection.PopulateChildren for aggregator connection %s", c.Name)
 	c.Connections = make(map[string]*Connection)
 	var failures []string
+	// the aggregator queries its children through the plugin, bypassing the views which apply default quals
+	// - so connections with default quals cannot be children
+	excluded := make(map[string]struct{})
+	excludeChild := func(name string) {
+		if _, ok := excluded[name]; ok {
+			return
+		}
+		excluded[name] = struct{}{}
+		msg := fmt.Sprintf("aggregator connection %s cannot include child connection %s as it has default quals", c.Name, name)
+		log.Println("[WARN]", msg)
+		failures = append(failures, msg)
+	}
 	for _, childPattern := range c.ConnectionNames {
 		// if this resolves as an existing connection, populate it
 		if childConnection, ok := connectionMap[childPattern]; ok {
@@ -247,6 +397,8 @@ func (c *Connection) PopulateChildren(connectionMap map[string]*Connection) []st
 					c.Name, childPattern)
 				log.Println("[WARN]", msg)
 				failures = append(failures, msg)
+			} else if len(childConnection.DefaultQuals) > 0 {
+				excludeChild(childPattern)
 			} else {
 				log.Printf("[TRACE] Connection.PopulateChildren found matching connection %s", childPattern)
 				c.Connections[childPattern] = childConnection
@@ -267,17 +419,64 @@ func (c *Connection) PopulateChildren(connectionMap map[string]*Connection) []st
 			}
 			if match, _ := path.Match(childPattern, name); match {
 				// verify that this connection is the same plugin instance
-				if connection.PluginInstance == c.PluginInstance {
+				if connection.PluginInstance != c.PluginInstance {
+					continue
+				}
+				if len(connection.DefaultQuals) > 0 {
+					excludeChild(name)
+				} else {
 					c.Connections[name] = connection
 					log.Printf("[TRACE] connection '%s' matches pattern '%s'", name, childPattern)
 				}
 			}
 		}
 	}
+
+	// if there is a tag selector, add all connections whose tags match
+	if len(c.ConnectionTagSelector) > 0 {
+		for name, connection := range connectionMap {
+			// if this is an aggregator connection, skip (this will also avoid us adding ourselves)
+			if connection.Type == ConnectionTypeAggregator {
+				continue
+			}
+			if connection.PluginInstance != c.PluginInstance || !connection.MatchesTagSelector(c.ConnectionTagSelector) {
+				continue
+			}
+			if len(connection.DefaultQuals) > 0 {
+				excludeChild(name)
+			} else {
+				c.Connections[name] = connection
+				log.Printf("[TRACE] connection '%s' matches tag selector %s", name, c.connectionTagSelectorString())
+			}
+		}
+	}
 	c.ResolvedConnectionNames = maps.Keys(c.Connections)
 	return failures
 }
 
+// MatchesTagSelector returns whether the connection has all the tags in the selector
+// selector values may be wildcards
+func (c *Connection) MatchesTagSelector(selector map[string]string) bool {
+	for tag, pattern := range selector {
+		value, ok := c.Tags[tag]
+		if !ok {
+			return false
+		}
+		if match, _ := path.Match(pattern, value); !match {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Connection) connectionTagSelectorString() string {
+	var selectorStrings []string
+	for _, tag := range utils.SortedMapKeys(c.ConnectionTagSelector) {
+		selectorStrings = append(selectorStrings, fmt.Sprintf("%s = \"%s\"", tag, c.ConnectionTagSelector[tag]))
+	}
+	return fmt.Sprintf("{ %s }", strings.Join(selectorStrings, ", "))
+}
+
 // GetResolveConnectionNames return the names of all child connections
 // (will only be non-empty for aggregator connections)
 func (c *Connection) GetResolveConnectionNames() []string {
@@ -1,6 +1,12 @@
 package modconfig
 
-import "testing"
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/maps"
+)
 
 type connectionEquality struct {
 	connection1 *Connection
@@ -36,3 +42,176 @@ func TestConnectionEquals(t *testing.T) {
 		}
 	}
 }
+
+type populateChildrenTest struct {
+	aggregator *Connection
+	expected   []string
+}
+
+func TestPopulateChildrenTagSelector(t *testing.T) {
+	pluginInstance := "aws"
+	otherPluginInstance := "aws_other"
+	connectionMap := map[string]*Connection{
+		"aws_prod_payments": {Name: "aws_prod_payments", PluginInstance: &pluginInstance, Tags: map[string]string{"env": "prod", "team": "payments"}},
+		"aws_prod_search":   {Name: "aws_prod_search", PluginInstance: &pluginInstance, Tags: map[string]string{"env": "prod", "team": "search"}},
+		"aws_dev_payments":  {Name: "aws_dev_payments", PluginInstance: &pluginInstance, Tags: map[string]string{"env": "dev", "team": "payments"}},
+		"aws_untagged":      {Name: "aws_untagged", PluginInstance: &pluginInstance},
+		"aws_other_prod":    {Name: "aws_other_prod", PluginInstance: &otherPluginInstance, Tags: map[string]string{"env": "prod"}},
+	}
+	tests := map[string]populateChildrenTest{
+		"single tag": {
+			aggregator: &Connection{Name: "aws_prod", Type: ConnectionTypeAggregator, PluginInstance: &pluginInstance, ConnectionTagSelector: map[string]string{"env": "prod"}},
+			expected:   []string{"aws_prod_payments", "aws_prod_search"},
+		},
+		"multiple tags": {
+			aggregator: &Connection{Name: "aws_prod_pay", Type: ConnectionTypeAggregator, PluginInstance: &pluginInstance, ConnectionTagSelector: map[string]string{"env": "prod", "team": "payments"}},
+			expected:   []string{"aws_prod_payments"},
+		},
+		"wildcard value": {
+			aggregator: &Connection{Name: "aws_pay", Type: ConnectionTypeAggregator, PluginInstance: &pluginInstance, ConnectionTagSelector: map[string]string{"team": "pay*"}},
+			expected:   []string{"aws_dev_payments", "aws_prod_payments"},
+		},
+		"no match": {
+			aggregator: &Connection{Name: "aws_test", Type: ConnectionTypeAggregator, PluginInstance: &pluginInstance, ConnectionTagSelector: map[string]string{"env": "test"}},
+			expected:   []string{},
+		},
+	}
+	for name, test := range tests {
+		test.aggregator.PopulateChildren(connectionMap)
+		res := test.aggregator.ResolvedConnectionNames
+		sort.Strings(res)
+		if strings.Join(res, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("Test: '%s' FAILED: expected: %v, actual: %v", name, test.expected, res)
+		}
+	}
+}
+
+type populateChildrenDefaultQualsTest struct {
+	aggregator       *Connection
+	expected         []string
+	expectedFailures []string
+}
+
+func TestPopulateChildrenDefaultQuals(t *testing.T) {
+	pluginInstance := "aws"
+	defaultQuals := map[string]string{"aws_ec2_instance": "region like 'eu-%'"}
+	connectionMap := map[string]*Connection{
+		"aws_prod":       {Name: "aws_prod", PluginInstance: &pluginInstance, Tags: map[string]string{"env": "prod"}},
+		"aws_dev":        {Name: "aws_dev", PluginInstance: &pluginInstance, Tags: map[string]string{"env": "dev"}},
+		"aws_restricted": {Name: "aws_restricted", PluginInstance: &pluginInstance, Tags: map[string]string{"env": "prod"}, DefaultQuals: defaultQuals},
+	}
+	tests := map[string]populateChildrenDefaultQualsTest{
+		"named child": {
+			aggregator:       &Connection{Name: "aws_all", Type: ConnectionTypeAggregator, PluginInstance: &pluginInstance, ConnectionNames: []string{"aws_prod", "aws_restricted"}},
+			expected:         []string{"aws_prod"},
+			expectedFailures: []string{"aggregator connection aws_all cannot include child connection aws_restricted as it has default quals"},
+		},
+		"wildcard": {
+			aggregator:       &Connection{Name: "aws_all", Type: ConnectionTypeAggregator, PluginInstance: &pluginInstance, ConnectionNames: []string{"aws_*"}},
+			expected:         []string{"aws_dev", "aws_prod"},
+			expectedFailures: []string{"aggregator connection aws_all cannot include child connection aws_restricted as it has default quals"},
+		},
+		"wildcard and tag selector": {
+			aggregator:       &Connection{Name: "aws_all", Type: ConnectionTypeAggregator, PluginInstance: &pluginInstance, ConnectionNames: []string{"aws_*"}, ConnectionTagSelector: map[string]string{"env": "prod"}},
+			expected:         []string{"aws_dev", "aws_prod"},
+			expectedFailures: []string{"aggregator connection aws_all cannot include child connection aws_restricted as it has default quals"},
+		},
+		"no restricted children": {
+			aggregator: &Connection{Name: "aws_dev_only", Type: ConnectionTypeAggregator, PluginInstance: &pluginInstance, ConnectionTagSelector: map[string]string{"env": "dev"}},
+			expected:   []string{"aws_dev"},
+		},
+	}
+	for name, test := range tests {
+		failures := test.aggregator.PopulateChildren(connectionMap)
+		res := test.aggregator.ResolvedConnectionNames
+		sort.Strings(res)
+		if strings.Join(res, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("Test: '%s' FAILED: expected: %v, actual: %v", name, test.expected, res)
+		}
+		if strings.Join(failures, "\n") != strings.Join(test.expectedFailures, "\n") {
+			t.Errorf("Test: '%s' FAILED: expected failures: %v, actual: %v", name, test.expectedFailures, failures)
+		}
+	}
+}
+
+func TestCrossPluginAggregatorPopulateChildren(t *testing.T) {
+	connectionMap := map[string]*Connection{
+		"aws_prod":    {Name: "aws_prod", ImportSchema: ImportSchemaEnabled},
+		"aws_archive": {Name: "aws_archive", ImportSchema: ImportSchemaLazy},
+		"aws_old":     {Name: "aws_old", ImportSchema: ImportSchemaDisabled},
+		"aws_all":     {Name: "aws_all", Type: ConnectionTypeAggregator, ImportSchema: ImportSchemaEnabled},
+	}
+	aggregator := &CrossPluginAggregator{Name: "all", ConnectionNames: []string{"aws_*"}}
+	aggregator.PopulateChildren(connectionMap)
+
+	// connections with lazy schema import are included, so are added to the views once their schema is imported
+	expected := []string{"aws_archive", "aws_prod"}
+	res := maps.Keys(aggregator.Connections)
+	sort.Strings(res)
+	if strings.Join(res, ",") != strings.Join(expected, ",") {
+		t.Errorf("Test: '%s' FAILED: expected: %v, actual: %v", "cross-plugin aggregator children", expected, res)
+	}
+}
+
+type validateDefaultQualsTest struct {
+	defaultQuals map[string]string
+	expected     []string
+}
+
+var testCasesValidateDefaultQuals = map[string]validateDefaultQualsTest{
+	"valid": {
+		defaultQuals: map[string]string{"aws_ec2_instance": "region like 'eu-%'"},
+	},
+	"empty qual": {
+		defaultQuals: map[string]string{"aws_ec2_instance": " "},
+		expected:     []string{"connection 'aws' has an empty default qual for table 'aws_ec2_instance'"},
+	},
+	"quoted separators and comments": {
+		defaultQuals: map[string]string{"aws_ec2_instance": `tags ->> 'owner;--' = 'it''s (me' and "col/*" is not null`},
+	},
+	"nested parentheses": {
+		defaultQuals: map[string]string{"aws_ec2_instance": "(region = 'eu-west-1' or (region = 'eu-west-2' and account_id = '123'))"},
+	},
+	"statement separator": {
+		defaultQuals: map[string]string{"aws_ec2_instance": "true; drop table foo"},
+		expected:     []string{"connection 'aws' has an invalid default qual for table 'aws_ec2_instance': must not contain ';'"},
+	},
+	"line comment": {
+		defaultQuals: map[string]string{"aws_ec2_instance": "true -- region = 'eu-west-1'"},
+		expected:     []string{"connection 'aws' has an invalid default qual for table 'aws_ec2_instance': must not contain comments"},
+	},
+	"block comment": {
+		defaultQuals: map[string]string{"aws_ec2_instance": "true /* region = 'eu-west-1' */"},
+		expected:     []string{"connection 'aws' has an invalid default qual for table 'aws_ec2_instance': must not contain comments"},
+	},
+	"dollar quoting": {
+		defaultQuals: map[string]string{"aws_ec2_instance": "region = $$eu-west-1$$"},
+		expected:     []string{"connection 'aws' has an invalid default qual for table 'aws_ec2_instance': must not contain '$'"},
+	},
+	"escape from where clause": {
+		defaultQuals: map[string]string{"aws_ec2_instance": "true) union select * from secrets where (true"},
+		expected:     []string{"connection 'aws' has an invalid default qual for table 'aws_ec2_instance': unbalanced parentheses"},
+	},
+	"escape from where clause with escaped quote": {
+		defaultQuals: map[string]string{"aws_ec2_instance": `region = E'\'' or true) union select * from secrets where (E'\'' = ''`},
+		expected:     []string{"connection 'aws' has an invalid default qual for table 'aws_ec2_instance': unbalanced parentheses"},
+	},
+	"unterminated string": {
+		defaultQuals: map[string]string{"aws_ec2_instance": "region = 'eu-west-1"},
+		expected:     []string{"connection 'aws' has an invalid default qual for table 'aws_ec2_instance': unterminated quoted string"},
+	},
+	"table name too long": {
+		defaultQuals: map[string]string{strings.Repeat("t", 50): "region like 'eu-%'"},
+		expected:     []string{"connection 'aws' cannot have a default qual for table '" + strings.Repeat("t", 50) + "' - the table name is too long"},
+	},
+}
+
+func TestValidateDefaultQuals(t *testing.T) {
+	for name, test := range testCasesValidateDefaultQuals {
+		connection := &Connection{Name: "aws", Type: ConnectionTypePlugin, ImportSchema: ImportSchemaEnabled, DefaultQuals: test.defaultQuals}
+		_, errors := connection.Validate(nil)
+		if strings.Join(errors, "\n") != strings.Join(test.expected, "\n") {
+			t.Errorf("Test: '%s' FAILED : expected %v, got %v", name, test.expected, errors)
+		}
+	}
+}
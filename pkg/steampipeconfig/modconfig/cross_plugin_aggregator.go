@@ -0,0 +1,123 @@
+package modconfig
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/go-kit/hcl_helpers"
+	"golang.org/x/exp/maps"
+)
+
+// CrossPluginAggregator is an aggregator connection whose child connections may use different plugins
+//
+// Rather than the tables of a single plugin, it exposes a set of user defined tables, each of which maps
+// plugin specific tables and columns to a common set of columns.
+// These are created as views in the aggregator schema, returning the union of the source tables of all child connections
+type CrossPluginAggregator struct {
+	// connection name
+	Name string `json:"name"`
+	// list of names or wildcards which are resolved to connections
+	ConnectionNames []string `json:"connections,omitempty"`
+	// map of tag names to values (or wildcards) - connections with matching tags are resolved as children
+	ConnectionTagSelector map[string]string `json:"connection_selector,omitempty"`
+	// the aggregator tables
+	Tables []*AggregatorTable `json:"tables"`
+	// a map of the resolved child connections
+	Connections map[string]*Connection `json:"-"`
+	DeclRange   Range                  `json:"decl_range"`
+}
+
+// AggregatorTable is a table of a CrossPluginAggregator
+type AggregatorTable struct {
+	Name string `json:"name"`
+	// the columns of the table - if not set, the columns common to all source tables are used
+	Columns []string `json:"columns,omitempty"`
+	// the plugin tables which provide the rows of this table
+	Sources []*AggregatorTableSource `json:"sources"`
+}
+
+// AggregatorTableSource is a plugin table providing rows for an AggregatorTable
+type AggregatorTableSource struct {
+	// the name of the plugin table
+	Table string `json:"table"`
+	// map of aggregator table column name to source table column name
+	// columns which are not mapped are assumed to have the same name in the source table
+	ColumnMap map[string]string `json:"column_map,omitempty"`
+}
+
+func NewCrossPluginAggregator(block *hcl.Block) *CrossPluginAggregator {
+	return &CrossPluginAggregator{
+		Name:      block.Labels[0],
+		DeclRange: NewRange(hcl_helpers.BlockRange(block)),
+	}
+}
+
+// SourceColumn returns the name of the source table column which provides the given aggregator table column
+func (s *AggregatorTableSource) SourceColumn(column string) string {
+	if sourceColumn, ok := s.ColumnMap[column]; ok {
+		return sourceColumn
+	}
+	return column
+}
+
+func (a *CrossPluginAggregator) String() string {
+	var tableStrings []string
+	for _, t := range a.Tables {
+		var sourceStrings []string
+		for _, s := range t.Sources {
+			sourceStrings = append(sourceStrings, fmt.Sprintf("%s %v", s.Table, s.ColumnMap))
+		}
+		tableStrings = append(tableStrings, fmt.Sprintf("%s (%s): %s", t.Name, strings.Join(t.Columns, ","), strings.Join(sourceStrings, ", ")))
+	}
+	return fmt.Sprintf("\n----\nName: %s\nConnections: %s %v\nTables:\n%s\n", a.Name, strings.Join(a.ConnectionNames, ","), a.ConnectionTagSelector, strings.Join(tableStrings, "\n"))
+}
+
+// Validate verifies the aggregator has at least one child connection and all tables have at least one source
+func (a *CrossPluginAggregator) Validate() (warnings []string, errors []string) {
+	if len(a.ConnectionNames) == 0 && len(a.ConnectionTagSelector) == 0 {
+		return nil, []string{fmt.Sprintf("aggregator '%s' defines no child connections", a.Name)}
+	}
+	if len(a.Tables) == 0 {
+		errors = append(errors, fmt.Sprintf("aggregator '%s' has no plugin and defines no tables", a.Name))
+	}
+	tableNames := make(map[string]struct{})
+	for _, t := range a.Tables {
+		if _, ok := tableNames[t.Name]; ok {
+			errors = append(errors, fmt.Sprintf("aggregator '%s' defines table '%s' more than once", a.Name, t.Name))
+		}
+		tableNames[t.Name] = struct{}{}
+		if len(t.Sources) == 0 {
+			errors = append(errors, fmt.Sprintf("table '%s' of aggregator '%s' has no sources", t.Name, a.Name))
+		}
+	}
+	if len(errors) == 0 && len(a.Connections) == 0 {
+		warnings = append(warnings, fmt.Sprintf("aggregator '%s' matches no connections", a.Name))
+	}
+	return warnings, errors
+}
+
+// PopulateChildren resolves the child connections - unlike plugin aggregators, these may use any plugin
+func (a *CrossPluginAggregator) PopulateChildren(connectionMap map[string]*Connection) {
+	log.Printf("[TRACE] CrossPluginAggregator.PopulateChildren for aggregator connection %s", a.Name)
+	a.Connections = make(map[string]*Connection)
+	for name, connection := range connectionMap {
+		// aggregators and connections without a schema cannot be children
+		// NOTE: connections with lazy schema import are included - their tables are only added to the aggregator
+		// views once their schema has been imported
+		if connection.Type == ConnectionTypeAggregator || connection.ImportDisabled() {
+			continue
+		}
+		for _, pattern := range a.ConnectionNames {
+			if match, _ := path.Match(pattern, name); match {
+				a.Connections[name] = connection
+			}
+		}
+		if len(a.ConnectionTagSelector) > 0 && connection.MatchesTagSelector(a.ConnectionTagSelector) {
+			a.Connections[name] = connection
+		}
+	}
+	log.Printf("[TRACE] aggregator %s resolved child connections: %s", a.Name, strings.Join(maps.Keys(a.Connections), ","))
+}
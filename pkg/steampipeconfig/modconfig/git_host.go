@@ -0,0 +1,53 @@
+package modconfig
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/go-kit/hcl_helpers"
+)
+
+// GitHost is the config for a git host which mod dependencies are installed from
+// it allows the clone url to be rewritten (e.g. to use ssh, or a mirror),
+// and specifies the credentials used for that host
+type GitHost struct {
+	// the host name, as used in mod dependency names, e.g. github.example.com
+	Host string `hcl:"name,label"`
+	// the base url to use in place of https://<host>, e.g. ssh://git@github.example.com:7999 or git@gitlab.example.com:
+	Url *string `hcl:"url,optional"`
+	// the username to authenticate with
+	Username *string `hcl:"username,optional"`
+	// the name of an environment variable containing an access token
+	TokenEnv *string `hcl:"token_env,optional"`
+	// if set, retrieve credentials using 'git credential fill'
+	CredentialHelper *bool `hcl:"credential_helper,optional"`
+	// path to the ssh private key - if not set, the ssh agent is used for ssh urls
+	SshKey *string `hcl:"ssh_key,optional"`
+
+	DeclRange hcl.Range
+}
+
+func (h *GitHost) OnDecoded(block *hcl.Block) {
+	h.DeclRange = hcl_helpers.BlockRange(block)
+}
+
+// GetRepoUrl returns the url to clone the mod with the given name from
+// modName is of the form <host>/<path>
+func (h *GitHost) GetRepoUrl(modName string) string {
+	if h == nil || h.Url == nil {
+		return "https://" + modName
+	}
+	repoPath := strings.TrimPrefix(modName, h.Host+"/")
+	base := strings.TrimSuffix(*h.Url, "/")
+	// scp style ssh urls (git@host:path) do not use a separator
+	if strings.HasSuffix(base, ":") {
+		return base + repoPath
+	}
+	return base + "/" + repoPath
+}
+
+// GitHostForMod returns the host name of a mod dependency name, i.e. the first path segment
+func GitHostForMod(modName string) string {
+	host, _, _ := strings.Cut(modName, "/")
+	return host
+}
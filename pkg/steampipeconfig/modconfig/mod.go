@@ -281,7 +281,14 @@ func (m *Mod) Save() error {
 		if len(require.Mods) > 0 {
 			for _, m := range require.Mods {
 				modBody := requiresBody.AppendNewBlock("mod", []string{m.Name}).Body()
-				modBody.SetAttributeValue("version", cty.StringVal(m.VersionString))
+				switch {
+				case m.Branch != "":
+					modBody.SetAttributeValue("branch", cty.StringVal(m.Branch))
+				case m.Commit != "":
+					modBody.SetAttributeValue("commit", cty.StringVal(m.Commit))
+				default:
+					modBody.SetAttributeValue("version", cty.StringVal(m.VersionString))
+				}
 			}
 		}
 	}
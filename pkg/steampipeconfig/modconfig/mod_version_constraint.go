@@ -2,6 +2,7 @@ package modconfig
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
@@ -13,15 +14,22 @@ import (
 
 const filePrefix = "file:"
 
+// a full or abbreviated git commit sha
+var commitShaRegex = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
 type VersionConstrainCollection []*ModVersionConstraint
 
 type ModVersionConstraint struct {
 	// the fully qualified mod name, e.g. github.com/turbot/mod1
 	Name          string `cty:"name" hcl:"name,label"`
-	VersionString string `cty:"version" hcl:"version"`
+	VersionString string `cty:"version" hcl:"version,optional"`
+	// the git branch to track - the resolved commit is recorded in the workspace lock
+	Branch string `cty:"branch" hcl:"branch,optional"`
+	// the git commit to pin to
+	Commit string `cty:"commit" hcl:"commit,optional"`
 	// variable values to be set on the dependency mod
 	Args map[string]cty.Value `cty:"args"  hcl:"args,optional"`
-	// only one of Constraint, Branch and FilePath will be set
+	// only one of Constraint, Branch/Commit and FilePath will be set
 	Constraint *versionhelpers.Constraints
 	// the local file location to use
 	FilePath string
@@ -78,6 +86,10 @@ func (m *ModVersionConstraint) Initialise(block *hcl.Block) hcl.Diagnostics {
 		return nil
 	}
 
+	if m.HasGitRef() {
+		return m.validateGitRef()
+	}
+
 	// now default the version string to latest
 	if m.VersionString == "" || m.VersionString == "latest" {
 		m.VersionString = "*"
@@ -99,6 +111,50 @@ func (m *ModVersionConstraint) Initialise(block *hcl.Block) hcl.Diagnostics {
 
 }
 
+// validateGitRef verifies that only one of version, branch and commit is set, and that the commit is a valid sha
+func (m *ModVersionConstraint) validateGitRef() hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	if m.VersionString != "" || (m.Branch != "" && m.Commit != "") {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("invalid mod requirement for %s - only one of 'version', 'branch' and 'commit' may be set", m.Name),
+			Subject:  &m.DefRange,
+		})
+	}
+	if m.Commit != "" && !commitShaRegex.MatchString(m.Commit) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("invalid mod commit %s - must be a git commit sha of at least 7 characters", m.Commit),
+			Subject:  &m.DefRange,
+		})
+	}
+	return diags
+}
+
+// HasGitRef returns whether the dependency is pinned to a git branch or commit, rather than a version constraint
+func (m *ModVersionConstraint) HasGitRef() bool {
+	return m.Branch != "" || m.Commit != ""
+}
+
+// ConstraintString returns the original version constraint, or an empty string for git ref requirements
+func (m *ModVersionConstraint) ConstraintString() string {
+	if m.Constraint == nil {
+		return ""
+	}
+	return m.Constraint.Original
+}
+
+// RequirementString returns a description of the requirement, for use in messages
+func (m *ModVersionConstraint) RequirementString() string {
+	switch {
+	case m.Branch != "":
+		return fmt.Sprintf("branch %s", m.Branch)
+	case m.Commit != "":
+		return fmt.Sprintf("commit %s", m.Commit)
+	}
+	return fmt.Sprintf("version constraint %s", m.ConstraintString())
+}
+
 func (m *ModVersionConstraint) DependencyPath() string {
 	if m.HasVersion() {
 		return fmt.Sprintf("%s@%s", m.Name, m.VersionString)
@@ -122,5 +178,8 @@ func (m *ModVersionConstraint) setFilePath() {
 
 func (m *ModVersionConstraint) Equals(other *ModVersionConstraint) bool {
 	// just check the hcl properties
-	return m.Name == other.Name && m.VersionString == other.VersionString
+	return m.Name == other.Name &&
+		m.VersionString == other.VersionString &&
+		m.Branch == other.Branch &&
+		m.Commit == other.Commit
 }
@@ -0,0 +1,70 @@
+package modconfig
+
+import (
+	"testing"
+)
+
+type modVersionConstraintTest struct {
+	constraint    *ModVersionConstraint
+	expectError   bool
+	expectGitRef  bool
+	expectVersion string
+}
+
+var testCasesModVersionConstraint = map[string]modVersionConstraintTest{
+	"no version": {
+		constraint:    &ModVersionConstraint{Name: "github.com/turbot/mod1"},
+		expectVersion: "*",
+	},
+	"version": {
+		constraint:    &ModVersionConstraint{Name: "github.com/turbot/mod1", VersionString: "^1.0"},
+		expectVersion: "^1.0",
+	},
+	"branch": {
+		constraint:   &ModVersionConstraint{Name: "github.com/turbot/mod1", Branch: "feature-x"},
+		expectGitRef: true,
+	},
+	"commit": {
+		constraint:   &ModVersionConstraint{Name: "github.com/turbot/mod1", Commit: "1a2b3c4"},
+		expectGitRef: true,
+	},
+	"full commit": {
+		constraint:   &ModVersionConstraint{Name: "github.com/turbot/mod1", Commit: "1a2b3c4d5e6f1a2b3c4d5e6f1a2b3c4d5e6f1a2b"},
+		expectGitRef: true,
+	},
+	"short commit": {
+		constraint:  &ModVersionConstraint{Name: "github.com/turbot/mod1", Commit: "1a2b3c"},
+		expectError: true,
+	},
+	"invalid commit": {
+		constraint:  &ModVersionConstraint{Name: "github.com/turbot/mod1", Commit: "not-a-sha"},
+		expectError: true,
+	},
+	"version and branch": {
+		constraint:  &ModVersionConstraint{Name: "github.com/turbot/mod1", VersionString: "1.0", Branch: "main"},
+		expectError: true,
+	},
+	"branch and commit": {
+		constraint:  &ModVersionConstraint{Name: "github.com/turbot/mod1", Branch: "main", Commit: "1a2b3c4"},
+		expectError: true,
+	},
+}
+
+func TestModVersionConstraintInitialise(t *testing.T) {
+	for name, test := range testCasesModVersionConstraint {
+		diags := test.constraint.Initialise(nil)
+		if diags.HasErrors() != test.expectError {
+			t.Errorf("Test: '%s' FAILED : expected error %v, got %v", name, test.expectError, diags)
+			continue
+		}
+		if test.expectError {
+			continue
+		}
+		if test.constraint.HasGitRef() != test.expectGitRef {
+			t.Errorf("Test: '%s' FAILED : expected HasGitRef %v, got %v", name, test.expectGitRef, test.constraint.HasGitRef())
+		}
+		if test.constraint.ConstraintString() != test.expectVersion {
+			t.Errorf("Test: '%s' FAILED : expected constraint '%s', got '%s'", name, test.expectVersion, test.constraint.ConstraintString())
+		}
+	}
+}
@@ -8,9 +8,12 @@ import (
 )
 
 type Database struct {
+	AuditLog         *bool   `hcl:"audit_log"`
 	Cache            *bool   `hcl:"cache"`
 	CacheMaxTtl      *int    `hcl:"cache_max_ttl"`
 	CacheMaxSizeMb   *int    `hcl:"cache_max_size_mb"`
+	HealthListen     *string `hcl:"health_listen"`
+	HealthPort       *int    `hcl:"health_port"`
 	Listen           *string `hcl:"listen"`
 	Port             *int    `hcl:"port"`
 	SearchPath       *string `hcl:"search_path"`
@@ -51,6 +54,15 @@ func (d *Database) ConfigMap() map[string]interface{} {
 	if d.CacheMaxSizeMb != nil {
 		res[constants.ArgMaxCacheSizeMb] = d.CacheMaxSizeMb
 	}
+	if d.HealthListen != nil {
+		res[constants.ArgDatabaseHealthListen] = d.HealthListen
+	}
+	if d.AuditLog != nil {
+		res[constants.ArgDatabaseAuditLog] = d.AuditLog
+	}
+	if d.HealthPort != nil {
+		res[constants.ArgDatabaseHealthPort] = d.HealthPort
+	}
 	return res
 }
 
@@ -83,6 +95,15 @@ func (d *Database) Merge(otherOptions Options) {
 		if o.CacheMaxTtl != nil {
 			d.CacheMaxTtl = o.CacheMaxTtl
 		}
+		if o.HealthListen != nil {
+			d.HealthListen = o.HealthListen
+		}
+		if o.HealthPort != nil {
+			d.HealthPort = o.HealthPort
+		}
+		if o.AuditLog != nil {
+			d.AuditLog = o.AuditLog
+		}
 	}
 }
 
@@ -131,5 +152,20 @@ func (d *Database) String() string {
 	} else {
 		str = append(str, fmt.Sprintf("  CacheMaxTtl: %d", *d.CacheMaxTtl))
 	}
+	if d.HealthListen == nil {
+		str = append(str, "  HealthListen: nil")
+	} else {
+		str = append(str, fmt.Sprintf("  HealthListen: %s", *d.HealthListen))
+	}
+	if d.HealthPort == nil {
+		str = append(str, "  HealthPort: nil")
+	} else {
+		str = append(str, fmt.Sprintf("  HealthPort: %d", *d.HealthPort))
+	}
+	if d.AuditLog == nil {
+		str = append(str, "  AuditLog: nil")
+	} else {
+		str = append(str, fmt.Sprintf("  AuditLog: %t", *d.AuditLog))
+	}
 	return strings.Join(str, "\n")
 }
@@ -9,6 +9,71 @@ import (
 
 type Plugin struct {
 	MemoryMaxMb *int `hcl:"memory_max_mb"`
+	// one of 'off', 'warn' or 'enforce'
+	SignaturePolicy *string `hcl:"signature_policy"`
+	// path of the PEM encoded public key used to verify plugin image signatures
+	SignaturePublicKey *string           `hcl:"signature_public_key"`
+	Registries         []*PluginRegistry `hcl:"registry,block"`
+}
+
+// PluginRegistry is the credential configuration for a private plugin registry, e.g.
+//
+//	registry "ghcr.io" {
+//	  credential_helper = "pass"
+//	}
+//
+// at most one of username/password, docker_config or credential_helper may be set
+// if none are set, credentials are read from the default docker config
+type PluginRegistry struct {
+	// the registry host (and optional port), e.g. ghcr.io or registry.example.com:5000
+	Host     string  `hcl:"host,label"`
+	Username *string `hcl:"username"`
+	Password *string `hcl:"password"`
+	// path to a docker config file to read the credentials from
+	DockerConfig *string `hcl:"docker_config"`
+	// suffix of a docker credential helper, e.g. 'ecr-login' for docker-credential-ecr-login
+	CredentialHelper *string `hcl:"credential_helper"`
+}
+
+// Validate returns an error if the registry config is ambiguous
+func (r *PluginRegistry) Validate() error {
+	if r.Host == "" {
+		return fmt.Errorf("plugin registry host must be set")
+	}
+	sources := 0
+	if r.Username != nil || r.Password != nil {
+		if r.Username == nil || r.Password == nil {
+			return fmt.Errorf("plugin registry '%s': both username and password must be set", r.Host)
+		}
+		sources++
+	}
+	if r.DockerConfig != nil {
+		sources++
+	}
+	if r.CredentialHelper != nil {
+		sources++
+	}
+	if sources > 1 {
+		return fmt.Errorf("plugin registry '%s': only one of username/password, docker_config or credential_helper may be set", r.Host)
+	}
+	return nil
+}
+
+func (r *PluginRegistry) String() string {
+	var str []string
+	if r.Username != nil {
+		str = append(str, fmt.Sprintf("username: %s", *r.Username))
+	}
+	if r.Password != nil {
+		str = append(str, "password: ********")
+	}
+	if r.DockerConfig != nil {
+		str = append(str, fmt.Sprintf("docker_config: %s", *r.DockerConfig))
+	}
+	if r.CredentialHelper != nil {
+		str = append(str, fmt.Sprintf("credential_helper: %s", *r.CredentialHelper))
+	}
+	return fmt.Sprintf("%s {%s}", r.Host, strings.Join(str, ", "))
 }
 
 // ConfigMap creates a config map that can be merged with viper
@@ -18,6 +83,12 @@ func (t *Plugin) ConfigMap() map[string]interface{} {
 	if t.MemoryMaxMb != nil {
 		res[constants.ArgMemoryMaxMbPlugin] = t.MemoryMaxMb
 	}
+	if t.SignaturePolicy != nil {
+		res[constants.ArgPluginSignaturePolicy] = t.SignaturePolicy
+	}
+	if t.SignaturePublicKey != nil {
+		res[constants.ArgPluginSignatureKey] = t.SignaturePublicKey
+	}
 
 	return res
 }
@@ -30,9 +101,29 @@ func (t *Plugin) Merge(otherOptions Options) {
 		if o.MemoryMaxMb != nil {
 			t.MemoryMaxMb = o.MemoryMaxMb
 		}
+		if o.SignaturePolicy != nil {
+			t.SignaturePolicy = o.SignaturePolicy
+		}
+		if o.SignaturePublicKey != nil {
+			t.SignaturePublicKey = o.SignaturePublicKey
+		}
+		// registries are merged by host
+		for _, registry := range o.Registries {
+			t.setRegistry(registry)
+		}
 	}
 }
 
+func (t *Plugin) setRegistry(registry *PluginRegistry) {
+	for i, existing := range t.Registries {
+		if existing.Host == registry.Host {
+			t.Registries[i] = registry
+			return
+		}
+	}
+	t.Registries = append(t.Registries, registry)
+}
+
 func (t *Plugin) String() string {
 	if t == nil {
 		return ""
@@ -43,6 +134,19 @@ func (t *Plugin) String() string {
 	} else {
 		str = append(str, fmt.Sprintf("  MemoryMaxMb: %d", *t.MemoryMaxMb))
 	}
+	if t.SignaturePolicy == nil {
+		str = append(str, "  SignaturePolicy: nil")
+	} else {
+		str = append(str, fmt.Sprintf("  SignaturePolicy: %s", *t.SignaturePolicy))
+	}
+	if t.SignaturePublicKey == nil {
+		str = append(str, "  SignaturePublicKey: nil")
+	} else {
+		str = append(str, fmt.Sprintf("  SignaturePublicKey: %s", *t.SignaturePublicKey))
+	}
+	for _, registry := range t.Registries {
+		str = append(str, fmt.Sprintf("  Registry: %s", registry.String()))
+	}
 
 	return strings.Join(str, "\n")
 }
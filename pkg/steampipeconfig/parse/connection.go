@@ -4,35 +4,134 @@ import (
 	"fmt"
 	"github.com/turbot/go-kit/hcl_helpers"
 	"log"
+	"regexp"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/utils"
 	"github.com/zclconf/go-cty/cty"
 	"golang.org/x/exp/maps"
 )
 
-func DecodeConnection(block *hcl.Block) (*modconfig.Connection, hcl.Diagnostics) {
+// matches a template in a connection name, e.g. the '{{each.key}}' in 'aws_{{each.key}}'
+var connectionNameTemplateRegex = regexp.MustCompile(`{{\s*(.*?)\s*}}`)
+
+// DecodeConnections decodes a connection block
+// connection attributes are evaluated with the functions 'env' and 'secret' available, so credentials need not be
+// stored in the config file - the values are resolved here, before the config is passed to the plugin
+// if the block has a 'for_each' attribute it is a connection template, and a connection is returned for
+// each element of the for_each value - the connection name and config may reference the element as 'each.key' and 'each.value'
+// configDir is used to resolve relative paths passed to functions such as 'file'
+func DecodeConnections(block *hcl.Block, configDir string) ([]*modconfig.Connection, hcl.Diagnostics) {
+	connectionContent, _, diags := block.Body.PartialContent(ConnectionBlockSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	evalCtx := newConnectionEvalContext(configDir)
+	forEachAttr := connectionContent.Attributes["for_each"]
+	if forEachAttr == nil {
+		connection, diags := decodeConnection(block, block.Labels[0], evalCtx)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		return []*modconfig.Connection{connection}, diags
+	}
+
+	nameTemplate := block.Labels[0]
+	if !connectionNameTemplateRegex.MatchString(nameTemplate) {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("connection '%s' has a for_each attribute but the connection name is not a template, e.g. '%s_{{each.key}}'", nameTemplate, nameTemplate),
+			Subject:  hcl_helpers.BlockRangePointer(block),
+		}}
+	}
+
+	forEach, diags := forEachAttr.Expr.Value(evalCtx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if forEach.IsNull() || !forEach.IsKnown() || !forEach.CanIterateElements() {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("the for_each value of connection '%s' must be a list, set, map or object", nameTemplate),
+			Subject:  forEachAttr.Expr.Range().Ptr(),
+		}}
+	}
+
+	var connections []*modconfig.Connection
+	for it := forEach.ElementIterator(); it.Next(); {
+		key, value := it.Element()
+		if forEach.Type().IsSetType() {
+			// for sets, the key is the value
+			key = value
+		}
+		evalCtx.Variables["each"] = cty.ObjectVal(map[string]cty.Value{
+			"key":   key,
+			"value": value,
+		})
+
+		name, moreDiags := expandConnectionNameTemplate(nameTemplate, evalCtx, block)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		connection, moreDiags := decodeConnection(block, name, evalCtx)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		connections = append(connections, connection)
+	}
+	return connections, diags
+}
+
+// expandConnectionNameTemplate evaluates each '{{ expression }}' template in the connection name
+func expandConnectionNameTemplate(nameTemplate string, evalCtx *hcl.EvalContext, block *hcl.Block) (string, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	name := connectionNameTemplateRegex.ReplaceAllStringFunc(nameTemplate, func(template string) string {
+		exprString := connectionNameTemplateRegex.FindStringSubmatch(template)[1]
+		expr, moreDiags := hclsyntax.ParseExpression([]byte(exprString), block.DefRange.Filename, block.DefRange.Start)
+		if moreDiags.HasErrors() {
+			diags = append(diags, moreDiags...)
+			return template
+		}
+		var value string
+		moreDiags = gohcl.DecodeExpression(expr, evalCtx, &value)
+		if moreDiags.HasErrors() {
+			diags = append(diags, moreDiags...)
+			return template
+		}
+		return value
+	})
+	return name, diags
+}
+
+// decodeConnection decodes a connection block using the given name
+// evalCtx is used to evaluate the connection attributes
+func decodeConnection(block *hcl.Block, name string, evalCtx *hcl.EvalContext) (*modconfig.Connection, hcl.Diagnostics) {
 	connectionContent, rest, diags := block.Body.PartialContent(ConnectionBlockSchema)
 	if diags.HasErrors() {
 		return nil, diags
 	}
 
 	connection := modconfig.NewConnection(block)
+	connection.Name = name
 
 	// decode the plugin property
 	// NOTE: this mutates connection to set PluginAlias and possible PluginInstance
-	diags = decodeConnectionPluginProperty(connectionContent, connection)
+	diags = decodeConnectionPluginProperty(connectionContent, connection, evalCtx)
 	if diags.HasErrors() {
 		return nil, diags
 	}
 
 	if connectionContent.Attributes["type"] != nil {
 		var connectionType string
-		diags = gohcl.DecodeExpression(connectionContent.Attributes["type"].Expr, nil, &connectionType)
+		diags = gohcl.DecodeExpression(connectionContent.Attributes["type"].Expr, evalCtx, &connectionType)
 		if diags.HasErrors() {
 			return nil, diags
 		}
@@ -40,19 +139,49 @@ func DecodeConnection(block *hcl.Block) (*modconfig.Connection, hcl.Diagnostics)
 	}
 	if connectionContent.Attributes["import_schema"] != nil {
 		var importSchema string
-		diags = gohcl.DecodeExpression(connectionContent.Attributes["import_schema"].Expr, nil, &importSchema)
+		diags = gohcl.DecodeExpression(connectionContent.Attributes["import_schema"].Expr, evalCtx, &importSchema)
 		if diags.HasErrors() {
 			return nil, diags
 		}
 		connection.ImportSchema = importSchema
 	}
-	if connectionContent.Attributes["connections"] != nil {
-		var connections []string
-		diags = gohcl.DecodeExpression(connectionContent.Attributes["connections"].Expr, nil, &connections)
+	if connectionsAttr := connectionContent.Attributes["connections"]; connectionsAttr != nil {
+		connection.ConnectionNames, connection.ConnectionTagSelector, diags = decodeConnectionsAttribute(connectionsAttr, evalCtx)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+	}
+	if connectionContent.Attributes["tags"] != nil {
+		var tags map[string]string
+		diags = gohcl.DecodeExpression(connectionContent.Attributes["tags"].Expr, evalCtx, &tags)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		connection.Tags = tags
+	}
+	if connectionContent.Attributes["tables_include"] != nil {
+		diags = gohcl.DecodeExpression(connectionContent.Attributes["tables_include"].Expr, evalCtx, &connection.TablesInclude)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+	}
+	if connectionContent.Attributes["tables_exclude"] != nil {
+		diags = gohcl.DecodeExpression(connectionContent.Attributes["tables_exclude"].Expr, evalCtx, &connection.TablesExclude)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+	}
+	if connectionContent.Attributes["default_quals"] != nil {
+		diags = gohcl.DecodeExpression(connectionContent.Attributes["default_quals"].Expr, evalCtx, &connection.DefaultQuals)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+	}
+	if connectionContent.Attributes["priority"] != nil {
+		diags = gohcl.DecodeExpression(connectionContent.Attributes["priority"].Expr, evalCtx, &connection.Priority)
 		if diags.HasErrors() {
 			return nil, diags
 		}
-		connection.ConnectionNames = connections
 	}
 
 	// check for nested options
@@ -106,7 +235,7 @@ func DecodeConnection(block *hcl.Block) (*modconfig.Connection, hcl.Diagnostics)
 	}
 
 	// convert the remaining config to a hcl string to pass to the plugin
-	config, moreDiags := hcl_helpers.HclBodyToHclString(rest, connectionContent)
+	config, moreDiags := connectionConfigString(rest, connectionContent, evalCtx)
 	if moreDiags.HasErrors() {
 		diags = append(diags, moreDiags...)
 	} else {
@@ -116,9 +245,28 @@ func DecodeConnection(block *hcl.Block) (*modconfig.Connection, hcl.Diagnostics)
 	return connection, diags
 }
 
-func decodeConnectionPluginProperty(connectionContent *hcl.BodyContent, connection *modconfig.Connection) hcl.Diagnostics {
+// decodeConnectionsAttribute decodes the 'connections' attribute of an aggregator
+// this is either a list of connection names/wildcards, or a tag selector, e.g. { env = "prod" }
+func decodeConnectionsAttribute(connectionsAttr *hcl.Attribute, evalCtx *hcl.EvalContext) (connectionNames []string, tagSelector map[string]string, diags hcl.Diagnostics) {
+	connectionsValue, diags := connectionsAttr.Expr.Value(evalCtx)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+	if ty := connectionsValue.Type(); ty.IsObjectType() || ty.IsMapType() {
+		diags = gohcl.DecodeExpression(connectionsAttr.Expr, evalCtx, &tagSelector)
+	} else {
+		diags = gohcl.DecodeExpression(connectionsAttr.Expr, evalCtx, &connectionNames)
+	}
+	return connectionNames, tagSelector, diags
+}
+
+func decodeConnectionPluginProperty(connectionContent *hcl.BodyContent, connection *modconfig.Connection, connectionEvalCtx *hcl.EvalContext) hcl.Diagnostics {
 	var pluginName string
-	evalCtx := &hcl.EvalContext{Variables: make(map[string]cty.Value)}
+	// NOTE: the 'plugin' variable is not defined, so plugin references are returned as dependencies
+	evalCtx := &hcl.EvalContext{Functions: connectionEvalCtx.Functions, Variables: make(map[string]cty.Value)}
+	for k, v := range connectionEvalCtx.Variables {
+		evalCtx.Variables[k] = v
+	}
 
 	diags := gohcl.DecodeExpression(connectionContent.Attributes["plugin"].Expr, evalCtx, &pluginName)
 	res := newDecodeResult()
@@ -150,6 +298,44 @@ func decodeConnectionPluginProperty(connectionContent *hcl.BodyContent, connecti
 	return nil
 }
 
+// connectionConfigString builds a hcl string containing the plugin specific connection config,
+// i.e. all attributes which are NOT in the connection block schema, evaluated using evalCtx
+// this is passed to the plugin which will validate and parse it
+// NOTE: this mirrors hcl_helpers.HclBodyToHclString, but evaluates the attributes using evalCtx
+func connectionConfigString(body hcl.Body, connectionContent *hcl.BodyContent, evalCtx *hcl.EvalContext) (string, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	attrExpressionMap := make(map[string]hcl.Expression)
+	if hclBody, ok := body.(*hclsyntax.Body); ok {
+		for name, attr := range hclBody.Attributes {
+			if _, ok := connectionContent.Attributes[name]; ok {
+				continue
+			}
+			attrExpressionMap[name] = attr.Expr
+		}
+	} else {
+		// json body - JustAttributes only returns the attributes which are not in the schema
+		attrs, moreDiags := body.JustAttributes()
+		if moreDiags.HasErrors() {
+			return "", moreDiags
+		}
+		for name, attr := range attrs {
+			attrExpressionMap[name] = attr.Expr
+		}
+	}
+
+	f := hclwrite.NewEmptyFile()
+	rootBody := f.Body()
+	for _, name := range utils.SortedMapKeys(attrExpressionMap) {
+		val, moreDiags := attrExpressionMap[name].Value(evalCtx)
+		if moreDiags.HasErrors() {
+			diags = append(diags, moreDiags...)
+			continue
+		}
+		rootBody.SetAttributeValue(name, val)
+	}
+	return string(f.Bytes()), diags
+}
+
 func getPluginInstanceFromDependency(dependencies []*modconfig.ResourceDependency) (string, bool) {
 	if len(dependencies) != 1 {
 		return "", false
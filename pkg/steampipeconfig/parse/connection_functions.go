@@ -0,0 +1,61 @@
+package parse
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/steampipe/pkg/secrets"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// newConnectionEvalContext returns the eval context used to evaluate connection attributes
+// this provides the standard context functions, plus 'env' and 'secret'
+// configDir is used to resolve relative paths passed to functions such as 'file'
+func newConnectionEvalContext(configDir string) *hcl.EvalContext {
+	functions := ContextFunctions(configDir)
+	functions["env"] = envFunc
+	functions["secret"] = secretFunc
+	return &hcl.EvalContext{
+		Functions: functions,
+		Variables: make(map[string]cty.Value),
+	}
+}
+
+// envFunc returns the value of an environment variable - it is an error if the variable is not set
+var envFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "name",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		name := args[0].AsString()
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return cty.NilVal, fmt.Errorf("environment variable '%s' is not set", name)
+		}
+		return cty.StringVal(value), nil
+	},
+})
+
+// secretFunc returns the value of a secret from the configured secret providers
+var secretFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "name",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		value, err := secrets.Lookup(args[0].AsString())
+		if err != nil {
+			return cty.NilVal, err
+		}
+		return cty.StringVal(value), nil
+	},
+})
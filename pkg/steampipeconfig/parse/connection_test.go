@@ -0,0 +1,293 @@
+package parse
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+type decodeConnectionsTest struct {
+	source   string
+	expected map[string]string
+}
+
+var testCasesDecodeConnections = map[string]decodeConnectionsTest{
+	"no for_each": {
+		source: `
+connection "aws_dev" {
+  plugin  = "aws"
+  profile = "dev"
+}`,
+		expected: map[string]string{"aws_dev": "profile = \"dev\"\n"},
+	},
+	"for_each map": {
+		source: `
+connection "aws_{{each.key}}" {
+  plugin   = "aws"
+  for_each = { dev = "111", prod = "222" }
+  profile  = each.key
+  account  = each.value
+}`,
+		expected: map[string]string{
+			"aws_dev":  "account = \"111\"\nprofile = \"dev\"\n",
+			"aws_prod": "account = \"222\"\nprofile = \"prod\"\n",
+		},
+	},
+	"for_each set": {
+		source: `
+connection "aws_{{ each.value }}" {
+  plugin   = "aws"
+  for_each = toset(["dev", "prod"])
+  profile  = each.value
+}`,
+		expected: map[string]string{
+			"aws_dev":  "profile = \"dev\"\n",
+			"aws_prod": "profile = \"prod\"\n",
+		},
+	},
+	"for_each csv file": {
+		source: `
+connection "aws_{{each.value.name}}" {
+  plugin   = "aws"
+  for_each = csvdecode(file("accounts.csv"))
+  profile  = each.value.profile
+  regions  = [each.value.region]
+}`,
+		expected: map[string]string{
+			"aws_dev":  "profile = \"dev_profile\"\nregions = [\"us-east-1\"]\n",
+			"aws_prod": "profile = \"prod_profile\"\nregions = [\"eu-west-1\"]\n",
+		},
+	},
+	"env function": {
+		source: `
+connection "aws_dev" {
+  plugin     = "aws"
+  access_key = env("TEST_DECODE_CONNECTIONS_ACCESS_KEY")
+}`,
+		expected: map[string]string{"aws_dev": "access_key = \"env_access_key\"\n"},
+	},
+	"env function variable not set": {
+		source: `
+connection "aws_dev" {
+  plugin     = "aws"
+  access_key = env("TEST_DECODE_CONNECTIONS_NOT_SET")
+}`,
+		expected: nil,
+	},
+	"for_each without name template": {
+		source: `
+connection "aws" {
+  plugin   = "aws"
+  for_each = ["dev", "prod"]
+}`,
+		expected: nil,
+	},
+	"for_each not a collection": {
+		source: `
+connection "aws_{{each.key}}" {
+  plugin   = "aws"
+  for_each = "dev"
+}`,
+		expected: nil,
+	},
+}
+
+func TestDecodeConnections(t *testing.T) {
+	configDir := t.TempDir()
+	csv := "name,profile,region\ndev,dev_profile,us-east-1\nprod,prod_profile,eu-west-1\n"
+	if err := os.WriteFile(filepath.Join(configDir, "accounts.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TEST_DECODE_CONNECTIONS_ACCESS_KEY", "env_access_key")
+
+	for name, test := range testCasesDecodeConnections {
+		file, diags := hclsyntax.ParseConfig([]byte(test.source), "test.spc", hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatalf("Test: '%s' FAILED : failed to parse source: %s", name, diags.Error())
+		}
+		content, diags := file.Body.Content(ConfigBlockSchema)
+		if diags.HasErrors() {
+			t.Fatalf("Test: '%s' FAILED : failed to decode source: %s", name, diags.Error())
+		}
+
+		connections, diags := DecodeConnections(content.Blocks[0], configDir)
+		if test.expected == nil {
+			if !diags.HasErrors() {
+				t.Errorf("Test: '%s' FAILED : expected an error", name)
+			}
+			continue
+		}
+		if diags.HasErrors() {
+			t.Errorf("Test: '%s' FAILED : unexpected error: %s", name, diags.Error())
+			continue
+		}
+
+		res := make(map[string]string)
+		for _, c := range connections {
+			if c.PluginAlias != "aws" {
+				t.Errorf("Test: '%s' FAILED : expected plugin 'aws', got '%s'", name, c.PluginAlias)
+			}
+			res[c.Name] = c.Config
+		}
+		if !reflect.DeepEqual(res, test.expected) {
+			t.Errorf("Test: '%s' FAILED : expected %v, got %v", name, test.expected, res)
+		}
+	}
+}
+
+func TestDecodeConnectionTags(t *testing.T) {
+	source := `
+connection "aws_prod_payments" {
+  plugin = "aws"
+  tags   = { env = "prod", team = "payments" }
+}
+
+connection "aws_prod" {
+  plugin      = "aws"
+  type        = "aggregator"
+  connections = { env = "prod" }
+}
+
+connection "aws_all" {
+  plugin      = "aws"
+  type        = "aggregator"
+  connections = ["aws_*"]
+}`
+	file, diags := hclsyntax.ParseConfig([]byte(source), "test.spc", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse source: %s", diags.Error())
+	}
+	content, _ := file.Body.Content(ConfigBlockSchema)
+
+	var connections = make(map[string]*modconfig.Connection)
+	for _, block := range content.Blocks {
+		decoded, diags := DecodeConnections(block, t.TempDir())
+		if diags.HasErrors() {
+			t.Fatalf("failed to decode connection: %s", diags.Error())
+		}
+		connections[decoded[0].Name] = decoded[0]
+	}
+
+	if tags := connections["aws_prod_payments"].Tags; !reflect.DeepEqual(tags, map[string]string{"env": "prod", "team": "payments"}) {
+		t.Errorf("Test: '%s' FAILED : unexpected tags %v", "tags", tags)
+	}
+	if selector := connections["aws_prod"].ConnectionTagSelector; !reflect.DeepEqual(selector, map[string]string{"env": "prod"}) {
+		t.Errorf("Test: '%s' FAILED : unexpected tag selector %v", "tag selector", selector)
+	}
+	if names := connections["aws_all"].ConnectionNames; !reflect.DeepEqual(names, []string{"aws_*"}) {
+		t.Errorf("Test: '%s' FAILED : unexpected connection names %v", "connection names", names)
+	}
+}
+
+func TestDecodeConnectionTableFilterAndDefaultQuals(t *testing.T) {
+	source := `
+connection "aws_prod" {
+  plugin         = "aws"
+  tables_include = ["aws_ec2_*", "aws_s3_bucket"]
+  tables_exclude = ["aws_ec2_ami*"]
+  default_quals  = { aws_ec2_instance = "region like 'eu-%'" }
+}`
+	file, diags := hclsyntax.ParseConfig([]byte(source), "test.spc", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse source: %s", diags.Error())
+	}
+	content, _ := file.Body.Content(ConfigBlockSchema)
+
+	connections, diags := DecodeConnections(content.Blocks[0], t.TempDir())
+	if diags.HasErrors() {
+		t.Fatalf("failed to decode connection: %s", diags.Error())
+	}
+	if include := connections[0].TablesInclude; !reflect.DeepEqual(include, []string{"aws_ec2_*", "aws_s3_bucket"}) {
+		t.Errorf("Test: '%s' FAILED : unexpected tables_include %v", "tables_include", include)
+	}
+	if exclude := connections[0].TablesExclude; !reflect.DeepEqual(exclude, []string{"aws_ec2_ami*"}) {
+		t.Errorf("Test: '%s' FAILED : unexpected tables_exclude %v", "tables_exclude", exclude)
+	}
+	if defaultQuals := connections[0].DefaultQuals; !reflect.DeepEqual(defaultQuals, map[string]string{"aws_ec2_instance": "region like 'eu-%'"}) {
+		t.Errorf("Test: '%s' FAILED : unexpected default_quals %v", "default_quals", defaultQuals)
+	}
+}
+
+func TestDecodeCrossPluginAggregator(t *testing.T) {
+	source := `
+connection "fleet" {
+  type        = "aggregator"
+  connections = ["aws_*", "azure_*"]
+
+  table "instance" {
+    columns = ["id", "region"]
+    source "aws_ec2_instance" {
+      column_map = { id = "instance_id" }
+    }
+    source "azure_compute_virtual_machine" {}
+  }
+}
+
+connection "aws_dev" {
+  plugin = "aws"
+}`
+	file, diags := hclsyntax.ParseConfig([]byte(source), "test.spc", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse source: %s", diags.Error())
+	}
+	content, _ := file.Body.Content(ConfigBlockSchema)
+
+	if !IsCrossPluginAggregator(content.Blocks[0]) {
+		t.Errorf("Test: '%s' FAILED : expected a cross-plugin aggregator", "fleet")
+	}
+	if IsCrossPluginAggregator(content.Blocks[1]) {
+		t.Errorf("Test: '%s' FAILED : expected a plugin connection", "aws_dev")
+	}
+
+	aggregator, diags := DecodeCrossPluginAggregator(content.Blocks[0], t.TempDir())
+	if diags.HasErrors() {
+		t.Fatalf("failed to decode aggregator: %s", diags.Error())
+	}
+	expected := []*modconfig.AggregatorTable{
+		{
+			Name:    "instance",
+			Columns: []string{"id", "region"},
+			Sources: []*modconfig.AggregatorTableSource{
+				{Table: "aws_ec2_instance", ColumnMap: map[string]string{"id": "instance_id"}},
+				{Table: "azure_compute_virtual_machine"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(aggregator.Tables, expected) {
+		t.Errorf("Test: '%s' FAILED : unexpected tables %s", "tables", aggregator.String())
+	}
+	if !reflect.DeepEqual(aggregator.ConnectionNames, []string{"aws_*", "azure_*"}) {
+		t.Errorf("Test: '%s' FAILED : unexpected connection names %v", "connection names", aggregator.ConnectionNames)
+	}
+}
+
+func TestDecodeConnectionPriorityAndLazyImport(t *testing.T) {
+	source := `
+connection "aws_archive" {
+  plugin        = "aws"
+  import_schema = "lazy"
+  priority      = -10
+}`
+	file, diags := hclsyntax.ParseConfig([]byte(source), "test.spc", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse source: %s", diags.Error())
+	}
+	content, _ := file.Body.Content(ConfigBlockSchema)
+
+	connections, diags := DecodeConnections(content.Blocks[0], t.TempDir())
+	if diags.HasErrors() {
+		t.Fatalf("failed to decode connection: %s", diags.Error())
+	}
+	if priority := connections[0].Priority; priority != -10 {
+		t.Errorf("Test: '%s' FAILED : unexpected priority %d", "priority", priority)
+	}
+	if !connections[0].ImportLazy() {
+		t.Errorf("Test: '%s' FAILED : unexpected import_schema %s", "import_schema", connections[0].ImportSchema)
+	}
+}
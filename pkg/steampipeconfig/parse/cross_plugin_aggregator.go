@@ -0,0 +1,101 @@
+package parse
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/turbot/go-kit/hcl_helpers"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+// IsCrossPluginAggregator returns whether the connection block is a cross-plugin aggregator,
+// i.e. it has no plugin attribute and defines at least one table block
+func IsCrossPluginAggregator(block *hcl.Block) bool {
+	content, _, _ := block.Body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "plugin"}},
+		Blocks:     []hcl.BlockHeaderSchema{{Type: "table", LabelNames: []string{"name"}}},
+	})
+	return content.Attributes["plugin"] == nil && len(content.Blocks) > 0
+}
+
+// DecodeCrossPluginAggregator decodes a connection block which defines a cross-plugin aggregator
+// configDir is used to resolve relative paths passed to functions such as 'file'
+func DecodeCrossPluginAggregator(block *hcl.Block, configDir string) (*modconfig.CrossPluginAggregator, hcl.Diagnostics) {
+	content, diags := block.Body.Content(CrossPluginAggregatorBlockSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	evalCtx := newConnectionEvalContext(configDir)
+	aggregator := modconfig.NewCrossPluginAggregator(block)
+
+	var connectionType string
+	diags = gohcl.DecodeExpression(content.Attributes["type"].Expr, evalCtx, &connectionType)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if connectionType != modconfig.ConnectionTypeAggregator {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("connection '%s' defines tables so must be of type '%s'", aggregator.Name, modconfig.ConnectionTypeAggregator),
+			Subject:  content.Attributes["type"].Expr.Range().Ptr(),
+		}}
+	}
+
+	aggregator.ConnectionNames, aggregator.ConnectionTagSelector, diags = decodeConnectionsAttribute(content.Attributes["connections"], evalCtx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	for _, tableBlock := range content.Blocks {
+		table, moreDiags := decodeAggregatorTable(tableBlock, evalCtx)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		aggregator.Tables = append(aggregator.Tables, table)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return aggregator, diags
+}
+
+func decodeAggregatorTable(block *hcl.Block, evalCtx *hcl.EvalContext) (*modconfig.AggregatorTable, hcl.Diagnostics) {
+	content, diags := block.Body.Content(AggregatorTableBlockSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	table := &modconfig.AggregatorTable{Name: block.Labels[0]}
+	if columnsAttr := content.Attributes["columns"]; columnsAttr != nil {
+		diags = gohcl.DecodeExpression(columnsAttr.Expr, evalCtx, &table.Columns)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+	}
+
+	for _, sourceBlock := range content.Blocks {
+		sourceContent, moreDiags := sourceBlock.Body.Content(AggregatorTableSourceBlockSchema)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		source := &modconfig.AggregatorTableSource{Table: sourceBlock.Labels[0]}
+		if columnMapAttr := sourceContent.Attributes["column_map"]; columnMapAttr != nil {
+			moreDiags = gohcl.DecodeExpression(columnMapAttr.Expr, evalCtx, &source.ColumnMap)
+			diags = append(diags, moreDiags...)
+			if moreDiags.HasErrors() {
+				continue
+			}
+		}
+		table.Sources = append(table.Sources, source)
+	}
+	if len(table.Sources) == 0 && !diags.HasErrors() {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("aggregator table '%s' must define at least one source", table.Name),
+			Subject:  hcl_helpers.BlockRangePointer(block),
+		})
+	}
+	return table, diags
+}
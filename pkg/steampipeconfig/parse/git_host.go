@@ -0,0 +1,19 @@
+package parse
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+func DecodeGitHost(block *hcl.Block) (*modconfig.GitHost, hcl.Diagnostics) {
+	var gitHost = &modconfig.GitHost{}
+	diags := gohcl.DecodeBody(block.Body, nil, gitHost)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	gitHost.Host = block.Labels[0]
+	gitHost.OnDecoded(block)
+	return gitHost, diags
+}
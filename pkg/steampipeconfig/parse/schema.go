@@ -27,6 +27,10 @@ var ConfigBlockSchema = &hcl.BodySchema{
 			Type:       modconfig.BlockTypeWorkspaceProfile,
 			LabelNames: []string{"name"},
 		},
+		{
+			Type:       modconfig.BlockTypeGitHost,
+			LabelNames: []string{"name"},
+		},
 	},
 }
 var PluginBlockSchema = &hcl.BodySchema{
@@ -64,6 +68,24 @@ var ConnectionBlockSchema = &hcl.BodySchema{
 		{
 			Name: "import_schema",
 		},
+		{
+			Name: "for_each",
+		},
+		{
+			Name: "tags",
+		},
+		{
+			Name: "tables_include",
+		},
+		{
+			Name: "tables_exclude",
+		},
+		{
+			Name: "default_quals",
+		},
+		{
+			Name: "priority",
+		},
 	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{
@@ -73,6 +95,48 @@ var ConnectionBlockSchema = &hcl.BodySchema{
 	},
 }
 
+// CrossPluginAggregatorBlockSchema is the schema for a connection block with no plugin, which defines aggregator tables
+var CrossPluginAggregatorBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     "type",
+			Required: true,
+		},
+		{
+			Name:     "connections",
+			Required: true,
+		},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       "table",
+			LabelNames: []string{"name"},
+		},
+	},
+}
+
+var AggregatorTableBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name: "columns",
+		},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       "source",
+			LabelNames: []string{"table"},
+		},
+	},
+}
+
+var AggregatorTableSourceBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name: "column_map",
+		},
+	},
+}
+
 // WorkspaceBlockSchema is the top level schema for all workspace resources
 var WorkspaceBlockSchema = &hcl.BodySchema{
 	Attributes: []hcl.AttributeSchema{},
@@ -28,6 +28,10 @@ type SteampipeConfig struct {
 	PluginsInstances map[string]*modconfig.Plugin
 	// map of connection name to partially parsed connection config
 	Connections map[string]*modconfig.Connection
+	// map of connection name to cross-plugin aggregator
+	CrossPluginAggregators map[string]*modconfig.CrossPluginAggregator
+	// map of git host config, keyed by host name
+	GitHosts map[string]*modconfig.GitHost
 
 	// Steampipe options
 	DefaultConnectionOptions *options.Connection
@@ -44,10 +48,12 @@ type SteampipeConfig struct {
 
 func NewSteampipeConfig(commandName string) *SteampipeConfig {
 	return &SteampipeConfig{
-		Connections:      make(map[string]*modconfig.Connection),
-		Plugins:          make(map[string][]*modconfig.Plugin),
-		PluginsInstances: make(map[string]*modconfig.Plugin),
-		commandName:      commandName,
+		Connections:            make(map[string]*modconfig.Connection),
+		CrossPluginAggregators: make(map[string]*modconfig.CrossPluginAggregator),
+		GitHosts:               make(map[string]*modconfig.GitHost),
+		Plugins:                make(map[string][]*modconfig.Plugin),
+		PluginsInstances:       make(map[string]*modconfig.Plugin),
+		commandName:            commandName,
 	}
 }
 
@@ -70,9 +76,32 @@ func (c *SteampipeConfig) Validate() (validationWarnings, validationErrors []str
 		}
 	}
 
+	// cross-plugin aggregators are resolved after the connections have been validated,
+	// so connections with errors are not included
+	for aggregatorName, aggregator := range c.CrossPluginAggregators {
+		aggregator.PopulateChildren(c.Connections)
+		w, e := aggregator.Validate()
+		validationWarnings = append(validationWarnings, w...)
+		validationErrors = append(validationErrors, e...)
+		if len(e) > 0 {
+			delete(c.CrossPluginAggregators, aggregatorName)
+		}
+	}
+
 	return
 }
 
+// connectionDeclRange returns the declaration range of the connection or cross-plugin aggregator with the given name
+func (c *SteampipeConfig) connectionDeclRange(name string) (modconfig.Range, bool) {
+	if connection, ok := c.Connections[name]; ok {
+		return connection.DeclRange, true
+	}
+	if aggregator, ok := c.CrossPluginAggregators[name]; ok {
+		return aggregator.DeclRange, true
+	}
+	return modconfig.Range{}, false
+}
+
 // ConfigMap creates a config map to pass to viper
 func (c *SteampipeConfig) ConfigMap() map[string]interface{} {
 	res := modconfig.ConfigMap{}
@@ -252,6 +281,9 @@ func (c *SteampipeConfig) String() string {
 	for _, c := range c.Connections {
 		connectionStrings = append(connectionStrings, c.String())
 	}
+	for _, a := range c.CrossPluginAggregators {
+		connectionStrings = append(connectionStrings, a.String())
+	}
 
 	str := fmt.Sprintf(`
 Connections: 
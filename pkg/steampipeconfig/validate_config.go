@@ -0,0 +1,199 @@
+package steampipeconfig
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/hashicorp/hcl/v2"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/options"
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+const (
+	ConfigIssueSeverityError   = "error"
+	ConfigIssueSeverityWarning = "warning"
+)
+
+// ConfigValidationIssue is a problem found when validating the connection config
+type ConfigValidationIssue struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// the connection or plugin the issue relates to (if any)
+	Resource  string `json:"resource,omitempty"`
+	FileName  string `json:"file_name,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+}
+
+func (i *ConfigValidationIssue) String() string {
+	if i.FileName == "" {
+		return i.Message
+	}
+	return fmt.Sprintf("%s (%s:%d)", i.Message, i.FileName, i.StartLine)
+}
+
+type ConfigValidationIssues []*ConfigValidationIssue
+
+func (i ConfigValidationIssues) HasErrors() bool {
+	for _, issue := range i {
+		if issue.Severity == ConfigIssueSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *ConfigValidationIssues) add(severity, message, resource string, declRange *modconfig.Range) {
+	issue := &ConfigValidationIssue{
+		Severity: severity,
+		Message:  message,
+		Resource: resource,
+	}
+	if declRange != nil {
+		issue.FileName = declRange.Filename
+		issue.StartLine = declRange.Start.Line
+		issue.EndLine = declRange.End.Line
+	}
+	*i = append(*i, issue)
+}
+
+func (i *ConfigValidationIssues) addDiags(diags hcl.Diagnostics) {
+	for _, diag := range diags {
+		severity := ConfigIssueSeverityWarning
+		if diag.Severity == hcl.DiagError {
+			severity = ConfigIssueSeverityError
+		}
+		message := diag.Summary
+		if diag.Detail != "" {
+			message += ": " + diag.Detail
+		}
+		var declRange *modconfig.Range
+		if diag.Subject != nil {
+			r := modconfig.NewRange(*diag.Subject)
+			declRange = &r
+		}
+		i.add(severity, message, "", declRange)
+	}
+}
+
+// ValidateConfig loads the connection config from configFolder (and the workspace config from modLocation, if set)
+// and validates it, returning all problems found rather than failing at the first error
+//
+// as well as the decode diagnostics, this checks:
+// - connection names are valid
+// - the plugin of each connection resolves to an installed plugin
+// - the child connection patterns of aggregators are not duplicated and all match at least one connection
+func ValidateConfig(configFolder, modLocation string) (*SteampipeConfig, ConfigValidationIssues) {
+	var issues ConfigValidationIssues
+	steampipeConfig := NewSteampipeConfig("")
+
+	include := filehelpers.InclusionsFromExtensions(constants.ConnectionConfigExtensions)
+	if !issues.decodeFolder(configFolder, steampipeConfig, &loadConfigOptions{include: include}) {
+		return steampipeConfig, issues
+	}
+	if modLocation != "" {
+		if _, err := os.Stat(modLocation); os.IsNotExist(err) {
+			issues.add(ConfigIssueSeverityError, fmt.Sprintf("mod location '%s' does not exist", modLocation), "", nil)
+			return steampipeConfig, issues
+		}
+		include = filehelpers.InclusionsFromFiles([]string{filepaths.WorkspaceConfigFileName})
+		issues.decodeFolder(modLocation, steampipeConfig, &loadConfigOptions{include: include, allowedOptions: []string{options.TerminalBlock}})
+	}
+	steampipeConfig.setDefaultConnectionOptions()
+
+	// validate the connections - this mirrors SteampipeConfig.Validate, but reports the declaration range of each problem
+	for _, name := range utils.SortedMapKeys(steampipeConfig.Connections) {
+		connection := steampipeConfig.Connections[name]
+		declRange := connection.DeclRange
+		if err := ValidateConnectionName(name); err != nil {
+			issues.add(ConfigIssueSeverityError, err.Error(), name, &declRange)
+		}
+		// plugin resolution errors are set on the connection by initializePlugins
+		if connection.Error != nil {
+			issues.add(ConfigIssueSeverityError, fmt.Sprintf("connection '%s': %s", name, connection.Error.Error()), name, &declRange)
+			continue
+		}
+		if connection.Type == modconfig.ConnectionTypeAggregator {
+			for _, failure := range connection.PopulateChildren(steampipeConfig.Connections) {
+				issues.add(ConfigIssueSeverityWarning, failure, name, &declRange)
+			}
+			for _, message := range validateAggregatorPatterns(connection, steampipeConfig.Connections) {
+				issues.add(ConfigIssueSeverityWarning, message, name, &declRange)
+			}
+		}
+		warnings, errors := connection.Validate(steampipeConfig.Connections)
+		for _, w := range warnings {
+			issues.add(ConfigIssueSeverityWarning, w, name, &declRange)
+		}
+		for _, e := range errors {
+			issues.add(ConfigIssueSeverityError, e, name, &declRange)
+		}
+	}
+	for _, name := range utils.SortedMapKeys(steampipeConfig.CrossPluginAggregators) {
+		aggregator := steampipeConfig.CrossPluginAggregators[name]
+		declRange := aggregator.DeclRange
+		if err := ValidateConnectionName(name); err != nil {
+			issues.add(ConfigIssueSeverityError, err.Error(), name, &declRange)
+		}
+		aggregator.PopulateChildren(steampipeConfig.Connections)
+		warnings, errors := aggregator.Validate()
+		for _, w := range warnings {
+			issues.add(ConfigIssueSeverityWarning, w, name, &declRange)
+		}
+		for _, e := range errors {
+			issues.add(ConfigIssueSeverityError, e, name, &declRange)
+		}
+	}
+
+	return steampipeConfig, issues
+}
+
+// decodeFolder decodes the config files in the folder into steampipeConfig, adding any diagnostics as issues
+// returns false if the config files could not be listed
+func (i *ConfigValidationIssues) decodeFolder(configFolder string, steampipeConfig *SteampipeConfig, opts *loadConfigOptions) bool {
+	configPaths, err := getConfigPaths(configFolder, opts)
+	if err != nil {
+		i.add(ConfigIssueSeverityError, fmt.Sprintf("failed to list config files in '%s': %s", configFolder, err.Error()), "", nil)
+		return false
+	}
+	if len(configPaths) == 0 {
+		return true
+	}
+	i.addDiags(decodeConfig(configFolder, configPaths, steampipeConfig, opts))
+	steampipeConfig.initializePlugins()
+	return true
+}
+
+// validateAggregatorPatterns checks for child connection patterns of an aggregator which are duplicated
+// or which do not match any connection using the plugin of the aggregator
+func validateAggregatorPatterns(aggregator *modconfig.Connection, connectionMap map[string]*modconfig.Connection) []string {
+	var res []string
+	patterns := make(map[string]struct{})
+	for _, pattern := range aggregator.ConnectionNames {
+		if _, ok := patterns[pattern]; ok {
+			res = append(res, fmt.Sprintf("aggregator '%s' specifies child connection '%s' more than once", aggregator.Name, pattern))
+			continue
+		}
+		patterns[pattern] = struct{}{}
+
+		reachable := false
+		for name, connection := range connectionMap {
+			if connection.Type == modconfig.ConnectionTypeAggregator || connection.Plugin != aggregator.Plugin {
+				continue
+			}
+			if match, _ := path.Match(pattern, name); match {
+				reachable = true
+				break
+			}
+		}
+		if !reachable {
+			res = append(res, fmt.Sprintf("child connection '%s' of aggregator '%s' does not match any %s connection", pattern, aggregator.Name, aggregator.PluginAlias))
+		}
+	}
+	return res
+}
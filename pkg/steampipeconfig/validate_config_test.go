@@ -0,0 +1,53 @@
+package steampipeconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+var testValidateAggregatorConnections = map[string]*modconfig.Connection{
+	"aws_dev":  {Name: "aws_dev", Plugin: "hub.steampipe.io/plugins/turbot/aws@latest", Type: modconfig.ConnectionTypePlugin},
+	"aws_prod": {Name: "aws_prod", Plugin: "hub.steampipe.io/plugins/turbot/aws@latest", Type: modconfig.ConnectionTypePlugin},
+	"gcp_dev":  {Name: "gcp_dev", Plugin: "hub.steampipe.io/plugins/turbot/gcp@latest", Type: modconfig.ConnectionTypePlugin},
+}
+
+type validateAggregatorPatternsTest struct {
+	patterns []string
+	expected []string
+}
+
+var testCasesValidateAggregatorPatterns = map[string]validateAggregatorPatternsTest{
+	"valid": {
+		patterns: []string{"aws_dev", "aws_p*"},
+	},
+	"duplicate": {
+		patterns: []string{"aws_*", "aws_*"},
+		expected: []string{"aggregator 'all' specifies child connection 'aws_*' more than once"},
+	},
+	"unmatched": {
+		patterns: []string{"aws_test"},
+		expected: []string{"child connection 'aws_test' of aggregator 'all' does not match any aws connection"},
+	},
+	"other plugin": {
+		patterns: []string{"gcp_*"},
+		expected: []string{"child connection 'gcp_*' of aggregator 'all' does not match any aws connection"},
+	},
+}
+
+func TestValidateAggregatorPatterns(t *testing.T) {
+	for name, test := range testCasesValidateAggregatorPatterns {
+		aggregator := &modconfig.Connection{
+			Name:            "all",
+			Plugin:          "hub.steampipe.io/plugins/turbot/aws@latest",
+			PluginAlias:     "aws",
+			Type:            modconfig.ConnectionTypeAggregator,
+			ConnectionNames: test.patterns,
+		}
+		res := validateAggregatorPatterns(aggregator, testValidateAggregatorConnections)
+		if strings.Join(res, "\n") != strings.Join(test.expected, "\n") {
+			t.Errorf("Test: '%s' FAILED : expected:\n%v\ngot:\n%v", name, test.expected, res)
+		}
+	}
+}
@@ -13,14 +13,21 @@ type DependencyVersionMap map[string]ResolvedVersionMap
 
 // Add adds a dependency to the list of items installed for the given parent
 func (m DependencyVersionMap) Add(dependencyName, alias string, dependencyVersion *semver.Version, constraintString string, parentName string) {
+	m.AddResolvedVersion(NewResolvedVersionConstraint(dependencyName, alias, dependencyVersion, constraintString), parentName)
+}
+
+// AddResolvedVersion adds a copy of the resolved dependency to the list of items installed for the given parent
+func (m DependencyVersionMap) AddResolvedVersion(dependency *ResolvedVersionConstraint, parentName string) {
 	// get the map for this parent
 	parentItems := m[parentName]
 	// create if needed
 	if parentItems == nil {
 		parentItems = make(ResolvedVersionMap)
 	}
-	// add the dependency
-	parentItems.Add(dependencyName, NewResolvedVersionConstraint(dependencyName, alias, dependencyVersion, constraintString))
+	// add a copy of the dependency (so the struct version is updated without changing the source map)
+	dep := *dependency
+	dep.StructVersion = WorkspaceLockStructVersion
+	parentItems.Add(dep.Name, &dep)
 	// save
 	m[parentName] = parentItems
 }
@@ -55,6 +62,44 @@ func (m DependencyVersionMap) buildTree(name string, tree treeprint.Tree) {
 	}
 }
 
+// DependencyTreeNode is a serialisable representation of a node in the dependency tree
+type DependencyTreeNode struct {
+	Name         string                `json:"name"`
+	Alias        string                `json:"alias,omitempty"`
+	Version      string                `json:"version,omitempty"`
+	Constraint   string                `json:"constraint,omitempty"`
+	Branch       string                `json:"branch,omitempty"`
+	Commit       string                `json:"commit,omitempty"`
+	Dependencies []*DependencyTreeNode `json:"dependencies"`
+}
+
+func (m DependencyVersionMap) GetDependencyTreeNode(rootName string) *DependencyTreeNode {
+	root := &DependencyTreeNode{Name: rootName}
+	m.buildTreeNode(rootName, root)
+	return root
+}
+
+func (m DependencyVersionMap) buildTreeNode(name string, node *DependencyTreeNode) {
+	deps := m[name]
+	depNames := maps.Keys(deps)
+	sort.Strings(depNames)
+	node.Dependencies = make([]*DependencyTreeNode, 0, len(deps))
+	for _, name := range depNames {
+		dep := deps[name]
+		child := &DependencyTreeNode{
+			Name:       dep.Name,
+			Alias:      dep.Alias,
+			Version:    dep.Version.String(),
+			Constraint: dep.Constraint,
+			Branch:     dep.Branch,
+			Commit:     dep.Commit,
+		}
+		node.Dependencies = append(node.Dependencies, child)
+		// if there are children add them
+		m.buildTreeNode(modconfig.BuildModDependencyPath(name, dep.Version), child)
+	}
+}
+
 // GetMissingFromOther returns a map of dependencies which exit in this map but not 'other'
 func (m DependencyVersionMap) GetMissingFromOther(other DependencyVersionMap) DependencyVersionMap {
 	res := make(DependencyVersionMap)
@@ -65,7 +110,7 @@ func (m DependencyVersionMap) GetMissingFromOther(other DependencyVersionMap) De
 		}
 		for name, dep := range deps {
 			if _, ok := otherDeps[name]; !ok {
-				res.Add(dep.Name, dep.Alias, dep.Version, dep.Constraint, parent)
+				res.AddResolvedVersion(dep, parent)
 			}
 		}
 	}
@@ -81,8 +126,10 @@ func (m DependencyVersionMap) GetUpgradedInOther(other DependencyVersionMap) Dep
 		}
 		for name, dep := range deps {
 			if otherDep, ok := otherDeps[name]; ok {
-				if otherDep.Version.GreaterThan(dep.Version) {
-					res.Add(otherDep.Name, dep.Alias, otherDep.Version, otherDep.Constraint, parent)
+				if isUpgrade(dep, otherDep) {
+					upgraded := *otherDep
+					upgraded.Alias = dep.Alias
+					res.AddResolvedVersion(&upgraded, parent)
 				}
 			}
 		}
@@ -99,11 +146,22 @@ func (m DependencyVersionMap) GetDowngradedInOther(other DependencyVersionMap) D
 		}
 		for name, dep := range deps {
 			if otherDep, ok := otherDeps[name]; ok {
-				if otherDep.Version.LessThan(dep.Version) {
-					res.Add(otherDep.Name, dep.Alias, otherDep.Version, otherDep.Constraint, parent)
+				if !dep.IsGitRef() && !otherDep.IsGitRef() && otherDep.Version.LessThan(dep.Version) {
+					downgraded := *otherDep
+					downgraded.Alias = dep.Alias
+					res.AddResolvedVersion(&downgraded, parent)
 				}
 			}
 		}
 	}
 	return res
 }
+
+// isUpgrade returns whether other is an upgrade of dep
+// - for a dependency tracking a git branch, this is the case if the branch head has moved
+func isUpgrade(dep, other *ResolvedVersionConstraint) bool {
+	if dep.IsGitRef() || other.IsGitRef() {
+		return other.Branch != "" && other.Branch == dep.Branch && other.Commit != dep.Commit
+	}
+	return other.Version.GreaterThan(dep.Version)
+}
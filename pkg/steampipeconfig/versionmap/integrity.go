@@ -0,0 +1,116 @@
+package versionmap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const integrityPrefix = "sha256:"
+
+// HashModDirectory returns the content hash of an installed mod directory
+//
+// the hash is a sha256 of the sorted list of '<file sha256>  <relative path>' lines for every
+// file in the directory, so it does not depend on file modes or timestamps
+// git metadata is excluded, so a cloned mod and a vendored copy of it have the same hash
+func HashModDirectory(dir string) (string, error) {
+	var lines []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		fileHash, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s\n", fileHash, filepath.ToSlash(relPath)))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	for _, line := range lines {
+		h.Write([]byte(line))
+	}
+	return integrityPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// IntegrityStatus is the result of verifying an installed dependency against the workspace lock
+type IntegrityStatus string
+
+const (
+	IntegrityOk       IntegrityStatus = "ok"
+	IntegrityModified IntegrityStatus = "modified"
+	IntegrityMissing  IntegrityStatus = "missing"
+	// the lock was written by a version of steampipe which did not record integrity hashes
+	IntegrityUnknown IntegrityStatus = "unknown"
+)
+
+// IntegrityResult is the result of verifying a single installed dependency
+type IntegrityResult struct {
+	DependencyPath string          `json:"dependency_path"`
+	Status         IntegrityStatus `json:"status"`
+	Expected       string          `json:"expected,omitempty"`
+	Actual         string          `json:"actual,omitempty"`
+}
+
+// DependencyModifiedError is returned by VerifyInstalledDependency if the contents of the installed dependency
+// do not match the integrity hash recorded in the lock
+type DependencyModifiedError struct {
+	DependencyPath string
+}
+
+func (e *DependencyModifiedError) Error() string {
+	return fmt.Sprintf("dependency mod '%s' has been modified since it was installed - run 'steampipe mod verify' for details, or 'steampipe mod install' to reinstall it", e.DependencyPath)
+}
+
+// VerifyInstalledDependency returns a DependencyModifiedError if the contents of the dependency installed
+// in the mod installation directory do not match the integrity hash recorded in the lock
+// (any other error means the dependency could not be verified)
+func VerifyInstalledDependency(dependency *ResolvedVersionConstraint, modInstallationPath string) error {
+	if dependency.Integrity == "" {
+		return nil
+	}
+	actual, err := hashInstalledDependency(modInstallationPath, dependency.DependencyPath())
+	if err != nil {
+		return err
+	}
+	if actual != dependency.Integrity {
+		return &DependencyModifiedError{DependencyPath: dependency.DependencyPath()}
+	}
+	return nil
+}
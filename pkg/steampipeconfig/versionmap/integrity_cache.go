@@ -0,0 +1,146 @@
+package versionmap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// IntegrityCacheFileName is the file in the mod installation directory which caches the content hash
+// of each installed dependency, so that the dependencies do not need to be re-hashed every time the workspace is loaded
+const IntegrityCacheFileName = ".integrity_cache.json"
+
+// integrityCacheEntry is the cached content hash of a dependency directory, which is valid
+// for as long as the directory fingerprint is unchanged
+type integrityCacheEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Integrity   string `json:"integrity"`
+}
+
+var integrityCacheMut sync.Mutex
+
+// hashInstalledDependency returns the content hash of the installed dependency
+//
+// the hash is only recomputed if a file in the dependency directory has been added, removed, resized or
+// modified since the hash was cached
+func hashInstalledDependency(modInstallationPath, dependencyPath string) (string, error) {
+	dependencyDir := filepath.Join(modInstallationPath, dependencyPath)
+	fingerprint, err := fingerprintModDirectory(dependencyDir)
+	if err != nil {
+		return "", err
+	}
+
+	integrityCacheMut.Lock()
+	defer integrityCacheMut.Unlock()
+
+	cachePath := filepath.Join(modInstallationPath, IntegrityCacheFileName)
+	cache := loadIntegrityCache(cachePath)
+	if entry, ok := cache[dependencyPath]; ok && entry.Fingerprint == fingerprint {
+		return entry.Integrity, nil
+	}
+
+	integrity, err := HashModDirectory(dependencyDir)
+	if err != nil {
+		return "", err
+	}
+	cache[dependencyPath] = integrityCacheEntry{Fingerprint: fingerprint, Integrity: integrity}
+	// failing to write the cache just means the dependency will be hashed again next time
+	if err := saveIntegrityCache(cachePath, cache); err != nil {
+		log.Printf("[WARN] failed to write mod integrity cache: %s", err.Error())
+	}
+	return integrity, nil
+}
+
+// fingerprintModDirectory returns a hash of the path, size and modification time of every file
+// included in the content hash of the directory (see HashModDirectory)
+func fingerprintModDirectory(dir string) (string, error) {
+	var lines []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, fmt.Sprintf("%s %d %d\n", filepath.ToSlash(relPath), info.Size(), info.ModTime().UnixNano()))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	for _, line := range lines {
+		h.Write([]byte(line))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// rehashInstalledDependency returns the content hash of the installed dependency, ignoring any cached hash,
+// and replaces the cached hash with it
+// this is used when the dependencies are verified explicitly, so that the result never depends on the cache
+func rehashInstalledDependency(modInstallationPath, dependencyPath string) (string, error) {
+	dependencyDir := filepath.Join(modInstallationPath, dependencyPath)
+	fingerprint, err := fingerprintModDirectory(dependencyDir)
+	if err != nil {
+		return "", err
+	}
+	integrity, err := HashModDirectory(dependencyDir)
+	if err != nil {
+		return "", err
+	}
+
+	integrityCacheMut.Lock()
+	defer integrityCacheMut.Unlock()
+
+	cachePath := filepath.Join(modInstallationPath, IntegrityCacheFileName)
+	cache := loadIntegrityCache(cachePath)
+	cache[dependencyPath] = integrityCacheEntry{Fingerprint: fingerprint, Integrity: integrity}
+	if err := saveIntegrityCache(cachePath, cache); err != nil {
+		log.Printf("[WARN] failed to write mod integrity cache: %s", err.Error())
+	}
+	return integrity, nil
+}
+
+func loadIntegrityCache(path string) map[string]integrityCacheEntry {
+	cache := make(map[string]integrityCacheEntry)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		log.Printf("[WARN] ignoring invalid mod integrity cache %s: %s", path, err.Error())
+		return make(map[string]integrityCacheEntry)
+	}
+	return cache
+}
+
+func saveIntegrityCache(path string, cache map[string]integrityCacheEntry) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,173 @@
+package versionmap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHashModDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "mod.sp"), `mod "m1" {}`)
+	writeTestFile(t, filepath.Join(dir, "queries", "q1.sql"), "select 1")
+
+	original, err := HashModDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// git metadata is not included
+	writeTestFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main")
+	if hash, _ := HashModDirectory(dir); hash != original {
+		t.Errorf("expected git metadata to be excluded from the hash")
+	}
+
+	// modifying a file changes the hash
+	writeTestFile(t, filepath.Join(dir, "queries", "q1.sql"), "select 2")
+	if hash, _ := HashModDirectory(dir); hash == original {
+		t.Errorf("expected a modified file to change the hash")
+	}
+}
+
+func TestWorkspaceLockVerify(t *testing.T) {
+	modsDir := t.TempDir()
+	writeTestFile(t, filepath.Join(modsDir, "github.com/turbot/m1@v1.0.0", "mod.sp"), `mod "m1" {}`)
+	writeTestFile(t, filepath.Join(modsDir, "github.com/turbot/m2@v1.0.0", "mod.sp"), `mod "m2" {}`)
+	writeTestFile(t, filepath.Join(modsDir, "github.com/turbot/m3@v1.0.0", "mod.sp"), `mod "m3" {}`)
+
+	m1Hash, _ := HashModDirectory(filepath.Join(modsDir, "github.com/turbot/m1@v1.0.0"))
+	m2Hash, _ := HashModDirectory(filepath.Join(modsDir, "github.com/turbot/m2@v1.0.0"))
+	// m2 is modified after installation
+	writeTestFile(t, filepath.Join(modsDir, "github.com/turbot/m2@v1.0.0", "extra.sp"), `query "q" {}`)
+
+	lock := &WorkspaceLock{
+		ModInstallationPath: modsDir,
+		InstallCache:        make(DependencyVersionMap),
+		MissingVersions:     make(DependencyVersionMap),
+	}
+	version := semver.MustParse("1.0.0")
+	for name, integrity := range map[string]string{
+		"github.com/turbot/m1": m1Hash,
+		"github.com/turbot/m2": m2Hash,
+		"github.com/turbot/m3": "",
+	} {
+		dep := NewResolvedVersionConstraint(name, "", version, "*")
+		dep.Integrity = integrity
+		lock.InstallCache.AddResolvedVersion(dep, "local")
+	}
+	lock.MissingVersions.Add("github.com/turbot/m4", "", version, "*", "local")
+
+	results, err := lock.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]IntegrityStatus{
+		"github.com/turbot/m1@v1.0.0": IntegrityOk,
+		"github.com/turbot/m2@v1.0.0": IntegrityModified,
+		"github.com/turbot/m3@v1.0.0": IntegrityUnknown,
+		"github.com/turbot/m4@v1.0.0": IntegrityMissing,
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+	for _, r := range results {
+		if r.Status != expected[r.DependencyPath] {
+			t.Errorf("Test: '%s' FAILED : expected %s, got %s", r.DependencyPath, expected[r.DependencyPath], r.Status)
+		}
+	}
+
+	if lock.GetLockedIntegrity("github.com/turbot/m1", version) != m1Hash {
+		t.Errorf("expected the locked integrity of github.com/turbot/m1 to be returned")
+	}
+}
+
+func TestVerifyInstalledDependency(t *testing.T) {
+	modsDir := t.TempDir()
+	depDir := filepath.Join(modsDir, "github.com/turbot/m1@v1.0.0")
+	writeTestFile(t, filepath.Join(depDir, "mod.sp"), `mod "m1" {}`)
+	integrity, _ := HashModDirectory(depDir)
+
+	dep := NewResolvedVersionConstraint("github.com/turbot/m1", "", semver.MustParse("1.0.0"), "*")
+	dep.Integrity = integrity
+	if err := VerifyInstalledDependency(dep, modsDir); err != nil {
+		t.Fatalf("expected unmodified dependency to verify, got %s", err.Error())
+	}
+	// the hash is cached
+	if cache := loadIntegrityCache(filepath.Join(modsDir, IntegrityCacheFileName)); cache[dep.DependencyPath()].Integrity != integrity {
+		t.Errorf("expected the dependency hash to be cached")
+	}
+
+	// a modified dependency returns a DependencyModifiedError
+	writeTestFile(t, filepath.Join(depDir, "mod.sp"), `mod "m1" { title = "modified" }`)
+	var modifiedErr *DependencyModifiedError
+	if err := VerifyInstalledDependency(dep, modsDir); !errors.As(err, &modifiedErr) {
+		t.Errorf("expected a DependencyModifiedError, got %v", err)
+	}
+
+	// a dependency which cannot be read returns some other error
+	if err := os.RemoveAll(depDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyInstalledDependency(dep, modsDir); err == nil || errors.As(err, &modifiedErr) {
+		t.Errorf("expected a missing dependency to return an error other than DependencyModifiedError, got %v", err)
+	}
+}
+
+func TestWorkspaceLockVerifyRehashes(t *testing.T) {
+	modsDir := t.TempDir()
+	depDir := filepath.Join(modsDir, "github.com/turbot/m1@v1.0.0")
+	writeTestFile(t, filepath.Join(depDir, "mod.sp"), `mod "m1" {}`)
+	installed, _ := HashModDirectory(depDir)
+
+	// simulate an edit which the fingerprint cannot detect (same size and modification time)
+	// by caching the installed hash against the fingerprint of the modified directory
+	writeTestFile(t, filepath.Join(depDir, "mod.sp"), `mod "m2" {}`)
+	fingerprint, err := fingerprintModDirectory(depDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cachePath := filepath.Join(modsDir, IntegrityCacheFileName)
+	if err := saveIntegrityCache(cachePath, map[string]integrityCacheEntry{
+		"github.com/turbot/m1@v1.0.0": {Fingerprint: fingerprint, Integrity: installed},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	lock := &WorkspaceLock{
+		ModInstallationPath: modsDir,
+		InstallCache:        make(DependencyVersionMap),
+		MissingVersions:     make(DependencyVersionMap),
+	}
+	dep := NewResolvedVersionConstraint("github.com/turbot/m1", "", semver.MustParse("1.0.0"), "*")
+	dep.Integrity = installed
+	lock.InstallCache.AddResolvedVersion(dep, "local")
+
+	results, err := lock.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != IntegrityModified {
+		t.Fatalf("expected verify to re-hash the modified dependency, got %+v", results)
+	}
+	// the stale cache entry is replaced
+	if cache := loadIntegrityCache(cachePath); cache[dep.DependencyPath()].Integrity != results[0].Actual {
+		t.Errorf("expected verify to refresh the integrity cache")
+	}
+	var modifiedErr *DependencyModifiedError
+	if err := VerifyInstalledDependency(dep, modsDir); !errors.As(err, &modifiedErr) {
+		t.Errorf("expected a DependencyModifiedError after the cache was refreshed, got %v", err)
+	}
+}
@@ -1,16 +1,23 @@
 package versionmap
 
 import (
+	"strings"
+
 	"github.com/Masterminds/semver/v3"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
 )
 
 type ResolvedVersionConstraint struct {
-	Name          string          `json:"name,omitempty"`
-	Alias         string          `json:"alias,omitempty"`
-	Version       *semver.Version `json:"version,omitempty"`
-	Constraint    string          `json:"constraint,omitempty"`
-	StructVersion int             `json:"struct_version,omitempty"`
+	Name       string          `json:"name,omitempty"`
+	Alias      string          `json:"alias,omitempty"`
+	Version    *semver.Version `json:"version,omitempty"`
+	Constraint string          `json:"constraint,omitempty"`
+	// for dependencies pinned to a git branch or commit, the required branch and the resolved commit
+	Branch string `json:"branch,omitempty"`
+	Commit string `json:"commit,omitempty"`
+	// the content hash of the installed mod directory
+	Integrity     string `json:"integrity,omitempty"`
+	StructVersion int    `json:"struct_version,omitempty"`
 }
 
 func NewResolvedVersionConstraint(name, alias string, version *semver.Version, constraintString string) *ResolvedVersionConstraint {
@@ -26,7 +33,30 @@ func NewResolvedVersionConstraint(name, alias string, version *semver.Version, c
 func (c ResolvedVersionConstraint) Equals(other *ResolvedVersionConstraint) bool {
 	return c.Name == other.Name &&
 		c.Version.Equal(other.Version) &&
-		c.Constraint == other.Constraint
+		c.Constraint == other.Constraint &&
+		c.Branch == other.Branch &&
+		c.Commit == other.Commit
+}
+
+// IsGitRef returns whether the dependency is pinned to a git branch or commit
+func (c ResolvedVersionConstraint) IsGitRef() bool {
+	return c.Branch != "" || c.Commit != ""
+}
+
+// Satisfies returns whether this resolved version meets the given requirement
+func (c ResolvedVersionConstraint) Satisfies(requiredModVersion *modconfig.ModVersionConstraint) bool {
+	if requiredModVersion.HasGitRef() {
+		if requiredModVersion.Branch != "" {
+			return c.Branch == requiredModVersion.Branch
+		}
+		// the required commit may be abbreviated - the resolved commit is always the full sha
+		return c.Branch == "" && c.Commit != "" && strings.HasPrefix(c.Commit, strings.ToLower(requiredModVersion.Commit))
+	}
+	// a git ref does not satisfy a version constraint
+	if c.IsGitRef() {
+		return false
+	}
+	return requiredModVersion.Constraint.Check(c.Version)
 }
 
 func (c ResolvedVersionConstraint) IsPrerelease() bool {
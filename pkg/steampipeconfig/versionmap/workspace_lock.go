@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
@@ -160,7 +161,7 @@ func (l *WorkspaceLock) setMissing() {
 				// get the mod name from the constraint (fullName includes the version)
 				name := resolvedConstraint.Name
 				// remove this item from the install cache and add into missing
-				l.MissingVersions.Add(name, resolvedConstraint.Alias, resolvedConstraint.Version, resolvedConstraint.Constraint, parent)
+				l.MissingVersions.AddResolvedVersion(resolvedConstraint, parent)
 				l.InstallCache[parent].Remove(name)
 			}
 		}
@@ -241,8 +242,8 @@ func (l *WorkspaceLock) GetLockedModVersion(requiredModVersion *modconfig.ModVer
 		return nil, nil
 	}
 
-	// verify the locked version satisfies the version constraint
-	if !requiredModVersion.Constraint.Check(lockedVersion.Version) {
+	// verify the locked version satisfies the version constraint (or git ref)
+	if !lockedVersion.Satisfies(requiredModVersion) {
 		return nil, nil
 	}
 
@@ -256,9 +257,9 @@ func (l *WorkspaceLock) EnsureLockedModVersion(requiredModVersion *modconfig.Mod
 		return nil, nil
 	}
 
-	// verify the locked version satisfies the version constraint
-	if !requiredModVersion.Constraint.Check(lockedVersion.Version) {
-		return nil, fmt.Errorf("failed to resolve dependencies for %s - locked version %s does not meet the constraint %s", parent.GetInstallCacheKey(), modconfig.BuildModDependencyPath(requiredModVersion.Name, lockedVersion.Version), requiredModVersion.Constraint.Original)
+	// verify the locked version satisfies the version constraint (or git ref)
+	if !lockedVersion.Satisfies(requiredModVersion) {
+		return nil, fmt.Errorf("failed to resolve dependencies for %s - locked version %s does not meet the %s", parent.GetInstallCacheKey(), modconfig.BuildModDependencyPath(requiredModVersion.Name, lockedVersion.Version), requiredModVersion.RequirementString())
 	}
 
 	return lockedVersion, nil
@@ -281,6 +282,53 @@ func (l *WorkspaceLock) GetLockedModVersionConstraint(requiredModVersion *modcon
 	return modconfig.NewModVersionConstraint(lockedVersionFullName)
 }
 
+// GetLockedIntegrity returns the integrity hash recorded in the lock for the given mod version, if any
+// (this includes versions which are locked but not currently installed)
+func (l *WorkspaceLock) GetLockedIntegrity(modName string, modVersion *semver.Version) string {
+	for _, versionMap := range []DependencyVersionMap{l.InstallCache, l.MissingVersions} {
+		for _, dep := range versionMap.FlatMap() {
+			if dep.Name == modName && dep.Version.Equal(modVersion) && dep.Integrity != "" {
+				return dep.Integrity
+			}
+		}
+	}
+	return ""
+}
+
+// Verify compares the contents of each installed dependency with the integrity hash recorded in the lock
+// every dependency is re-hashed (the integrity cache is refreshed rather than trusted)
+// results are sorted by dependency path
+func (l *WorkspaceLock) Verify() ([]*IntegrityResult, error) {
+	var res []*IntegrityResult
+	for dependencyPath, dep := range l.InstallCache.FlatMap() {
+		res = append(res, &IntegrityResult{DependencyPath: dependencyPath, Expected: dep.Integrity})
+	}
+	for dependencyPath, dep := range l.MissingVersions.FlatMap() {
+		res = append(res, &IntegrityResult{DependencyPath: dependencyPath, Expected: dep.Integrity, Status: IntegrityMissing})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].DependencyPath < res[j].DependencyPath })
+
+	for _, r := range res {
+		if r.Status == IntegrityMissing {
+			continue
+		}
+		actual, err := rehashInstalledDependency(l.ModInstallationPath, r.DependencyPath)
+		if err != nil {
+			return nil, err
+		}
+		r.Actual = actual
+		switch {
+		case r.Expected == "":
+			r.Status = IntegrityUnknown
+		case r.Expected == actual:
+			r.Status = IntegrityOk
+		default:
+			r.Status = IntegrityModified
+		}
+	}
+	return res, nil
+}
+
 // ContainsModVersion returns whether the lockfile contains the given mod version
 func (l *WorkspaceLock) ContainsModVersion(modName string, modVersion *semver.Version) bool {
 	for _, modVersionMap := range l.InstallCache {
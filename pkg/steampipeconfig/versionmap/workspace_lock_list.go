@@ -5,3 +5,8 @@ func (l *WorkspaceLock) GetModList(rootName string) string {
 	tree := l.InstallCache.GetDependencyTree(rootName)
 	return tree.String()
 }
+
+// GetModTree returns the installed dependency tree as a DependencyTreeNode, suitable for serialising
+func (l *WorkspaceLock) GetModTree(rootName string) *DependencyTreeNode {
+	return l.InstallCache.GetDependencyTreeNode(rootName)
+}
@@ -509,6 +509,65 @@ load "$LIB_BATS_SUPPORT/load.bash"
   assert_not_equal "$unique1" "$unique3"
 }
 
+# This test checks that clearing the cache for a connection only clears the cached results of that connection.
+# To test this behaviour:
+#   1. Start the service and query the unique value(unique_col) of the chaos_cache_check table of two connections.
+#   2. Clear the cache of the first connection and run both queries again.
+#   3. The first connection should return a new value, the second connection should still return the cached value.
+@test "verify connection cache clear only clears the cache for that connection" {
+  cp $SRC_DATA_DIR/chaos_cache_clear.spc $STEAMPIPE_INSTALL_DIR/config/chaos_cache_clear.spc
+
+  # start the service
+  steampipe service start
+
+  steampipe query "select unique_col from chaos_cache_clear_1.chaos_cache_check where id=2" --output json > out1.json
+  steampipe query "select unique_col from chaos_cache_clear_2.chaos_cache_check where id=2" --output json > out2.json
+
+  # clear the cache for the first connection
+  # (metaqueries are only run by the interactive prompt, so drive it through a pseudo terminal)
+  (sleep 5; printf '.cache clear chaos_cache_clear_1\r'; sleep 2; printf '.exit\r') | script -qec "steampipe query" /dev/null
+
+  # run the queries again
+  steampipe query "select unique_col from chaos_cache_clear_1.chaos_cache_check where id=2" --output json > out3.json
+  steampipe query "select unique_col from chaos_cache_clear_2.chaos_cache_check where id=2" --output json > out4.json
+
+  # stop the service
+  steampipe service stop
+
+  unique1=$(cat out1.json | jq '.[].unique_col')
+  unique2=$(cat out2.json | jq '.[].unique_col')
+  unique3=$(cat out3.json | jq '.[].unique_col')
+  unique4=$(cat out4.json | jq '.[].unique_col')
+
+  # remove the output and the config files
+  rm -f out*.json
+  rm -f $STEAMPIPE_INSTALL_DIR/config/chaos_cache_clear.spc
+
+  # the cache of the first connection was cleared, so the query should have a different value
+  assert_not_equal "$unique1" "$unique3"
+  # the cache of the second connection was not cleared, so the query should have the same value
+  assert_equal "$unique2" "$unique4"
+}
+
+@test "verify cache stats shows the scans of the connections queried in the session" {
+  cp $SRC_DATA_DIR/chaos_cache_clear.spc $STEAMPIPE_INSTALL_DIR/config/chaos_cache_clear.spc
+
+  # start the service
+  steampipe service start
+
+  # run a query and show the cache stats in the same interactive session
+  # (metaqueries are only run by the interactive prompt, so drive it through a pseudo terminal)
+  run bash -c "(sleep 5; printf 'select unique_col from chaos_cache_clear_1.chaos_cache_check where id=2;\r'; sleep 2; printf '.cache stats\r'; sleep 2; printf '.exit\r') | script -qec 'steampipe query' /dev/null"
+
+  # stop the service
+  steampipe service stop
+
+  rm -f $STEAMPIPE_INSTALL_DIR/config/chaos_cache_clear.spc
+
+  # the stats table is only shown when connections have been scanned
+  assert_output --partial "Cache hits"
+}
+
 function teardown_file() {
   # list running processes
   ps -ef | grep steampipe